@@ -83,4 +83,53 @@ false
   {{- else -}}
     false
   {{- end -}}
-{{- end }}
\ No newline at end of file
+{{- end }}
+
+{{- define "hazelcastNetworkPolicy" }}
+# NetworkPolicy for {{ .name }}: admits the Pega tiers and the other Hazelcast members to the member port and
+# Prometheus to the metrics port
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: {{ .name }}
+  namespace: {{ .root.Release.Namespace }}
+  labels:
+    app: {{ .name }}
+    component: Pega
+spec:
+  podSelector:
+    matchLabels:
+      app: {{ .name }}
+      component: Hazelcast
+  policyTypes:
+  - Ingress
+  - Egress
+  ingress:
+  - from:
+{{ include "pegaNetworkPolicyTierPods" .root | trim | indent 4 }}
+    - podSelector:
+        matchLabels:
+          app: {{ .name }}
+          component: Hazelcast
+    ports:
+    - protocol: TCP
+      port: 5701
+  - from:
+{{ include "pegaNetworkPolicyMonitoringPeers" .root | trim | indent 4 }}
+    ports:
+    - protocol: TCP
+      port: 8089
+  egress:
+{{ include "pegaNetworkPolicyDNS" .root | trim | indent 2 }}
+  - to:
+    - podSelector:
+        matchLabels:
+          app: {{ .name }}
+          component: Hazelcast
+    ports:
+    - protocol: TCP
+      port: 5701
+{{- with include "pegaNetworkPolicyExtraEgress" .root }}
+{{ . | trim | indent 2 }}
+{{- end }}
+{{- end }}
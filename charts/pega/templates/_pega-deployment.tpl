@@ -111,6 +111,9 @@ spec:
 {{- if .node.nodeSelector }}
       nodeSelector:
 {{ toYaml .node.nodeSelector | indent 8 }}
+{{- end }}
+{{- with include "pegaAffinity" (dict "affinity" .node.affinity "preset" .node.podAntiAffinityPreset "labels" (dict "app" .name) "name" .name) }}
+{{ . | trim | indent 6 }}
 {{- end }}
       securityContext:
 {{- if (ne .root.Values.global.provider "openshift") }}
@@ -143,6 +146,10 @@ spec:
           name: pega-web-port
         - containerPort: 8443
           name: pega-tls-port
+{{- if (.node.monitoring).enabled }}
+        - containerPort: {{ include "pega.metricsPort" .node }}
+          name: pega-metrics
+{{- end }}
 {{- if .custom }}
 {{- if .custom.ports }}
         # Additional custom ports
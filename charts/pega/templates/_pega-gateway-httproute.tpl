@@ -0,0 +1,117 @@
+{{- define "pega.gateway.httpRoute" -}}
+{{- $ingress := .node.ingress }}
+{{- $gateway := $ingress.gateway | default dict }}
+{{- if not $gateway.name }}
+{{- fail (printf "tier[%s] ingress.type gateway requires ingress.gateway.name, the name of the parent Gateway" .name) }}
+{{- end }}
+{{- if eq (toString (.node.service).httpEnabled) "false" }}
+{{- fail (printf "tier[%s] ingress.type gateway routes to the http port of the service and may not set service.httpEnabled to false" .name) }}
+{{- end }}
+{{- $domain := include "domainName" (dict "node" .node) }}
+{{- $contextPath := printf "/%s" (include "pega.applicationContextPath" .) }}
+{{- $sessionPersistence := $gateway.sessionPersistence | default dict }}
+# HTTPRoute to be used for {{ .name }}, requires the Gateway API custom resource definitions
+apiVersion: gateway.networking.k8s.io/v1
+kind: HTTPRoute
+metadata:
+  name: {{ .name }}
+  namespace: {{ .root.Release.Namespace }}
+{{- if $ingress.annotations }}
+  annotations:
+    # Custom annotations
+{{ toYaml $ingress.annotations | indent 4 }}
+{{- end }}
+spec:
+  parentRefs:
+{{ include "pega.gateway.parentRef" (dict "gateway" $gateway "sectionName" $gateway.sectionName) | indent 2 }}
+{{- if $domain }}
+  hostnames:
+  - {{ $domain | quote }}
+{{- end }}
+  rules:
+{{- if and .root.Values.constellation (eq .root.Values.constellation.enabled true) }}
+  - matches:
+    - path:
+        type: PathPrefix
+        value: /c11n
+    backendRefs:
+    - name: constellation
+      port: 3000
+{{- end }}
+{{- if not $ingress.path }}
+  # Requests to the root of the domain are sent to the application context path
+  - matches:
+    - path:
+        type: Exact
+        value: /
+    filters:
+    - type: RequestRedirect
+      requestRedirect:
+        path:
+          type: ReplaceFullPath
+          replaceFullPath: {{ printf "%s/" $contextPath | quote }}
+        statusCode: 302
+{{- end }}
+  - matches:
+    - path:
+        type: PathPrefix
+        value: {{ $ingress.path | default $contextPath | quote }}
+    # The Gateway terminates TLS and connects to the plain HTTP port of the service, as the route sets no BackendTLSPolicy
+    backendRefs:
+    - name: {{ .name }}
+      port: {{ .node.service.port }}
+{{- if $gateway.timeouts }}
+    timeouts:
+{{ toYaml $gateway.timeouts | indent 6 }}
+{{- end }}
+{{- if $sessionPersistence.enabled }}
+    # Keeps a user session on the pod that holds its requestor, for the passivation time of the requestor. Part of the
+    # experimental channel of the Gateway API.
+    sessionPersistence:
+      type: Cookie
+{{- if $sessionPersistence.sessionName }}
+      sessionName: {{ $sessionPersistence.sessionName | quote }}
+{{- end }}
+      absoluteTimeout: {{ printf "%ss" (include "lbSessionCookieStickiness" .) | quote }}
+{{- if $sessionPersistence.idleTimeout }}
+      idleTimeout: {{ $sessionPersistence.idleTimeout | quote }}
+{{- end }}
+      cookieConfig:
+        lifetimeType: Permanent
+{{- end }}
+---
+{{- if and (include "ingressTlsEnabled" .) $gateway.httpSectionName }}
+# HTTPRoute redirecting the plain HTTP requests for {{ .name }} to HTTPS
+apiVersion: gateway.networking.k8s.io/v1
+kind: HTTPRoute
+metadata:
+  name: {{ .name }}-http-redirect
+  namespace: {{ .root.Release.Namespace }}
+spec:
+  parentRefs:
+{{ include "pega.gateway.parentRef" (dict "gateway" $gateway "sectionName" $gateway.httpSectionName) | indent 2 }}
+{{- if $domain }}
+  hostnames:
+  - {{ $domain | quote }}
+{{- end }}
+  rules:
+  - filters:
+    - type: RequestRedirect
+      requestRedirect:
+        scheme: https
+        statusCode: 301
+---
+{{- end }}
+{{- end }}
+
+{{- define "pega.gateway.parentRef" -}}
+- group: gateway.networking.k8s.io
+  kind: Gateway
+  name: {{ .gateway.name | quote }}
+{{- if .gateway.namespace }}
+  namespace: {{ .gateway.namespace | quote }}
+{{- end }}
+{{- if .sectionName }}
+  sectionName: {{ .sectionName | quote }}
+{{- end }}
+{{- end }}
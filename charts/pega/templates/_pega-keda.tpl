@@ -0,0 +1,41 @@
+{{- define "pega.scaledObject" -}}
+{{- if not .autoscaling.triggers }}
+{{- fail (printf "tier[%s] autoscaling.mode keda requires at least one entry in autoscaling.triggers" .deploymentName) }}
+{{- end }}
+# The KEDA ScaledObject for {{ .deploymentName }}, requires the KEDA custom resource definitions
+apiVersion: keda.sh/v1alpha1
+kind: ScaledObject
+metadata:
+  name: {{ .name | quote }}
+  namespace: {{ .root.Release.Namespace }}
+{{- if .autoscaling.labels }}
+  labels:
+{{ toYaml .autoscaling.labels | indent 4 }}
+{{- end }}
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: {{ .kind }}
+    name: {{ .deploymentName | quote }}
+  minReplicaCount: {{ hasKey .autoscaling "minReplicas" | ternary .autoscaling.minReplicas 1 }}
+  maxReplicaCount: {{ .autoscaling.maxReplicas | default 5 }}
+{{- if .autoscaling.pollingInterval }}
+  pollingInterval: {{ .autoscaling.pollingInterval }}
+{{- end }}
+{{- if .autoscaling.cooldownPeriod }}
+  cooldownPeriod: {{ .autoscaling.cooldownPeriod }}
+{{- end }}
+{{- if .autoscaling.fallback }}
+  fallback:
+{{ toYaml .autoscaling.fallback | indent 4 }}
+{{- end }}
+{{- if .autoscaling.behavior }}
+  advanced:
+    horizontalPodAutoscalerConfig:
+      behavior:
+{{ toYaml .autoscaling.behavior | indent 8 }}
+{{- end }}
+  triggers:
+{{ toYaml .autoscaling.triggers | indent 2 }}
+---
+{{- end -}}
@@ -0,0 +1,66 @@
+{{- define "pega.metricsPort" -}}
+{{ (.monitoring).port | default 9404 }}
+{{- end -}}
+
+{{- define "pega.metricsService" -}}
+# Metrics Service for {{ .name }}, scraped by Prometheus through the ServiceMonitor of the tier
+kind: Service
+apiVersion: v1
+metadata:
+  name: {{ .name }}-metrics
+  namespace: {{ .root.Release.Namespace }}
+  labels:
+    app: {{ .name }}
+    component: Pega
+{{- if .monitoring.annotations }}
+  annotations:
+{{ toYaml .monitoring.annotations | indent 4 }}
+{{- end }}
+spec:
+  type: ClusterIP
+  ports:
+  - name: metrics
+    port: {{ include "pega.metricsPort" . }}
+    targetPort: pega-metrics
+  selector:
+    app: {{ .name }}
+---
+{{- end -}}
+
+{{- define "pega.serviceMonitor" -}}
+{{- $serviceMonitor := .monitoring.serviceMonitor | default dict -}}
+{{- if or (not (hasKey $serviceMonitor "enabled")) $serviceMonitor.enabled }}
+# ServiceMonitor for {{ .name }}, requires the Prometheus Operator custom resource definitions
+apiVersion: monitoring.coreos.com/v1
+kind: ServiceMonitor
+metadata:
+  name: {{ .name }}
+  namespace: {{ .root.Release.Namespace }}
+  labels:
+{{ toYaml (merge (dict "app" .name "component" "Pega") ($serviceMonitor.labels | default dict)) | indent 4 }}
+spec:
+  selector:
+    matchLabels:
+      app: {{ .name }}
+      component: Pega
+  namespaceSelector:
+    matchNames:
+    - {{ .root.Release.Namespace }}
+  endpoints:
+  - port: metrics
+    path: {{ .monitoring.path | default "/metrics" }}
+    interval: {{ $serviceMonitor.interval | default "30s" }}
+{{- if $serviceMonitor.scrapeTimeout }}
+    scrapeTimeout: {{ $serviceMonitor.scrapeTimeout }}
+{{- end }}
+{{- if $serviceMonitor.relabelings }}
+    relabelings:
+{{ toYaml $serviceMonitor.relabelings | indent 4 }}
+{{- end }}
+{{- if $serviceMonitor.metricRelabelings }}
+    metricRelabelings:
+{{ toYaml $serviceMonitor.metricRelabelings | indent 4 }}
+{{- end }}
+---
+{{- end }}
+{{- end -}}
@@ -0,0 +1,203 @@
+{{- /*
+networkPolicyURLPorts renders the TCP ports of a comma separated list of URLs or host:port pairs as NetworkPolicy
+ports, falling back to the scheme default and then to .defaultPort when a URL does not name a port.
+*/}}
+{{- define "networkPolicyURLPorts" -}}
+{{- $ports := list -}}
+{{- range $url := splitList "," .urls -}}
+{{- $url = trim $url -}}
+{{- if $url -}}
+{{- $authority := regexReplaceAll "[/?#;].*$" (regexReplaceAll "^[a-zA-Z][-+.a-zA-Z0-9]*://" $url "") "" -}}
+{{- $port := "" -}}
+{{- if regexMatch ":[0-9]+$" $authority -}}
+{{- $port = regexReplaceAll "^.*:" $authority "" -}}
+{{- else if hasPrefix "https://" (lower $url) -}}
+{{- $port = "443" -}}
+{{- else if hasPrefix "http://" (lower $url) -}}
+{{- $port = "80" -}}
+{{- else if $.defaultPort -}}
+{{- $port = toString $.defaultPort -}}
+{{- end -}}
+{{- if and $port (not (has $port $ports)) -}}
+{{- $ports = append $ports $port -}}
+{{- end -}}
+{{- end -}}
+{{- end -}}
+{{- range $ports }}
+- protocol: TCP
+  port: {{ . }}
+{{- end -}}
+{{- end -}}
+
+{{- /*
+networkPolicyJDBCEgress renders an egress rule per JDBC URL. The port comes from the URL or the default port of
+global.jdbc.dbType; the destination is global.networkPolicy.databaseCIDRs, the host when the URL names an IPv4
+address, and any address otherwise.
+*/}}
+{{- define "networkPolicyJDBCEgress" -}}
+{{- $defaultPorts := dict "postgres" "5432" "oracledate" "1521" "mssql" "1433" "udb" "50000" -}}
+{{- $jdbc := .Values.global.jdbc -}}
+{{- range $url := compact (list $jdbc.url $jdbc.readerurl) }}
+{{- $host := "" -}}
+{{- $port := "" -}}
+{{- if regexMatch "(?i)\\(\\s*host\\s*=" $url -}}
+{{- $host = regexReplaceAll "(?is)^.*?\\(\\s*host\\s*=\\s*([^)\\s]+)\\s*\\).*$" $url "${1}" -}}
+{{- if regexMatch "(?i)\\(\\s*port\\s*=" $url -}}
+{{- $port = regexReplaceAll "(?is)^.*?\\(\\s*port\\s*=\\s*([0-9]+)\\s*\\).*$" $url "${1}" -}}
+{{- end -}}
+{{- else -}}
+{{- $authority := regexReplaceAll "^(//|@)" (regexFind "(//|@)[^/:;?@]+(:[0-9]+)?" $url) "" -}}
+{{- $host = regexReplaceAll ":[0-9]+$" $authority "" -}}
+{{- if regexMatch ":[0-9]+$" $authority -}}
+{{- $port = regexReplaceAll "^.*:" $authority "" -}}
+{{- end -}}
+{{- end -}}
+{{- $port = $port | default (get $defaultPorts (toString $jdbc.dbType)) -}}
+{{- if not $port -}}
+{{- fail (printf "The NetworkPolicy needs the database port: add it to the JDBC URL %s" $url) -}}
+{{- end }}
+# Database at {{ $host | default "the JDBC URL" }}
+- ports:
+  - protocol: TCP
+    port: {{ $port }}
+{{- if ($.Values.global.networkPolicy).databaseCIDRs }}
+  to:
+{{- range $.Values.global.networkPolicy.databaseCIDRs }}
+  - ipBlock:
+      cidr: {{ . }}
+{{- end }}
+{{- else if regexMatch "^[0-9]{1,3}(\\.[0-9]{1,3}){3}$" $host }}
+  to:
+  - ipBlock:
+      cidr: {{ $host }}/32
+{{- end }}
+{{- end }}
+{{- end -}}
+
+{{- define "pega.networkPolicy" -}}
+{{- $root := .root }}
+{{- $servicePorts := list }}
+{{- if .node.service }}
+{{- if or (not (hasKey .node.service "httpEnabled")) (.node.service.httpEnabled) }}
+{{- $servicePorts = append $servicePorts .node.service.targetPort }}
+{{- end }}
+{{- if (.node.service.tls).enabled }}
+{{- $servicePorts = append $servicePorts .node.service.tls.targetPort }}
+{{- end }}
+{{- end }}
+{{- $internalSearch := and (not $root.Values.pegasearch.externalSearchService) (eq (include "pegaSearchURL" $root) (include "defaultSearchURL" $root)) }}
+# NetworkPolicy for {{ .name }}: admits the other Pega tiers and the load balancer, and lets the pods reach the services
+# the chart configures them to use
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: {{ .name }}
+  namespace: {{ $root.Release.Namespace }}
+  labels:
+    app: {{ .name }}
+    component: Pega
+spec:
+  podSelector:
+    matchLabels:
+      app: {{ .name }}
+  policyTypes:
+  - Ingress
+  - Egress
+  ingress:
+  # The nodes of all tiers form a single Pega cluster
+  - from:
+{{ include "pegaNetworkPolicyTierPods" $root | trim | indent 4 }}
+{{- if $servicePorts }}
+  # Load balancer and ingress controller traffic to the service target ports
+  - ports:
+{{- range $servicePorts }}
+    - protocol: TCP
+      port: {{ . }}
+{{- end }}
+{{- if ($root.Values.global.networkPolicy).ingressControllerPeers }}
+    from:
+{{ toYaml $root.Values.global.networkPolicy.ingressControllerPeers | indent 4 }}
+{{- end }}
+{{- end }}
+{{- if (.node.monitoring).enabled }}
+  # Prometheus scrapes
+  - ports:
+    - protocol: TCP
+      port: {{ include "pega.metricsPort" .node }}
+    from:
+{{ include "pegaNetworkPolicyMonitoringPeers" $root | trim | indent 4 }}
+{{- end }}
+  egress:
+{{ include "pegaNetworkPolicyDNS" $root | trim | indent 2 }}
+  # The nodes of all tiers form a single Pega cluster
+  - to:
+{{ include "pegaNetworkPolicyTierPods" $root | trim | indent 4 }}
+{{- if $internalSearch }}
+  # Search nodes deployed by the chart
+  - to:
+    - podSelector:
+        matchLabels:
+          app: {{ include "searchName" $root }}
+          component: Search
+    ports:
+    - protocol: TCP
+      port: 9200
+{{- else }}
+{{- with include "networkPolicyURLPorts" (dict "urls" (include "pegaSearchURL" $root)) }}
+  # External search service at {{ include "pegaSearchURL" $root }}
+  - ports:
+{{ . | trim | indent 4 }}
+{{- end }}
+{{- end }}
+{{- if and $root.Values.pegasearch.externalSearchService ($root.Values.pegasearch.srsAuth).enabled }}
+{{- with include "networkPolicyURLPorts" (dict "urls" ($root.Values.pegasearch.srsAuth.url | default "")) }}
+  # OAuth token endpoint of the search and reporting service
+  - ports:
+{{ . | trim | indent 4 }}
+{{- end }}
+{{- end }}
+{{- range $name, $enabled := dict (include "hazelcastName" $root) $root.Values.hazelcast.enabled (include "clusteringServiceName" $root) $root.Values.hazelcast.clusteringServiceEnabled }}
+{{- if $enabled }}
+  # Hazelcast servers
+  - to:
+    - podSelector:
+        matchLabels:
+          app: {{ $name }}
+          component: Hazelcast
+    ports:
+    - protocol: TCP
+      port: 5701
+{{- end }}
+{{- end }}
+{{- if (eq (include "cassandraEnabled" $root) "true") }}
+  # Cassandra nodes for decisioning data
+  - ports:
+    - protocol: TCP
+      port: {{ $root.Values.dds.port | default 9042 }}
+{{- end }}
+{{- if $root.Values.stream.enabled }}
+{{- with include "networkPolicyURLPorts" (dict "urls" ($root.Values.stream.bootstrapServer | default "") "defaultPort" 9092) }}
+  # Kafka brokers of the external stream service
+  - ports:
+{{ . | trim | indent 4 }}
+{{- end }}
+{{- end }}
+{{- with include "networkPolicyURLPorts" (dict "urls" ($root.Values.global.jdbc.driverUri | default "")) }}
+  # Download of the JDBC driver
+  - ports:
+{{ . | trim | indent 4 }}
+{{- end }}
+{{ include "networkPolicyJDBCEgress" $root | trim | indent 2 }}
+{{- if or (eq (include "performInstallAndDeployment" $root) "true") (and (eq (include "performUpgradeAndDeployment" $root) "true") (eq $root.Values.installer.upgrade.upgradeType "zero-downtime")) }}
+  # Kubernetes API server, polled by the init containers waiting for the installer and upgrade jobs
+  - ports:
+    - protocol: TCP
+      port: 443
+    - protocol: TCP
+      port: 6443
+{{- end }}
+{{- with include "pegaNetworkPolicyExtraEgress" $root }}
+{{ . | trim | indent 2 }}
+{{- end }}
+---
+{{- end -}}
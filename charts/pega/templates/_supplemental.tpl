@@ -115,4 +115,131 @@ false
     name: {{ .extSecretName }}
 {{- end -}}
 {{- end -}}
-{{- end  -}}
\ No newline at end of file
+{{- end  -}}
+
+{{- /* NetworkPolicy rules shared by the policies of the tiers, search and Hazelcast */}}
+
+{{- define "pegaNetworkPolicyDNS" }}
+# Name lookups through the cluster DNS
+{{- if ((.Values.global).networkPolicy).dnsPeers }}
+- to:
+{{ toYaml .Values.global.networkPolicy.dnsPeers | indent 2 }}
+  ports:
+  - protocol: UDP
+    port: 53
+  - protocol: TCP
+    port: 53
+{{- else if (eq .Values.global.provider "openshift") }}
+- to:
+  - namespaceSelector:
+      matchLabels:
+        kubernetes.io/metadata.name: openshift-dns
+  ports:
+  - protocol: UDP
+    port: 5353
+  - protocol: TCP
+    port: 5353
+{{- else }}
+- to:
+  - namespaceSelector:
+      matchLabels:
+        kubernetes.io/metadata.name: kube-system
+    podSelector:
+      matchExpressions:
+      - key: k8s-app
+        operator: In
+        values: ["kube-dns", "coredns"]
+  ports:
+  - protocol: UDP
+    port: 53
+  - protocol: TCP
+    port: 53
+{{- end }}
+{{- end }}
+
+{{- define "pegaNetworkPolicyTierPods" }}
+{{- $depName := printf "%s" (include "deploymentName" $) }}
+- podSelector:
+    matchExpressions:
+    - key: app
+      operator: In
+      values:
+{{- range $tier := .Values.global.tier }}
+      - {{ printf "%s-%s" $depName $tier.name }}
+{{- end }}
+{{- end }}
+
+{{- define "pegaNetworkPolicyMonitoringPeers" }}
+{{- if ((.Values.global).networkPolicy).monitoringPeers }}
+{{ toYaml .Values.global.networkPolicy.monitoringPeers }}
+{{- else }}
+- namespaceSelector: {}
+{{- end }}
+{{- end }}
+
+{{- define "pegaNetworkPolicyExtraEgress" }}
+{{- if ((.Values.global).networkPolicy).egress }}
+# Additional egress rules from global.networkPolicy.egress
+{{ toYaml .Values.global.networkPolicy.egress }}
+{{- end }}
+{{- end }}
+
+{{- /*
+pegaAffinity renders the affinity block of a pod spec from .affinity, passed through verbatim, and .preset, which adds
+a "soft" (preferred) or "hard" (required) pod anti-affinity keeping the pods matching .labels on separate nodes.
+*/}}
+{{- define "pegaAffinity" }}
+{{- $affinity := deepCopy (.affinity | default dict) }}
+{{- if .preset }}
+{{- if hasKey $affinity "podAntiAffinity" }}
+{{- fail (printf "Set either podAntiAffinityPreset or affinity.podAntiAffinity for %s, not both" .name) }}
+{{- end }}
+{{- $term := dict "labelSelector" (dict "matchLabels" .labels) "topologyKey" "kubernetes.io/hostname" }}
+{{- if eq .preset "soft" }}
+{{- $_ := set $affinity "podAntiAffinity" (dict "preferredDuringSchedulingIgnoredDuringExecution" (list (dict "weight" 100 "podAffinityTerm" $term))) }}
+{{- else if eq .preset "hard" }}
+{{- $_ := set $affinity "podAntiAffinity" (dict "requiredDuringSchedulingIgnoredDuringExecution" (list $term)) }}
+{{- else }}
+{{- fail (printf "podAntiAffinityPreset of %s must be soft or hard, not %s" .name .preset) }}
+{{- end }}
+{{- end }}
+{{- if $affinity }}
+affinity:
+{{ toYaml $affinity | indent 2 }}
+{{- end }}
+{{- end }}
+
+{{- /*
+pegaVerticalPodAutoscaler renders a VerticalPodAutoscaler named .name for the .kind workload .targetName. The .vpa
+settings apply to the .containerName container; VPA leaves the other containers of the pods, such as sidecars, alone.
+*/}}
+{{- define "pegaVerticalPodAutoscaler" }}
+# VerticalPodAutoscaler for {{ .targetName }}, requires the Vertical Pod Autoscaler custom resource definitions
+apiVersion: autoscaling.k8s.io/v1
+kind: VerticalPodAutoscaler
+metadata:
+  name: {{ .name }}
+  namespace: {{ .root.Release.Namespace }}
+spec:
+  targetRef:
+    apiVersion: apps/v1
+    kind: {{ .kind }}
+    name: {{ .targetName }}
+  updatePolicy:
+    updateMode: {{ .vpa.updateMode | default "Off" | quote }}
+  resourcePolicy:
+    containerPolicies:
+    - containerName: {{ .containerName }}
+      controlledResources: {{ .vpa.controlledResources | default (list "cpu" "memory") | toJson }}
+{{- if .vpa.minAllowed }}
+      minAllowed:
+{{ toYaml .vpa.minAllowed | indent 8 }}
+{{- end }}
+{{- if .vpa.maxAllowed }}
+      maxAllowed:
+{{ toYaml .vpa.maxAllowed | indent 8 }}
+{{- end }}
+    - containerName: "*"
+      mode: "Off"
+---
+{{- end }}
@@ -1,6 +0,0 @@
-package addons
-
-type SearchResourceOption struct {
-	Name string
-	Kind string
-}
@@ -0,0 +1,56 @@
+package addons
+
+import (
+	"flag"
+	"path/filepath"
+	"testing"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "regenerate golden manifests instead of comparing against them")
+
+// goldenScenarios render the chart with its default values, which deploy the EFK stack, and with the ingress
+// controller of EKS. Every addon comes from a remote chart, so the golden manifests must be regenerated with
+// make dependencies when a dependency version changes.
+func goldenScenarios() []helmtest.Scenario {
+	scenarios := []helmtest.Scenario{
+		{
+			Name: "default",
+		},
+		{
+			Name: "eks-aws-load-balancer-controller",
+			SetValues: map[string]string{
+				"aws-load-balancer-controller.enabled":     "true",
+				"aws-load-balancer-controller.clusterName": "pega-eks",
+				"aws-load-balancer-controller.region":      "us-east-1",
+				"aws-load-balancer-controller.vpcId":       "vpc-0123456789abcdef0",
+				"elasticsearch.enabled":                    "false",
+				"fluentd-elasticsearch.enabled":            "false",
+				"kibana.enabled":                           "false",
+			},
+		},
+	}
+	for i := range scenarios {
+		scenarios[i].Chart = helmtest.AddonsChart
+		scenarios[i].ReleaseName = addonsHelmRelease
+		scenarios[i].KubeVersion = "1.27.0"
+	}
+	return scenarios
+}
+
+// TestAddonsGoldenManifests - compares the complete rendered chart with addons/data/golden; run with -update to
+// regenerate
+func TestAddonsGoldenManifests(t *testing.T) {
+	goldenPath, err := filepath.Abs("data/golden")
+	require.NoError(t, err)
+
+	for _, scenario := range goldenScenarios() {
+		scenario := scenario
+		t.Run(scenario.ScenarioName(), func(t *testing.T) {
+			t.Parallel()
+			helmtest.AssertGoldenManifests(t, goldenPath, scenario, *updateGolden)
+		})
+	}
+}
@@ -1,21 +1,23 @@
 package addons
 
 import (
+	"testing"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
-	"testing"
 )
 
 func TestShouldNotContainAlbIngressIfDisabled(t *testing.T) {
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTest(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTest(t, helmChartRelativePath, addonsHelmRelease, map[string]string{
 			"aws-load-balancer-controller.enabled": "false",
 		}),
 	)
 
 	for _, i := range albIngressResources {
-		require.False(t, helmChartParser.Contains(SearchResourceOption{
+		require.False(t, helmChartParser.Contains(helmtest.SearchResourceOption{
 			Name: i.Name,
 			Kind: i.Kind,
 		}))
@@ -23,14 +25,14 @@ func TestShouldNotContainAlbIngressIfDisabled(t *testing.T) {
 }
 
 func TestAlbIngressShouldContainAllResources(t *testing.T) {
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTest(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTest(t, helmChartRelativePath, addonsHelmRelease, map[string]string{
 			"aws-load-balancer-controller.enabled": "true",
 		}),
 	)
 
 	for _, i := range albIngressResources {
-		require.True(t, helmChartParser.Contains(SearchResourceOption{
+		require.True(t, helmChartParser.Contains(helmtest.SearchResourceOption{
 			Name: i.Name,
 			Kind: i.Kind,
 		}))
@@ -38,8 +40,8 @@ func TestAlbIngressShouldContainAllResources(t *testing.T) {
 }
 
 func Test_checkSetAwsRegion(t *testing.T) {
-	helmChart := NewHelmConfigParser(
-		NewHelmTest(t, helmChartRelativePath, map[string]string{
+	helmChart := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTest(t, helmChartRelativePath, addonsHelmRelease, map[string]string{
 			"aws-load-balancer-controller.enabled":               "true",
 			"aws-load-balancer-controller.autoDiscoverAwsRegion": "false",
 			"aws-load-balancer-controller.region":             "YOUR_EKS_CLUSTER_REGION",
@@ -47,7 +49,7 @@ func Test_checkSetAwsRegion(t *testing.T) {
 	)
 
 	var deployment *v1.Deployment
-	helmChart.Find(SearchResourceOption{
+	helmChart.Find(helmtest.SearchResourceOption{
 		Name: "pega-aws-load-balancer-controller",
 		Kind: "Deployment",
 	}, &deployment)
@@ -56,15 +58,15 @@ func Test_checkSetAwsRegion(t *testing.T) {
 }
 
 func Test_checkSetAwsVpcID(t *testing.T) {
-	helmChart := NewHelmConfigParser(
-		NewHelmTest(t, helmChartRelativePath, map[string]string{
+	helmChart := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTest(t, helmChartRelativePath, addonsHelmRelease, map[string]string{
 			"aws-load-balancer-controller.enabled":              "true",
 			"aws-load-balancer-controller.vpcId":             "YOUR_EKS_CLUSTER_VPC_ID",
 		}),
 	)
 
 	var deployment *v1.Deployment
-	helmChart.Find(SearchResourceOption{
+	helmChart.Find(helmtest.SearchResourceOption{
 		Name: "pega-aws-load-balancer-controller",
 		Kind: "Deployment",
 	}, &deployment)
@@ -73,13 +75,13 @@ func Test_checkSetAwsVpcID(t *testing.T) {
 }
 
 func Test_checkSetServiceAnnotation(t *testing.T) {
-	helmChart := NewHelmConfigParser(
-		NewHelmTest(t, helmChartRelativePath, map[string]string{
+	helmChart := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTest(t, helmChartRelativePath, addonsHelmRelease, map[string]string{
 			"aws-load-balancer-controller.enabled":              "true",
 		}),
 	)
 	var serviceAccount *corev1.ServiceAccount
-	helmChart.Find(SearchResourceOption{
+	helmChart.Find(helmtest.SearchResourceOption{
 		Name: "pega-aws-load-balancer-controller",
 		Kind: "ServiceAccount",
 	}, &serviceAccount)
@@ -88,15 +90,15 @@ func Test_checkSetServiceAnnotation(t *testing.T) {
 }
 
 func Test_checkSetClusterName(t *testing.T) {
-	helmChart := NewHelmConfigParser(
-		NewHelmTest(t, helmChartRelativePath, map[string]string{
+	helmChart := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTest(t, helmChartRelativePath, addonsHelmRelease, map[string]string{
 			"aws-load-balancer-controller.enabled":     "true",
 			"aws-load-balancer-controller.clusterName": "YOUR_EKS_CLUSTER_NAME",
 		}),
 	)
 
 	var deployment *v1.Deployment
-	helmChart.Find(SearchResourceOption{
+	helmChart.Find(helmtest.SearchResourceOption{
 		Name: "pega-aws-load-balancer-controller",
 		Kind: "Deployment",
 	}, &deployment)
@@ -104,7 +106,7 @@ func Test_checkSetClusterName(t *testing.T) {
 	require.Contains(t, deployment.Spec.Template.Spec.Containers[0].Args, "--cluster-name=YOUR_EKS_CLUSTER_NAME")
 }
 
-var albIngressResources = []SearchResourceOption{
+var albIngressResources = []helmtest.SearchResourceOption{
 	{
 		Name: "pega-aws-load-balancer-controller",
 		Kind: "ServiceAccount",
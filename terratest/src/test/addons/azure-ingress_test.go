@@ -2,20 +2,22 @@ package addons
 
 import (
 	b64 "encoding/base64"
+	"testing"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/core/v1"
-	"testing"
 )
 
 func TestShouldNotContainAzureIngressIfDisabled(t *testing.T) {
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTest(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTest(t, helmChartRelativePath, addonsHelmRelease, map[string]string{
 			"ingress-azure.enabled": "false",
 		}),
 	)
 
 	for _, i := range azureIngressResources {
-		require.False(t, helmChartParser.Contains(SearchResourceOption{
+		require.False(t, helmChartParser.Contains(helmtest.SearchResourceOption{
 			Name: i.Name,
 			Kind: i.Kind,
 		}))
@@ -23,14 +25,14 @@ func TestShouldNotContainAzureIngressIfDisabled(t *testing.T) {
 }
 
 func TestAzureIngressShouldContainAllResources(t *testing.T) {
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTest(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTest(t, helmChartRelativePath, addonsHelmRelease, map[string]string{
 			"ingress-azure.enabled": "true",
 		}),
 	)
 
 	for _, i := range azureIngressResources {
-		require.True(t, helmChartParser.Contains(SearchResourceOption{
+		require.True(t, helmChartParser.Contains(helmtest.SearchResourceOption{
 			Name: i.Name,
 			Kind: i.Kind,
 		}))
@@ -38,8 +40,8 @@ func TestAzureIngressShouldContainAllResources(t *testing.T) {
 }
 
 func TestSetValuesForAppGW(t *testing.T) {
-	helmChart := NewHelmConfigParser(
-		NewHelmTest(t, helmChartRelativePath, map[string]string{
+	helmChart := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTest(t, helmChartRelativePath, addonsHelmRelease, map[string]string{
 			"ingress-azure.enabled":              "true",
 			"ingress-azure.appgw.subscriptionId": "<YOUR.SUBSCRIPTION_ID>",
 			"ingress-azure.appgw.resourceGroup":  "<RESOURCE_GROUP_NAME>",
@@ -48,7 +50,7 @@ func TestSetValuesForAppGW(t *testing.T) {
 	)
 
 	var configMap *v1.ConfigMap
-	helmChart.Find(SearchResourceOption{
+	helmChart.Find(helmtest.SearchResourceOption{
 		Name: "pega-cm-ingress-azure",
 		Kind: "ConfigMap",
 	}, &configMap)
@@ -60,8 +62,8 @@ func TestSetValuesForAppGW(t *testing.T) {
 }
 
 func TestSetValuesForArmAuth(t *testing.T) {
-	helmChart := NewHelmConfigParser(
-		NewHelmTest(t, helmChartRelativePath, map[string]string{
+	helmChart := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTest(t, helmChartRelativePath, addonsHelmRelease, map[string]string{
 			"ingress-azure.enabled":            "true",
 			"ingress-azure.armAuth.type":       "servicePrincipal",
 			"ingress-azure.armAuth.secretJSON": b64.StdEncoding.EncodeToString([]byte("<SECRET_JSON_CREATED_USING_ABOVE_COMMAND>")),
@@ -69,7 +71,7 @@ func TestSetValuesForArmAuth(t *testing.T) {
 	)
 
 	var secret *v1.Secret
-	helmChart.Find(SearchResourceOption{
+	helmChart.Find(helmtest.SearchResourceOption{
 		Name: "networking-appgw-k8s-azure-service-principal",
 		Kind: "Secret",
 	}, &secret)
@@ -77,7 +79,7 @@ func TestSetValuesForArmAuth(t *testing.T) {
 	require.Equal(t, "<SECRET_JSON_CREATED_USING_ABOVE_COMMAND>", string(secret.Data["armAuth.json"]))
 }
 
-var azureIngressResources = []SearchResourceOption{
+var azureIngressResources = []helmtest.SearchResourceOption{
 	{
 		Name: "networking-appgw-k8s-azure-service-principal",
 		Kind: "Secret",
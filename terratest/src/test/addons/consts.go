@@ -1,3 +1,4 @@
 package addons
 
 const helmChartRelativePath = "../../../../charts/addons"
+const addonsHelmRelease = "pega"
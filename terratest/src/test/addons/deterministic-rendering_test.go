@@ -0,0 +1,27 @@
+package addons
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+)
+
+var (
+	renderCount = flag.Int("renders", 3, "number of times the determinism tests render every scenario")
+	renderSeed  = flag.Int64("render-seed", 0, "seed shuffling the values files of the determinism tests, random when 0")
+)
+
+// TestAddonsDeterministicRendering - renders the chart with the addons enabled several times with a shuffled
+// values.yaml, which must give byte-identical output
+func TestAddonsDeterministicRendering(t *testing.T) {
+	t.Parallel()
+	helmtest.AssertDeterministicRendering(t, helmtest.Scenario{
+		Chart:       helmtest.AddonsChart,
+		ReleaseName: addonsHelmRelease,
+		SetValues: map[string]string{
+			"traefik.enabled":        "true",
+			"metrics-server.enabled": "true",
+		},
+	}, *renderCount, *renderSeed)
+}
@@ -1,15 +1,17 @@
 package addons
 
 import (
+	"testing"
+
 	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	"k8s.io/api/apps/v1beta2"
-	"testing"
 )
 
 func TestShouldNotContainDeploy_EFKIfDisabled(t *testing.T) {
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTest(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTest(t, helmChartRelativePath, addonsHelmRelease, map[string]string{
 			"elasticsearch.enabled":         "false",
 			"kibana.enabled":                "false",
 			"fluentd-elasticsearch.enabled": "false",
@@ -17,7 +19,7 @@ func TestShouldNotContainDeploy_EFKIfDisabled(t *testing.T) {
 	)
 
 	for _, i := range deployEfkResources {
-		require.False(t, helmChartParser.Contains(SearchResourceOption{
+		require.False(t, helmChartParser.Contains(helmtest.SearchResourceOption{
 			Name: i.Name,
 			Kind: i.Kind,
 		}))
@@ -25,8 +27,8 @@ func TestShouldNotContainDeploy_EFKIfDisabled(t *testing.T) {
 }
 
 func TestShouldDeploy_EFKContainAllResourcesIfEnabled(t *testing.T) {
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTest(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTest(t, helmChartRelativePath, addonsHelmRelease, map[string]string{
 			"elasticsearch.enabled":         "true",
 			"kibana.enabled":                "true",
 			"fluentd-elasticsearch.enabled": "true",
@@ -34,7 +36,7 @@ func TestShouldDeploy_EFKContainAllResourcesIfEnabled(t *testing.T) {
 	)
 
 	for _, i := range deployEfkResources {
-		require.True(t, helmChartParser.Contains(SearchResourceOption{
+		require.True(t, helmChartParser.Contains(helmtest.SearchResourceOption{
 			Name: i.Name,
 			Kind: i.Kind,
 		}))
@@ -42,43 +44,43 @@ func TestShouldDeploy_EFKContainAllResourcesIfEnabled(t *testing.T) {
 }
 
 func Test_shouldBeIngressEnabledForKibana(t *testing.T) {
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTest(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTest(t, helmChartRelativePath, addonsHelmRelease, map[string]string{
 			"kibana.enabled":         "true",
 			"kibana.ingress.enabled": "true",
 		}),
 	)
 
-	require.True(t, helmChartParser.Contains(SearchResourceOption{
+	require.True(t, helmChartParser.Contains(helmtest.SearchResourceOption{
 		Name: "pega-kibana",
 		Kind: "Ingress",
 	}))
 }
 
 func Test_shouldBeIngressDisabledForKibana(t *testing.T) {
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTest(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTest(t, helmChartRelativePath, addonsHelmRelease, map[string]string{
 			"kibana.enabled":         "true",
 			"kibana.ingress.enabled": "false",
 		}),
 	)
 
-	require.False(t, helmChartParser.Contains(SearchResourceOption{
+	require.False(t, helmChartParser.Contains(helmtest.SearchResourceOption{
 		Name: "pega-kibana",
 		Kind: "Ingress",
 	}))
 }
 
 func Test_shouldBeHostForIngressKibana(t *testing.T) {
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTest(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTest(t, helmChartRelativePath, addonsHelmRelease, map[string]string{
 			"kibana.enabled":               "true",
 			"kibana.ingress.enabled":       "true",
 			"kibana.ingress.hosts[0].host": "{YOUR_WEB.KIBANA.EXAMPLE.COM}",
 		}),
 	)
 
-	var d DeploymentMetadata
+	var d helmtest.DeploymentMetadata
 	var ingress string
 	for _, slice := range helmChartParser.SlicedResource {
 		helm.UnmarshalK8SYaml(t, slice, &d)
@@ -91,15 +93,15 @@ func Test_shouldBeHostForIngressKibana(t *testing.T) {
 	require.Contains(t, ingress, "host: [YOUR_WEB.KIBANA.EXAMPLE.COM]")
 }
 func Test_shouldBeHostForElasticsearch(t *testing.T) {
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTest(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTest(t, helmChartRelativePath, addonsHelmRelease, map[string]string{
 			"fluentd-elasticsearch.enabled":            "true",
 			"fluentd-elasticsearch.elasticsearch.host": "elasticsearch-master:9200",
 		}),
 	)
 
 	var daemon *v1beta2.DaemonSet
-	helmChartParser.Find(SearchResourceOption{
+	helmChartParser.Find(helmtest.SearchResourceOption{
 		Name: "pega-fluentd-elasticsearch",
 		Kind: "DaemonSet",
 	}, &daemon)
@@ -107,7 +109,7 @@ func Test_shouldBeHostForElasticsearch(t *testing.T) {
 	require.Equal(t, "elasticsearch-master:9200", daemon.Spec.Template.Spec.Containers[0].Env[1].Value)
 }
 
-var deployEfkResources = []SearchResourceOption{
+var deployEfkResources = []helmtest.SearchResourceOption{
 	{
 		Name: "pega-kibana",
 		Kind: "Service",
@@ -0,0 +1,29 @@
+package addons
+
+import (
+	"testing"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAddonsKubeVersions - the addons templates have no version-specific APIs of their own, but the traefik and
+// metrics-server charts they enable must still render their resources for every supported Kubernetes version
+func TestAddonsKubeVersions(t *testing.T) {
+	helmtest.Matrix{KubeVersions: helmtest.SupportedKubeVersions}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		helmChartParser := helmtest.NewHelmConfigParser(&helmtest.HelmTest{
+			T:           t,
+			ChartPath:   helmChartRelativePath,
+			ReleaseName: addonsHelmRelease,
+			KubeVersion: combination.KubeVersion,
+			HelmOptions: combination.HelmOptions(map[string]string{
+				"traefik.enabled":        "true",
+				"metrics-server.enabled": "true",
+			}),
+		})
+
+		for _, resource := range append(traefikResources, metricServerResources...) {
+			require.True(t, helmChartParser.Contains(resource), "%s %s is not rendered", resource.Kind, resource.Name)
+		}
+	})
+}
@@ -1,20 +1,22 @@
 package addons
 
 import (
+	"testing"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	"k8s.io/api/apps/v1"
-	"testing"
 )
 
 func Test_shouldNotContainMetricServerIfDisabled(t *testing.T) {
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTest(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTest(t, helmChartRelativePath, addonsHelmRelease, map[string]string{
 			"metrics-server.enabled": "false",
 		}),
 	)
 
 	for _, i := range metricServerResources {
-		require.False(t, helmChartParser.Contains(SearchResourceOption{
+		require.False(t, helmChartParser.Contains(helmtest.SearchResourceOption{
 			Name: i.Name,
 			Kind: i.Kind,
 		}))
@@ -22,14 +24,14 @@ func Test_shouldNotContainMetricServerIfDisabled(t *testing.T) {
 }
 
 func Test_shouldContainMetricServerIfEnabled(t *testing.T) {
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTest(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTest(t, helmChartRelativePath, addonsHelmRelease, map[string]string{
 			"metrics-server.enabled": "true",
 		}),
 	)
 
 	for _, i := range metricServerResources {
-		require.True(t, helmChartParser.Contains(SearchResourceOption{
+		require.True(t, helmChartParser.Contains(helmtest.SearchResourceOption{
 			Name: i.Name,
 			Kind: i.Kind,
 		}))
@@ -37,12 +39,12 @@ func Test_shouldContainMetricServerIfEnabled(t *testing.T) {
 }
 
 func Test_shouldContainCommandArgs(t *testing.T) {
-	helmChartParser := NewHelmConfigParser(NewHelmTest(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(helmtest.NewHelmTest(t, helmChartRelativePath, addonsHelmRelease, map[string]string{
 		"metrics-server.enabled": "true",
 	}))
 
 	var deployment *v1.Deployment
-	helmChartParser.Find(SearchResourceOption{
+	helmChartParser.Find(helmtest.SearchResourceOption{
 		Name: "pega-metrics-server",
 		Kind: "Deployment",
 	}, &deployment)
@@ -50,7 +52,7 @@ func Test_shouldContainCommandArgs(t *testing.T) {
 	require.Contains(t, deployment.Spec.Template.Spec.Containers[0].Args, "--logtostderr")
 }
 
-var metricServerResources = []SearchResourceOption{
+var metricServerResources = []helmtest.SearchResourceOption{
 	{
 		Name: "pega-metrics-server",
 		Kind: "ServiceAccount",
@@ -0,0 +1,61 @@
+package addons
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/policy"
+	"github.com/stretchr/testify/require"
+)
+
+// policyExemption - a rule that the resources rendered from the templates under source are not held to
+type policyExemption struct {
+	rule   string
+	source string
+}
+
+// addonsPolicyExemptions - traefik and metrics-server take their image tags from the upstream charts, which Pega does
+// not maintain, so their images are not pinned to a digest
+var addonsPolicyExemptions = []policyExemption{
+	{rule: "pinned-image-tags", source: "addons/charts/traefik/"},
+	{rule: "pinned-image-tags", source: "addons/charts/metrics-server/"},
+}
+
+func exempted(violation policy.Violation) bool {
+	for _, exemption := range addonsPolicyExemptions {
+		if violation.Rule == exemption.rule && strings.Contains(violation.Resource, "("+exemption.source) {
+			return true
+		}
+	}
+	return false
+}
+
+// TestAddonsPolicyReport - with cpu and memory limits set, traefik and metrics-server meet every rule of the default
+// pack apart from the exemptions above
+func TestAddonsPolicyReport(t *testing.T) {
+	t.Parallel()
+	helmTest := &helmtest.HelmTest{
+		T:           t,
+		ChartPath:   helmChartRelativePath,
+		ReleaseName: addonsHelmRelease,
+		HelmOptions: &helm.Options{SetValues: map[string]string{
+			"traefik.enabled":                        "true",
+			"traefik.resources.limits.cpu":           "500m",
+			"traefik.resources.limits.memory":        "500Mi",
+			"metrics-server.enabled":                 "true",
+			"metrics-server.resources.limits.cpu":    "100m",
+			"metrics-server.resources.limits.memory": "200Mi",
+		}},
+	}
+	reports := policy.NewEngine().Evaluate(helmtest.NewHelmConfigParser(helmTest).Query(helmtest.ResourceQuery{}))
+
+	var remaining []policy.Violation
+	for _, violation := range policy.Violations(reports) {
+		if !exempted(violation) {
+			remaining = append(remaining, violation)
+		}
+	}
+	require.Empty(t, remaining, policy.Describe(reports))
+}
@@ -4,19 +4,20 @@ import (
 	"testing"
 
 	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	v12 "k8s.io/api/apps/v1"
 )
 
 func Test_shouldNotContainTraefikResourcesWhenDisabled(t *testing.T) {
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTest(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTest(t, helmChartRelativePath, addonsHelmRelease, map[string]string{
 			"traefik.enabled": "false",
 		}),
 	)
 
 	for _, i := range traefikResources {
-		require.False(t, helmChartParser.Contains(SearchResourceOption{
+		require.False(t, helmChartParser.Contains(helmtest.SearchResourceOption{
 			Name: i.Name,
 			Kind: i.Kind,
 		}))
@@ -24,14 +25,14 @@ func Test_shouldNotContainTraefikResourcesWhenDisabled(t *testing.T) {
 }
 
 func Test_shouldContainTraefikResourcesWhenEnabled(t *testing.T) {
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTest(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTest(t, helmChartRelativePath, addonsHelmRelease, map[string]string{
 			"traefik.enabled": "true",
 		}),
 	)
 
 	for _, i := range traefikResources {
-		require.True(t, helmChartParser.Contains(SearchResourceOption{
+		require.True(t, helmChartParser.Contains(helmtest.SearchResourceOption{
 			Name: i.Name,
 			Kind: i.Kind,
 		}))
@@ -39,14 +40,14 @@ func Test_shouldContainTraefikResourcesWhenEnabled(t *testing.T) {
 }
 
 func Test_shouldBeAbleToSetUpServiceTypeAsLoadBalancer(t *testing.T) {
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTest(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTest(t, helmChartRelativePath, addonsHelmRelease, map[string]string{
 			"traefik.enabled":      "true",
 			"traefik.service.type": "LoadBalancer",
 		}),
 	)
 
-	var d DeploymentMetadata
+	var d helmtest.DeploymentMetadata
 	var list string
 	for _, slice := range helmChartParser.SlicedResource {
 		helm.UnmarshalK8SYaml(helmChartParser.T, slice, &d)
@@ -61,14 +62,14 @@ func Test_shouldBeAbleToSetUpServiceTypeAsLoadBalancer(t *testing.T) {
 }
 
 func Test_shouldBeAbleToSetUpServiceTypeAsNodePort(t *testing.T) {
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTest(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTest(t, helmChartRelativePath, addonsHelmRelease, map[string]string{
 			"traefik.enabled":      "true",
 			"traefik.service.type": "NodePort",
 		}),
 	)
 
-	var d DeploymentMetadata
+	var d helmtest.DeploymentMetadata
 	var list string
 	for _, slice := range helmChartParser.SlicedResource {
 		helm.UnmarshalK8SYaml(helmChartParser.T, slice, &d)
@@ -83,53 +84,53 @@ func Test_shouldBeAbleToSetUpServiceTypeAsNodePort(t *testing.T) {
 }
 
 func Test_hasRoleWhenRbacEnabled(t *testing.T) {
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTest(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTest(t, helmChartRelativePath, addonsHelmRelease, map[string]string{
 			"traefik.enabled":      "true",
 			"traefik.rbac.enabled": "true",
 		}),
 	)
 
-	require.True(t, helmChartParser.Contains(SearchResourceOption{
+	require.True(t, helmChartParser.Contains(helmtest.SearchResourceOption{
 		Name: "pega-traefik",
 		Kind: "ClusterRole",
 	}))
 
-	require.True(t, helmChartParser.Contains(SearchResourceOption{
+	require.True(t, helmChartParser.Contains(helmtest.SearchResourceOption{
 		Name: "pega-traefik",
 		Kind: "ServiceAccount",
 	}))
 
-	require.True(t, helmChartParser.Contains(SearchResourceOption{
+	require.True(t, helmChartParser.Contains(helmtest.SearchResourceOption{
 		Name: "pega-traefik",
 		Kind: "ClusterRoleBinding",
 	}))
 }
 
 func Test_noRoleWhenRbacDisabled(t *testing.T) {
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTest(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTest(t, helmChartRelativePath, addonsHelmRelease, map[string]string{
 			"traefik.enabled":      "true",
 			"traefik.rbac.enabled": "false",
 		}),
 	)
 
-	require.False(t, helmChartParser.Contains(SearchResourceOption{
+	require.False(t, helmChartParser.Contains(helmtest.SearchResourceOption{
 		Name: "pega-traefik",
 		Kind: "ClusterRole",
 	}))
 }
 
 func Test_hasSecretWhenSSLEnabled(t *testing.T) {
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTest(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTest(t, helmChartRelativePath, addonsHelmRelease, map[string]string{
 			"traefik.enabled":                     "true",
 			"traefik.ports.websecure.tls.enabled": "true",
 		}),
 	)
 
 	var deployment v12.Deployment
-	helmChartParser.Find(SearchResourceOption{
+	helmChartParser.Find(helmtest.SearchResourceOption{
 		Name: "pega-traefik",
 		Kind: "Deployment",
 	}, &deployment)
@@ -138,15 +139,15 @@ func Test_hasSecretWhenSSLEnabled(t *testing.T) {
 }
 
 func Test_hasNoSecretWhenSSLEnabled(t *testing.T) {
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTest(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTest(t, helmChartRelativePath, addonsHelmRelease, map[string]string{
 			"traefik.enabled":                     "true",
 			"traefik.ports.websecure.tls.enabled": "false",
 		}),
 	)
 
 	var deployment v12.Deployment
-	helmChartParser.Find(SearchResourceOption{
+	helmChartParser.Find(helmtest.SearchResourceOption{
 		Name: "pega-traefik",
 		Kind: "Deployment",
 	}, &deployment)
@@ -155,8 +156,8 @@ func Test_hasNoSecretWhenSSLEnabled(t *testing.T) {
 }
 
 func Test_checkResourceRequests(t *testing.T) {
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTest(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTest(t, helmChartRelativePath, addonsHelmRelease, map[string]string{
 			"traefik.enabled":                   "true",
 			"traefik.resources.requests.cpu":    "300m",
 			"traefik.resources.requests.memory": "300Mi",
@@ -164,7 +165,7 @@ func Test_checkResourceRequests(t *testing.T) {
 	)
 
 	var deployment v12.Deployment
-	helmChartParser.Find(SearchResourceOption{
+	helmChartParser.Find(helmtest.SearchResourceOption{
 		Name: "pega-traefik",
 		Kind: "Deployment",
 	}, &deployment)
@@ -174,8 +175,8 @@ func Test_checkResourceRequests(t *testing.T) {
 }
 
 func Test_checkResourceLimits(t *testing.T) {
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTest(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTest(t, helmChartRelativePath, addonsHelmRelease, map[string]string{
 			"traefik.enabled":                 "true",
 			"traefik.resources.limits.cpu":    "600m",
 			"traefik.resources.limits.memory": "600Mi",
@@ -183,7 +184,7 @@ func Test_checkResourceLimits(t *testing.T) {
 	)
 
 	var deployment v12.Deployment
-	helmChartParser.Find(SearchResourceOption{
+	helmChartParser.Find(helmtest.SearchResourceOption{
 		Name: "pega-traefik",
 		Kind: "Deployment",
 	}, &deployment)
@@ -193,14 +194,14 @@ func Test_checkResourceLimits(t *testing.T) {
 }
 
 func Test_checkDefaultResourceRequests(t *testing.T) {
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTest(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTest(t, helmChartRelativePath, addonsHelmRelease, map[string]string{
 			"traefik.enabled": "true",
 		}),
 	)
 
 	var deployment v12.Deployment
-	helmChartParser.Find(SearchResourceOption{
+	helmChartParser.Find(helmtest.SearchResourceOption{
 		Name: "pega-traefik",
 		Kind: "Deployment",
 	}, &deployment)
@@ -210,14 +211,14 @@ func Test_checkDefaultResourceRequests(t *testing.T) {
 }
 
 func Test_checkDefaultResourceLimits(t *testing.T) {
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTest(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTest(t, helmChartRelativePath, addonsHelmRelease, map[string]string{
 			"traefik.enabled": "true",
 		}),
 	)
 
 	var deployment v12.Deployment
-	helmChartParser.Find(SearchResourceOption{
+	helmChartParser.Find(helmtest.SearchResourceOption{
 		Name: "pega-traefik",
 		Kind: "Deployment",
 	}, &deployment)
@@ -226,7 +227,7 @@ func Test_checkDefaultResourceLimits(t *testing.T) {
 	require.Equal(t, "500Mi", deployment.Spec.Template.Spec.Containers[0].Resources.Limits.Memory().String())
 }
 
-var traefikResources = []SearchResourceOption{
+var traefikResources = []helmtest.SearchResourceOption{
 	{
 		Name: "pega-traefik",
 		Kind: "ServiceAccount",
@@ -1,6 +0,0 @@
-package backingservices
-
-type SearchResourceOption struct {
-	Name string
-	Kind string
-}
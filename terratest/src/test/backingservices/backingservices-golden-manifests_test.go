@@ -0,0 +1,59 @@
+package backingservices
+
+import (
+	"flag"
+	"path/filepath"
+	"testing"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "regenerate golden manifests instead of comparing against them")
+
+// goldenScenarios render the chart with each service enabled. The internal Elasticsearch cluster comes from a remote
+// chart and is left out.
+func goldenScenarios() []helmtest.Scenario {
+	scenarios := []helmtest.Scenario{
+		{
+			Name: "srs",
+			SetValues: map[string]string{
+				"srs.enabled": "true",
+				"srs.srsStorage.provisionInternalESCluster":  "false",
+				"srs.srsStorage.domain":                      "elasticsearch.example.com",
+				"srs.srsStorage.port":                        "9200",
+				"srs.srsStorage.protocol":                    "https",
+				"srs.srsStorage.tls.enabled":                 "false",
+				"srs.srsStorage.basicAuthentication.enabled": "false",
+			},
+		},
+		{
+			Name: "constellation",
+			SetValues: map[string]string{
+				"constellation.enabled":           "true",
+				"constellation-messaging.enabled": "true",
+			},
+		},
+	}
+	for i := range scenarios {
+		scenarios[i].Chart = helmtest.BackingServicesChart
+		scenarios[i].ReleaseName = srsHelmRelease
+		scenarios[i].KubeVersion = "1.27.0"
+	}
+	return scenarios
+}
+
+// TestBackingServicesGoldenManifests - compares the complete rendered chart with backingservices/data/golden; run with
+// -update to regenerate
+func TestBackingServicesGoldenManifests(t *testing.T) {
+	goldenPath, err := filepath.Abs("data/golden")
+	require.NoError(t, err)
+
+	for _, scenario := range goldenScenarios() {
+		scenario := scenario
+		t.Run(scenario.ScenarioName(), func(t *testing.T) {
+			t.Parallel()
+			helmtest.AssertGoldenManifests(t, goldenPath, scenario, *updateGolden)
+		})
+	}
+}
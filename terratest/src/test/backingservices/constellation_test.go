@@ -1,19 +1,21 @@
 package backingservices
 
 import (
-	"github.com/stretchr/testify/require"
 	"testing"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_shouldNotContainConstellationResourcesWhenDisabled(t *testing.T) {
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTest(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTest(t, helmChartRelativePath, srsHelmRelease, map[string]string{
 			"constellation.enabled": "false",
 		}),
 	)
 
 	for _, i := range constellationResources {
-		require.False(t, helmChartParser.Contains(SearchResourceOption{
+		require.False(t, helmChartParser.Contains(helmtest.SearchResourceOption{
 			Name: i.Name,
 			Kind: i.Kind,
 		}))
@@ -21,14 +23,14 @@ func Test_shouldNotContainConstellationResourcesWhenDisabled(t *testing.T) {
 }
 
 func Test_shouldContainConstellationResourcesWhenEnabled(t *testing.T) {
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTest(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTest(t, helmChartRelativePath, srsHelmRelease, map[string]string{
 			"constellation.enabled": "true",
 		}),
 	)
 
 	for _, i := range constellationResources {
-		require.True(t, helmChartParser.Contains(SearchResourceOption{
+		require.True(t, helmChartParser.Contains(helmtest.SearchResourceOption{
 			Name: i.Name,
 			Kind: i.Kind,
 		}))
@@ -36,8 +38,8 @@ func Test_shouldContainConstellationResourcesWhenEnabled(t *testing.T) {
 }
 
 func Test_shouldContainConstellationMessagingWhenEnabled(t *testing.T) {
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTest(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTest(t, helmChartRelativePath, srsHelmRelease, map[string]string{
 			"srs.enabled": "false",
 			"constellation-messaging.enabled": "true",
 			"constellation-messaging.name": "constellation-messaging",
@@ -49,7 +51,7 @@ func Test_shouldContainConstellationMessagingWhenEnabled(t *testing.T) {
 	)
 
 	for _, i := range constellationMessagingResources {
-		require.True(t, helmChartParser.Contains(SearchResourceOption{
+		require.True(t, helmChartParser.Contains(helmtest.SearchResourceOption{
 			Name: i.Name,
 			Kind: i.Kind,
 		}))
@@ -57,8 +59,8 @@ func Test_shouldContainConstellationMessagingWhenEnabled(t *testing.T) {
 }
 
 func Test_shouldNotContainConstellationMessagingWhenDisabled(t *testing.T) {
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTest(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTest(t, helmChartRelativePath, srsHelmRelease, map[string]string{
 			"srs.enabled": "false",
 			"constellation-messaging.enabled": "false",
 			"constellation-messaging.name": "constellation-messaging",
@@ -70,14 +72,14 @@ func Test_shouldNotContainConstellationMessagingWhenDisabled(t *testing.T) {
 	)
 
 	for _, i := range constellationMessagingResources {
-		require.False(t, helmChartParser.Contains(SearchResourceOption{
+		require.False(t, helmChartParser.Contains(helmtest.SearchResourceOption{
 			Name: i.Name,
 			Kind: i.Kind,
 		}))
 	}
 }
 
-var constellationResources = []SearchResourceOption{
+var constellationResources = []helmtest.SearchResourceOption{
 	{
 		Name: "constellation",
 		Kind: "Deployment",
@@ -96,7 +98,7 @@ var constellationResources = []SearchResourceOption{
 	},
 }
 
-var constellationMessagingResources = []SearchResourceOption{
+var constellationMessagingResources = []helmtest.SearchResourceOption{
 	{
 		Name: "constellation-messaging",
 		Kind: "Deployment",
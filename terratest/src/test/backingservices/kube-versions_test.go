@@ -0,0 +1,32 @@
+package backingservices
+
+import (
+	"testing"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSRSKubeVersionAPIChoices - renders the SRS subchart for every supported Kubernetes version
+func TestSRSKubeVersionAPIChoices(t *testing.T) {
+	helmtest.Matrix{KubeVersions: helmtest.SupportedKubeVersions}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		helmChartParser := helmtest.NewHelmConfigParser(&helmtest.HelmTest{
+			T:           t,
+			ChartPath:   helmtest.ChartPath(helmtest.BackingServicesChart),
+			ReleaseName: srsHelmRelease,
+			KubeVersion: combination.KubeVersion,
+			HelmOptions: combination.HelmOptions(map[string]string{
+				"srs.enabled":                "true",
+				"srs.deploymentName":         "test-srs",
+				"srs.srsStorage.tls.enabled": "false",
+			}),
+			Templates: []string{"charts/srs/templates/srsservice_poddisruptionbudget.yaml"},
+		})
+
+		expectedPDBVersion := "policy/v1beta1"
+		if helmtest.KubeVersionAtLeast(combination.KubeVersion, 1, 21) {
+			expectedPDBVersion = "policy/v1"
+		}
+		require.Equal(t, expectedPDBVersion, helmChartParser.QueryOne(helmtest.ResourceQuery{Kind: "PodDisruptionBudget"}).APIVersion)
+	})
+}
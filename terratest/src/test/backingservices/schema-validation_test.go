@@ -0,0 +1,58 @@
+package backingservices
+
+import (
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/validation"
+)
+
+// TestBackingServicesManifestsMatchKubernetesSchemas - validates the rendered SRS, with and without its
+// VerticalPodAutoscaler, and Constellation resources against the OpenAPI schema of each validated Kubernetes version
+func TestBackingServicesManifestsMatchKubernetesSchemas(t *testing.T) {
+	services := map[string]map[string]string{
+		"srs": {
+			"srs.enabled": "true",
+			"srs.srsStorage.provisionInternalESCluster":  "false",
+			"srs.srsStorage.domain":                      "elasticsearch.example.com",
+			"srs.srsStorage.port":                        "9200",
+			"srs.srsStorage.protocol":                    "https",
+			"srs.srsStorage.tls.enabled":                 "false",
+			"srs.srsStorage.basicAuthentication.enabled": "false",
+		},
+		"srs-vpa": {
+			"srs.enabled": "true",
+			"srs.srsStorage.provisionInternalESCluster":  "false",
+			"srs.srsStorage.domain":                      "elasticsearch.example.com",
+			"srs.srsStorage.port":                        "9200",
+			"srs.srsStorage.protocol":                    "https",
+			"srs.srsStorage.tls.enabled":                 "false",
+			"srs.srsStorage.basicAuthentication.enabled": "false",
+			"srs.srsRuntime.vpa.enabled":                 "true",
+			"srs.srsRuntime.vpa.minAllowed.cpu":          "500m",
+			"srs.srsRuntime.vpa.maxAllowed.cpu":          "2",
+			"srs.srsRuntime.vpa.maxAllowed.memory":       "4Gi",
+		},
+		"constellation": {
+			"constellation.enabled":           "true",
+			"constellation-messaging.enabled": "true",
+		},
+	}
+	for name, setValues := range services {
+		setValues := setValues
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			helmtest.Matrix{KubeVersions: helmtest.ValidatedKubeVersions}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+				parser := helmtest.NewHelmConfigParser(&helmtest.HelmTest{
+					T:           t,
+					ChartPath:   helmtest.ChartPath(helmtest.BackingServicesChart),
+					ReleaseName: srsHelmRelease,
+					KubeVersion: combination.KubeVersion,
+					HelmOptions: &helm.Options{SetValues: setValues},
+				})
+				validation.AssertValid(t, parser, combination.KubeVersion)
+			})
+		})
+	}
+}
@@ -1,18 +1,20 @@
 package backingservices
 
 import (
+	"strings"
+	"testing"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
 	k8score "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
-	"testing"
-	"strings"
 )
 
 func TestSRSDeployment(t *testing.T){
 
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTestFromTemplate(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTestFromTemplate(t, helmChartRelativePath, srsHelmRelease, map[string]string{
 			"srs.enabled": "true",
 			"srs.deploymentName": "test-srs",
 			"global.imageCredentials.registry": "docker-registry.io",
@@ -27,7 +29,7 @@ func TestSRSDeployment(t *testing.T){
 	)
 
 	var srsDeploymentObj appsv1.Deployment
-	helmChartParser.getResourceYAML(SearchResourceOption{
+	helmChartParser.Find(helmtest.SearchResourceOption{
 		Name: "test-srs",
 		Kind: "Deployment",
 	}, &srsDeploymentObj)
@@ -52,8 +54,8 @@ func TestSRSDeployment(t *testing.T){
 
 func TestSRSDeploymentVariables(t *testing.T){
 
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTestFromTemplate(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTestFromTemplate(t, helmChartRelativePath, srsHelmRelease, map[string]string{
 			"srs.enabled": "true",
 			"srs.deploymentName": "test-srs-dev",
 			"global.imageCredentials.registry": "docker-registry.io",
@@ -79,7 +81,7 @@ func TestSRSDeploymentVariables(t *testing.T){
 	)
 
 	var srsDeploymentObj appsv1.Deployment
-	helmChartParser.getResourceYAML(SearchResourceOption{
+	helmChartParser.Find(helmtest.SearchResourceOption{
 		Name: "test-srs-dev",
 		Kind: "Deployment",
 	}, &srsDeploymentObj)
@@ -105,8 +107,8 @@ func TestSRSDeploymentVariables(t *testing.T){
 
 func TestSRSDeploymentVariablesDefaultInternetEgress(t *testing.T){
 
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTestFromTemplate(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTestFromTemplate(t, helmChartRelativePath, srsHelmRelease, map[string]string{
 			"srs.enabled": "true",
 			"srs.deploymentName": "test-srs-dev",
 			"global.imageCredentials.registry": "docker-registry.io",
@@ -130,7 +132,7 @@ func TestSRSDeploymentVariablesDefaultInternetEgress(t *testing.T){
 	)
 
 	var srsDeploymentObj appsv1.Deployment
-	helmChartParser.getResourceYAML(SearchResourceOption{
+	helmChartParser.Find(helmtest.SearchResourceOption{
 		Name: "test-srs-dev",
 		Kind: "Deployment",
 	}, &srsDeploymentObj)
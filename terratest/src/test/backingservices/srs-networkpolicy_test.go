@@ -1,15 +1,17 @@
 package backingservices
 
 import (
+	"testing"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	networkingv1 "k8s.io/api/networking/v1"
-	"testing"
 )
 
 func TestSRSServiceNetworkPolicy(t *testing.T){
 
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTestFromTemplate(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTestFromTemplate(t, helmChartRelativePath, srsHelmRelease, map[string]string{
 			"srs.enabled": "true",
 			"srs.deploymentName": "test-srs",
 			"srs.srsStorage.tls.enabled": "false",
@@ -18,7 +20,7 @@ func TestSRSServiceNetworkPolicy(t *testing.T){
 	)
 
 	var networkPolicyObj networkingv1.NetworkPolicy
-	helmChartParser.getResourceYAML(SearchResourceOption{
+	helmChartParser.Find(helmtest.SearchResourceOption{
 		Name: "test-srs-networkpolicy",
 		Kind: "NetworkPolicy",
 	}, &networkPolicyObj)
@@ -27,8 +29,8 @@ func TestSRSServiceNetworkPolicy(t *testing.T){
 
 func TestSRSServiceNetworkPolicyWithProvisionInternalESCluster(t *testing.T){
 
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTestFromTemplate(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTestFromTemplate(t, helmChartRelativePath, srsHelmRelease, map[string]string{
 			"srs.enabled": "true",
 			"srs.deploymentName": "test-srs",
 			"srs.srsStorage.tls.enabled": "false",
@@ -39,7 +41,7 @@ func TestSRSServiceNetworkPolicyWithProvisionInternalESCluster(t *testing.T){
 	)
 
 	var networkPolicyObj networkingv1.NetworkPolicy
-	helmChartParser.getResourceYAML(SearchResourceOption{
+	helmChartParser.Find(helmtest.SearchResourceOption{
 		Name: "test-srs-networkpolicy",
 		Kind: "NetworkPolicy",
 	}, &networkPolicyObj)
@@ -48,8 +50,8 @@ func TestSRSServiceNetworkPolicyWithProvisionInternalESCluster(t *testing.T){
 
 func TestSRSServiceNetworkPolicyWithProvisionInternalESClusterFalse(t *testing.T){
 
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTestFromTemplate(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTestFromTemplate(t, helmChartRelativePath, srsHelmRelease, map[string]string{
 			"srs.enabled": "true",
 			"srs.deploymentName": "test-srs",
 			"srs.srsStorage.requireInternetAccess": "true",
@@ -64,7 +66,7 @@ func TestSRSServiceNetworkPolicyWithProvisionInternalESClusterFalse(t *testing.T
 	)
 
 	var networkPolicyObj networkingv1.NetworkPolicy
-	helmChartParser.getResourceYAML(SearchResourceOption{
+	helmChartParser.Find(helmtest.SearchResourceOption{
 		Name: "test-srs-networkpolicy",
 		Kind: "NetworkPolicy",
 	}, &networkPolicyObj)
@@ -1,15 +1,17 @@
 package backingservices
 
 import (
+	"testing"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	"k8s.io/api/policy/v1beta1"
-	"testing"
 )
 
 func TestSRSServicePDB(t *testing.T){
 
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTestFromTemplate(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTestFromTemplate(t, helmChartRelativePath, srsHelmRelease, map[string]string{
 			"srs.enabled": "true",
 			"srs.deploymentName": "test-srs",
 			"srs.srsStorage.tls.enabled": "false",
@@ -18,7 +20,7 @@ func TestSRSServicePDB(t *testing.T){
 	)
 
 	var pdbObj v1beta1.PodDisruptionBudget
-	helmChartParser.getResourceYAML(SearchResourceOption{
+	helmChartParser.Find(helmtest.SearchResourceOption{
 		Name: "test-srs",
 		Kind: "PodDisruptionBudget",
 	}, &pdbObj)
@@ -27,8 +29,8 @@ func TestSRSServicePDB(t *testing.T){
 
 func TestSRSServicePDBWithESInternetAccess(t *testing.T){
 
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTestFromTemplate(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTestFromTemplate(t, helmChartRelativePath, srsHelmRelease, map[string]string{
 			"srs.enabled": "true",
 			"srs.deploymentName": "test-srs",
 			"srs.srsStorage.tls.enabled": "false",
@@ -38,7 +40,7 @@ func TestSRSServicePDBWithESInternetAccess(t *testing.T){
 	)
 
 	var pdbObj v1beta1.PodDisruptionBudget
-	helmChartParser.getResourceYAML(SearchResourceOption{
+	helmChartParser.Find(helmtest.SearchResourceOption{
 		Name: "test-srs",
 		Kind: "PodDisruptionBudget",
 	}, &pdbObj)
@@ -47,8 +49,8 @@ func TestSRSServicePDBWithESInternetAccess(t *testing.T){
 
 func TestSRSServicePDBWithESInternetAccessWithExternalES(t *testing.T){
 
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTestFromTemplate(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTestFromTemplate(t, helmChartRelativePath, srsHelmRelease, map[string]string{
 			"srs.enabled": "true",
 			"srs.deploymentName": "test-srs",
 			"srs.srsStorage.requireInternetAccess": "true",
@@ -63,7 +65,7 @@ func TestSRSServicePDBWithESInternetAccessWithExternalES(t *testing.T){
 	)
 
 	var pdbObj v1beta1.PodDisruptionBudget
-	helmChartParser.getResourceYAML(SearchResourceOption{
+	helmChartParser.Find(helmtest.SearchResourceOption{
 		Name: "test-srs",
 		Kind: "PodDisruptionBudget",
 	}, &pdbObj)
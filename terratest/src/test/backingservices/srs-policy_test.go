@@ -0,0 +1,42 @@
+package backingservices
+
+import (
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/policy"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSRSPolicyDefaults - the default rule pack flags the srs containers, which set no security context, and the
+// unpinned images of the service and the wait-for-internal-es-cluster init container
+func TestSRSPolicyDefaults(t *testing.T) {
+	t.Parallel()
+	helmTest := &helmtest.HelmTest{
+		T:           t,
+		ChartPath:   helmChartRelativePath,
+		ReleaseName: srsHelmRelease,
+		HelmOptions: &helm.Options{SetValues: map[string]string{
+			"srs.deploymentName":                        "test-srs",
+			"srs.srsRuntime.srsImage":                   "pegasystems/search-n-reporting-service:1.28.1",
+			"srs.srsStorage.provisionInternalESCluster": "true",
+		}},
+		Templates: []string{srsDeploymentTemplate},
+	}
+	reports := policy.NewEngine().Evaluate(helmtest.NewHelmConfigParser(helmTest).Query(helmtest.ResourceQuery{}))
+
+	deployment := "Deployment/test-srs (backingservices/charts/srs/templates/srsservice_deployment.yaml)"
+	require.Equal(t, []policy.ResourceReport{{Resource: deployment, Violations: []policy.Violation{
+		{Rule: "run-as-non-root", Resource: deployment, Path: "spec.template.spec.initContainers[0]",
+			Message: "container wait-for-internal-es-cluster sets neither runAsNonRoot nor a non-zero runAsUser and may run as root"},
+		{Rule: "run-as-non-root", Resource: deployment, Path: "spec.template.spec.containers[0]",
+			Message: "container srs-service sets neither runAsNonRoot nor a non-zero runAsUser and may run as root"},
+		{Rule: "resource-limits", Resource: deployment, Path: "spec.template.spec.initContainers[0].resources.limits",
+			Message: "container wait-for-internal-es-cluster has no cpu or memory limit"},
+		{Rule: "pinned-image-tags", Resource: deployment, Path: "spec.template.spec.initContainers[0].image",
+			Message: "image alpine:3.18.3 of container wait-for-internal-es-cluster is not pinned to a digest"},
+		{Rule: "pinned-image-tags", Resource: deployment, Path: "spec.template.spec.containers[0].image",
+			Message: "image pegasystems/search-n-reporting-service:1.28.1 of container srs-service is not pinned to a digest"},
+	}}}, reports)
+}
@@ -1,13 +1,15 @@
 package backingservices
 
 import (
+	"testing"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
-	"testing"
 )
 func TestSRSRegistrySecretDefaultName(t *testing.T){
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTestFromTemplate(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTestFromTemplate(t, helmChartRelativePath, srsHelmRelease, map[string]string{
 			"srs.enabled": "true",
 			"srs.deploymentName": "test-srs",
 			"srs.srsStorage.tls.enabled": "false",
@@ -16,7 +18,7 @@ func TestSRSRegistrySecretDefaultName(t *testing.T){
 	)
 
 	var secret corev1.Secret
-	helmChartParser.getResourceYAML(SearchResourceOption{
+	helmChartParser.Find(helmtest.SearchResourceOption{
 		Name: "test-srs-reg-secret",
 		Kind: "Secret",
 	}, &secret)
@@ -26,8 +28,8 @@ func TestSRSRegistrySecretDefaultName(t *testing.T){
 }
 
 func TestSRSRegistrySecretCustomName(t *testing.T){
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTestFromTemplate(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTestFromTemplate(t, helmChartRelativePath, srsHelmRelease, map[string]string{
 			"srs.enabled": "true",
 			"srs.deploymentName": "custom-srs",
 			"global.imageCredentials.registry": "docker-repo.acme.io",
@@ -39,7 +41,7 @@ func TestSRSRegistrySecretCustomName(t *testing.T){
 	)
 
 	var secret corev1.Secret
-	helmChartParser.getResourceYAML(SearchResourceOption{
+	helmChartParser.Find(helmtest.SearchResourceOption{
 		Name: "custom-srs-reg-secret",
 		Kind: "Secret",
 	}, &secret)
@@ -1,16 +1,18 @@
 package backingservices
 
 import (
+	"testing"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	k8score "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
-	"testing"
 )
 
 func TestSRSService(t *testing.T){
 
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTestFromTemplate(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTestFromTemplate(t, helmChartRelativePath, srsHelmRelease, map[string]string{
 			"srs.enabled": "true",
 			"srs.deploymentName": "test-srs",
 			"srs.srsStorage.tls.enabled": "false",
@@ -19,7 +21,7 @@ func TestSRSService(t *testing.T){
 	)
 
 	var srsServiceObj k8score.Service
-	helmChartParser.getResourceYAML(SearchResourceOption{
+	helmChartParser.Find(helmtest.SearchResourceOption{
 		Name: "test-srs",
 		Kind: "Service",
 	}, &srsServiceObj)
@@ -28,8 +30,8 @@ func TestSRSService(t *testing.T){
 
 func TestSRSServiceWithInternetEgress(t *testing.T){
 
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTestFromTemplate(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTestFromTemplate(t, helmChartRelativePath, srsHelmRelease, map[string]string{
 			"srs.enabled": "true",
 			"srs.deploymentName": "test-srs",
 			"srs.srsStorage.requireInternetAccess": "true",
@@ -44,7 +46,7 @@ func TestSRSServiceWithInternetEgress(t *testing.T){
 	)
 
 	var srsServiceObj k8score.Service
-	helmChartParser.getResourceYAML(SearchResourceOption{
+	helmChartParser.Find(helmtest.SearchResourceOption{
 		Name: "test-srs",
 		Kind: "Service",
 	}, &srsServiceObj)
@@ -0,0 +1,91 @@
+package backingservices
+
+import (
+	"testing"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+)
+
+const srsDeploymentTemplate = "charts/srs/templates/srsservice_deployment.yaml"
+
+// TestSRSTemplateFailures - every fail and required guard in the srs templates stops rendering with its message.
+// The base values connect SRS to an external Elasticsearch without authentication, which renders cleanly.
+func TestSRSTemplateFailures(t *testing.T) {
+	helmtest.FailureSuite{
+		Chart:       helmtest.BackingServicesChart,
+		ReleaseName: srsHelmRelease,
+		BaseValues: map[string]string{
+			"srs.deploymentName":                         "test-srs",
+			"srs.srsStorage.provisionInternalESCluster":  "false",
+			"srs.srsStorage.domain":                      "es.example.com",
+			"srs.srsStorage.port":                        "9200",
+			"srs.srsStorage.protocol":                    "https",
+			"srs.srsStorage.tls.enabled":                 "false",
+			"srs.srsStorage.basicAuthentication.enabled": "false",
+		},
+		Cases: []helmtest.FailureCase{
+			{
+				Name:          "external Elasticsearch without a domain",
+				SetValues:     map[string]string{"srs.srsStorage.domain": ""},
+				Templates:     []string{srsDeploymentTemplate},
+				ExpectedError: helmtest.FailMessage("A valid '.Values.srsStorage.domain' entry is required when connecting to external Elasticsearch!"),
+			},
+			{
+				Name:          "external Elasticsearch without a port",
+				SetValues:     map[string]string{"srs.srsStorage.port": ""},
+				Templates:     []string{srsDeploymentTemplate},
+				ExpectedError: helmtest.FailMessage("A valid '.Values.srsStorage.port' entry is required when connecting to external Elasticsearch!"),
+			},
+			{
+				Name:          "external Elasticsearch without a protocol",
+				SetValues:     map[string]string{"srs.srsStorage.protocol": ""},
+				Templates:     []string{srsDeploymentTemplate},
+				ExpectedError: helmtest.FailMessage("A valid ''.Values.srsStorage.protocol' entry is required when connecting to external Elasticsearch!"),
+			},
+			{
+				Name: "tls and basic authentication",
+				SetValues: map[string]string{
+					"srs.srsStorage.tls.enabled":                 "true",
+					"srs.srsStorage.basicAuthentication.enabled": "true",
+				},
+				Templates:     []string{srsDeploymentTemplate},
+				ExpectedError: "(?m)" + helmtest.FailMessage("Only one authentication can be enabled, please try to disable .Values.srsStorage.tls.enabled/.Values.srsStorage.basicAuthentication.enabled") + "$",
+			},
+			{
+				Name: "tls and aws IAM",
+				SetValues: map[string]string{
+					"srs.srsStorage.tls.enabled":   "true",
+					"srs.srsStorage.awsIAM.region": "us-east-1",
+				},
+				Templates:     []string{srsDeploymentTemplate},
+				ExpectedError: helmtest.FailMessage("Only one authentication can be enabled, please try to disable .Values.srsStorage.tls.enabled/.Values.srsStorage.awsIAM"),
+			},
+			{
+				Name: "basic authentication and aws IAM",
+				SetValues: map[string]string{
+					"srs.srsStorage.basicAuthentication.enabled": "true",
+					"srs.srsStorage.awsIAM.region":               "us-east-1",
+				},
+				Templates:     []string{srsDeploymentTemplate},
+				ExpectedError: helmtest.FailMessage("Only one authentication can be enabled, please try to disable .Values.srsStorage.basicAuthentication.enabled/.Values.srsStorage.awsIAM"),
+			},
+			{
+				// the tls and basic authentication guard comes first, so the message for all three is never reached
+				Name: "tls, basic authentication and aws IAM",
+				SetValues: map[string]string{
+					"srs.srsStorage.tls.enabled":                 "true",
+					"srs.srsStorage.basicAuthentication.enabled": "true",
+					"srs.srsStorage.awsIAM.region":               "us-east-1",
+				},
+				Templates:     []string{srsDeploymentTemplate},
+				ExpectedError: "(?m)" + helmtest.FailMessage("Only one authentication can be enabled, please try to disable .Values.srsStorage.tls.enabled/.Values.srsStorage.basicAuthentication.enabled") + "$",
+			},
+			{
+				Name:          "authentication without a public key url",
+				SetValues:     map[string]string{"srs.srsRuntime.env.AuthEnabled": "true"},
+				Templates:     []string{srsDeploymentTemplate},
+				ExpectedError: helmtest.FailMessage("A valid entry is required for srsRuntime.env.OAuthPublicKeyURL, when request authentication mechanism(IDP) in place between SRS and Pega Infinity i.e. srsRuntime.env.AuthEnabled is true"),
+			},
+		},
+	}.Run(t)
+}
@@ -0,0 +1,71 @@
+package backingservices
+
+import (
+	"testing"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// renderSRSWithExternalES - the whole backingservices chart with SRS connected to an external Elasticsearch
+func renderSRSWithExternalES(t *testing.T, values map[string]string) *helmtest.HelmChartParser {
+	options := map[string]string{
+		"srs.deploymentName":                         "test-srs",
+		"srs.srsStorage.provisionInternalESCluster":  "false",
+		"srs.srsStorage.domain":                      "es.example.com",
+		"srs.srsStorage.port":                        "9200",
+		"srs.srsStorage.protocol":                    "https",
+		"srs.srsStorage.tls.enabled":                 "false",
+		"srs.srsStorage.basicAuthentication.enabled": "false",
+	}
+	for key, value := range values {
+		options[key] = value
+	}
+	return helmtest.NewHelmConfigParser(helmtest.NewHelmTest(t, helmChartRelativePath, srsHelmRelease, options))
+}
+
+func TestSRSServiceVPA(t *testing.T) {
+	parser := renderSRSWithExternalES(t, map[string]string{
+		"srs.srsRuntime.vpa.enabled":                "true",
+		"srs.srsRuntime.vpa.updateMode":             "Auto",
+		"srs.srsRuntime.vpa.minAllowed.cpu":         "500m",
+		"srs.srsRuntime.vpa.maxAllowed.memory":      "4Gi",
+		"srs.srsRuntime.vpa.controlledResources[0]": "memory",
+	})
+
+	var vpa helmtest.VerticalPodAutoscaler
+	parser.Find(helmtest.SearchResourceOption{Name: "test-srs-vpa", Kind: "VerticalPodAutoscaler"}, &vpa)
+	require.Equal(t, "apps/v1", vpa.Spec.TargetRef.APIVersion)
+	require.Equal(t, "Deployment", vpa.Spec.TargetRef.Kind)
+	require.Equal(t, "Auto", vpa.Spec.UpdatePolicy.UpdateMode)
+
+	var deployment appsv1.Deployment
+	parser.Find(helmtest.SearchResourceOption{Name: vpa.Spec.TargetRef.Name, Kind: "Deployment"}, &deployment)
+	require.Len(t, vpa.Spec.ResourcePolicy.ContainerPolicies, 1)
+	policy := vpa.Spec.ResourcePolicy.ContainerPolicies[0]
+	require.Equal(t, deployment.Spec.Template.Spec.Containers[0].Name, policy.ContainerName)
+	require.Equal(t, []string{"memory"}, policy.ControlledResources)
+	require.True(t, resource.MustParse("500m").Equal(policy.MinAllowed["cpu"]))
+	require.True(t, resource.MustParse("4Gi").Equal(policy.MaxAllowed["memory"]))
+}
+
+func TestSRSServiceVPADefaults(t *testing.T) {
+	parser := renderSRSWithExternalES(t, map[string]string{"srs.srsRuntime.vpa.enabled": "true"})
+
+	var vpa helmtest.VerticalPodAutoscaler
+	parser.Find(helmtest.SearchResourceOption{Name: "test-srs-vpa", Kind: "VerticalPodAutoscaler"}, &vpa)
+	require.Equal(t, "test-srs", vpa.Spec.TargetRef.Name)
+	require.Equal(t, "Off", vpa.Spec.UpdatePolicy.UpdateMode)
+	require.Equal(t, []string{"cpu", "memory"}, vpa.Spec.ResourcePolicy.ContainerPolicies[0].ControlledResources)
+	require.Empty(t, vpa.Spec.ResourcePolicy.ContainerPolicies[0].MinAllowed)
+	require.Empty(t, vpa.Spec.ResourcePolicy.ContainerPolicies[0].MaxAllowed)
+}
+
+func TestSRSServiceVPADisabled(t *testing.T) {
+	parser := renderSRSWithExternalES(t, nil)
+
+	require.True(t, parser.Contains(helmtest.SearchResourceOption{Name: "test-srs", Kind: "Deployment"}))
+	require.False(t, parser.Contains(helmtest.SearchResourceOption{Name: "test-srs-vpa", Kind: "VerticalPodAutoscaler"}))
+}
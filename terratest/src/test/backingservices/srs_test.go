@@ -1,13 +1,15 @@
 package backingservices
 
 import (
-	"github.com/stretchr/testify/require"
 	"testing"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_shouldNotContainSRSResourcesWhenDisabled(t *testing.T) {
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTest(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTest(t, helmChartRelativePath, srsHelmRelease, map[string]string{
 			"srs.enabled": "false",
 			"srs.srsStorage.provisionInternalESCluster": "false",
 			"srs.srsStorage.tls.enabled": "false",
@@ -17,14 +19,14 @@ func Test_shouldNotContainSRSResourcesWhenDisabled(t *testing.T) {
 	)
 
 	for _, i := range srsResources {
-		require.False(t, helmChartParser.Contains(SearchResourceOption{
+		require.False(t, helmChartParser.Contains(helmtest.SearchResourceOption{
 			Name: i.Name,
 			Kind: i.Kind,
 		}))
 	}
 
 	for _, i := range esResources {
-		require.False(t, helmChartParser.Contains(SearchResourceOption{
+		require.False(t, helmChartParser.Contains(helmtest.SearchResourceOption{
 			Name: i.Name,
 			Kind: i.Kind,
 		}))
@@ -32,8 +34,8 @@ func Test_shouldNotContainSRSResourcesWhenDisabled(t *testing.T) {
 }
 
 func Test_shouldContainSRSResourcesWhenEnabled(t *testing.T) {
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTest(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTest(t, helmChartRelativePath, srsHelmRelease, map[string]string{
 			"srs.deploymentName": "test-srs",
 			"srs.srsStorage.provisionInternalESCluster": "true",
 			"srs.srsStorage.tls.enabled": "true",
@@ -42,14 +44,14 @@ func Test_shouldContainSRSResourcesWhenEnabled(t *testing.T) {
 	)
 
 	for _, i := range srsResources {
-		require.True(t, helmChartParser.Contains(SearchResourceOption{
+		require.True(t, helmChartParser.Contains(helmtest.SearchResourceOption{
 			Name: i.Name,
 			Kind: i.Kind,
 		}))
 	}
 
 	for _, i := range esResources {
-		require.True(t, helmChartParser.Contains(SearchResourceOption{
+		require.True(t, helmChartParser.Contains(helmtest.SearchResourceOption{
 			Name: i.Name,
 			Kind: i.Kind,
 		}))
@@ -57,8 +59,8 @@ func Test_shouldContainSRSResourcesWhenEnabled(t *testing.T) {
 }
 
 func Test_shouldContainSRSandESResourcesWhenEnabled(t *testing.T) {
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTest(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTest(t, helmChartRelativePath, srsHelmRelease, map[string]string{
 			"srs.deploymentName": "test-srs",
 			"srs.srsStorage.provisionInternalESCluster": "true",
 			"srs.srsStorage.tls.enabled": "false",
@@ -66,14 +68,14 @@ func Test_shouldContainSRSandESResourcesWhenEnabled(t *testing.T) {
 	)
 
 	for _, i := range srsResources {
-		require.True(t, helmChartParser.Contains(SearchResourceOption{
+		require.True(t, helmChartParser.Contains(helmtest.SearchResourceOption{
 			Name: i.Name,
 			Kind: i.Kind,
 		}))
 	}
 
 	for _, i := range esResources {
-		require.True(t, helmChartParser.Contains(SearchResourceOption{
+		require.True(t, helmChartParser.Contains(helmtest.SearchResourceOption{
 			Name: i.Name,
 			Kind: i.Kind,
 		}))
@@ -81,8 +83,8 @@ func Test_shouldContainSRSandESResourcesWhenEnabled(t *testing.T) {
 }
 
 func Test_shouldContainSRSWhenEnabledandNotESResourcesWhenDisabled(t *testing.T) {
-	helmChartParser := NewHelmConfigParser(
-		NewHelmTest(t, helmChartRelativePath, map[string]string{
+	helmChartParser := helmtest.NewHelmConfigParser(
+		helmtest.NewHelmTest(t, helmChartRelativePath, srsHelmRelease, map[string]string{
 			"srs.deploymentName": "test-srs",
 			"srs.srsStorage.provisionInternalESCluster": "false",
 			"srs.srsStorage.domain": "es.managed.io",
@@ -94,21 +96,21 @@ func Test_shouldContainSRSWhenEnabledandNotESResourcesWhenDisabled(t *testing.T)
 	)
 
 	for _, i := range srsResources {
-		require.True(t, helmChartParser.Contains(SearchResourceOption{
+		require.True(t, helmChartParser.Contains(helmtest.SearchResourceOption{
 			Name: i.Name,
 			Kind: i.Kind,
 		}))
 	}
 
 	for _, i := range esResources {
-		require.False(t, helmChartParser.Contains(SearchResourceOption{
+		require.False(t, helmChartParser.Contains(helmtest.SearchResourceOption{
 			Name: i.Name,
 			Kind: i.Kind,
 		}))
 	}
 }
 
-var srsResources = []SearchResourceOption{
+var srsResources = []helmtest.SearchResourceOption{
 	{
 		Name: "test-srs",
 		Kind: "Deployment",
@@ -131,7 +133,7 @@ var srsResources = []SearchResourceOption{
 	},
 }
 
-var esResources = []SearchResourceOption{
+var esResources = []helmtest.SearchResourceOption{
 	{
 		Name: "elasticsearch-master",
 		Kind: "Service",
@@ -0,0 +1,78 @@
+package helmtest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/gruntwork-io/terratest/modules/k8s"
+	"github.com/gruntwork-io/terratest/modules/logger"
+)
+
+// CommandRender is a `helm template` run of the commands built on this package. The pega chart of this repository
+// and the release name pega are rendered when ChartPath and ReleaseName are empty.
+type CommandRender struct {
+	ChartPath   string
+	ReleaseName string
+	Namespace   string
+	KubeVersion string
+	ValuesFiles []string
+	SetValues   map[string]string
+}
+
+// RenderE renders the chart with RenderCommandE
+func (r CommandRender) RenderE() (string, error) {
+	chartPath := r.ChartPath
+	if chartPath == "" {
+		chartPath = ChartPath(PegaChart)
+	}
+	releaseName := r.ReleaseName
+	if releaseName == "" {
+		releaseName = "pega"
+	}
+	options := &helm.Options{ValuesFiles: r.ValuesFiles, SetValues: r.SetValues}
+	if r.Namespace != "" {
+		options.KubectlOptions = k8s.NewKubectlOptions("", "", r.Namespace)
+	}
+	var extraHelmArgs []string
+	if r.KubeVersion != "" {
+		extraHelmArgs = append(extraHelmArgs, "--kube-version", r.KubeVersion)
+	}
+	return RenderCommandE(chartPath, releaseName, options, extraHelmArgs...)
+}
+
+// RenderCommandE runs `helm template` outside of a test, for the commands built on this package. The helm output is
+// not logged, and a rendering error carries the message helm printed without the exit status and usage hints
+// terratest wraps it in.
+func RenderCommandE(chartPath string, releaseName string, options *helm.Options, extraHelmArgs ...string) (string, error) {
+	quiet := *options
+	quiet.Logger = logger.Discard
+	output, err := helm.RenderTemplateE(&commandT{name: releaseName}, &quiet, chartPath, releaseName, nil, extraHelmArgs...)
+	if err != nil {
+		return "", renderError(err)
+	}
+	return output, nil
+}
+
+func renderError(err error) error {
+	message := err.Error()
+	if index := strings.Index(message, "Error: "); index >= 0 {
+		message = message[index+len("Error: "):]
+	}
+	message = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(message), "Use --debug flag to render out invalid YAML"))
+	return fmt.Errorf("%s", message)
+}
+
+// commandT satisfies the testing.TestingT terratest expects outside of a test. terratest only uses it for logging,
+// which RenderCommandE discards; failures are turned into panics as there is no test to fail.
+type commandT struct {
+	name string
+}
+
+func (c *commandT) Fail()                                     { panic("helm: " + c.name + " failed") }
+func (c *commandT) FailNow()                                  { c.Fail() }
+func (c *commandT) Fatal(args ...interface{})                 { panic(fmt.Sprint(args...)) }
+func (c *commandT) Fatalf(format string, args ...interface{}) { panic(fmt.Sprintf(format, args...)) }
+func (c *commandT) Error(args ...interface{})                 { c.Fatal(args...) }
+func (c *commandT) Errorf(format string, args ...interface{}) { c.Fatalf(format, args...) }
+func (c *commandT) Name() string                              { return c.name }
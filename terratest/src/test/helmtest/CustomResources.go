@@ -0,0 +1,136 @@
+package helmtest
+
+import (
+	autoscaling "k8s.io/api/autoscaling/v2beta2"
+	k8score "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The custom resources rendered by the charts are decoded with QueryAs into the views below. They hold only the
+// fields the tests check, so that the tests do not depend on the Prometheus Operator, KEDA, VPA or Gateway API modules.
+
+// ServiceMonitor is a monitoring.coreos.com/v1 ServiceMonitor
+type ServiceMonitor struct {
+	Metadata metav1.ObjectMeta `json:"metadata"`
+	Spec     struct {
+		Selector struct {
+			MatchLabels map[string]string `json:"matchLabels"`
+		} `json:"selector"`
+		NamespaceSelector struct {
+			MatchNames []string `json:"matchNames"`
+		} `json:"namespaceSelector"`
+		Endpoints []struct {
+			Port              string                   `json:"port"`
+			Path              string                   `json:"path"`
+			Interval          string                   `json:"interval"`
+			ScrapeTimeout     string                   `json:"scrapeTimeout"`
+			Relabelings       []map[string]interface{} `json:"relabelings"`
+			MetricRelabelings []map[string]interface{} `json:"metricRelabelings"`
+		} `json:"endpoints"`
+	} `json:"spec"`
+}
+
+// ScaledObject is a keda.sh/v1alpha1 ScaledObject
+type ScaledObject struct {
+	Metadata metav1.ObjectMeta `json:"metadata"`
+	Spec     struct {
+		ScaleTargetRef  CrossVersionObjectReference `json:"scaleTargetRef"`
+		MinReplicaCount *int32                      `json:"minReplicaCount"`
+		MaxReplicaCount *int32                      `json:"maxReplicaCount"`
+		PollingInterval *int32                      `json:"pollingInterval"`
+		CooldownPeriod  *int32                      `json:"cooldownPeriod"`
+		Fallback        *struct {
+			FailureThreshold int32 `json:"failureThreshold"`
+			Replicas         int32 `json:"replicas"`
+		} `json:"fallback"`
+		Advanced *struct {
+			HorizontalPodAutoscalerConfig struct {
+				Behavior autoscaling.HorizontalPodAutoscalerBehavior `json:"behavior"`
+			} `json:"horizontalPodAutoscalerConfig"`
+		} `json:"advanced"`
+		Triggers []struct {
+			Type              string            `json:"type"`
+			Metadata          map[string]string `json:"metadata"`
+			AuthenticationRef *struct {
+				Name string `json:"name"`
+			} `json:"authenticationRef"`
+		} `json:"triggers"`
+	} `json:"spec"`
+}
+
+// VerticalPodAutoscaler is an autoscaling.k8s.io/v1 VerticalPodAutoscaler
+type VerticalPodAutoscaler struct {
+	Metadata metav1.ObjectMeta `json:"metadata"`
+	Spec     struct {
+		TargetRef    CrossVersionObjectReference `json:"targetRef"`
+		UpdatePolicy struct {
+			UpdateMode string `json:"updateMode"`
+		} `json:"updatePolicy"`
+		ResourcePolicy struct {
+			ContainerPolicies []struct {
+				ContainerName       string               `json:"containerName"`
+				Mode                string               `json:"mode"`
+				ControlledResources []string             `json:"controlledResources"`
+				MinAllowed          k8score.ResourceList `json:"minAllowed"`
+				MaxAllowed          k8score.ResourceList `json:"maxAllowed"`
+			} `json:"containerPolicies"`
+		} `json:"resourcePolicy"`
+	} `json:"spec"`
+}
+
+// CrossVersionObjectReference is the workload a ScaledObject or VerticalPodAutoscaler scales
+type CrossVersionObjectReference struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+}
+
+// HTTPRoute is a gateway.networking.k8s.io/v1 HTTPRoute
+type HTTPRoute struct {
+	Metadata metav1.ObjectMeta `json:"metadata"`
+	Spec     struct {
+		ParentRefs []struct {
+			Group       string `json:"group"`
+			Kind        string `json:"kind"`
+			Name        string `json:"name"`
+			Namespace   string `json:"namespace"`
+			SectionName string `json:"sectionName"`
+		} `json:"parentRefs"`
+		Hostnames []string        `json:"hostnames"`
+		Rules     []HTTPRouteRule `json:"rules"`
+	} `json:"spec"`
+}
+
+// HTTPRouteRule is a rule of an HTTPRoute
+type HTTPRouteRule struct {
+	Matches []struct {
+		Path struct {
+			Type  string `json:"type"`
+			Value string `json:"value"`
+		} `json:"path"`
+	} `json:"matches"`
+	Filters []struct {
+		Type            string `json:"type"`
+		RequestRedirect struct {
+			Scheme string `json:"scheme"`
+			Path   struct {
+				Type            string `json:"type"`
+				ReplaceFullPath string `json:"replaceFullPath"`
+			} `json:"path"`
+			StatusCode int `json:"statusCode"`
+		} `json:"requestRedirect"`
+	} `json:"filters"`
+	BackendRefs []struct {
+		Name string `json:"name"`
+		Port int32  `json:"port"`
+	} `json:"backendRefs"`
+	Timeouts struct {
+		Request string `json:"request"`
+	} `json:"timeouts"`
+	SessionPersistence *struct {
+		Type            string `json:"type"`
+		SessionName     string `json:"sessionName"`
+		AbsoluteTimeout string `json:"absoluteTimeout"`
+		IdleTimeout     string `json:"idleTimeout"`
+	} `json:"sessionPersistence"`
+}
@@ -0,0 +1,280 @@
+package helmtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/stretchr/testify/require"
+	yamlv2 "gopkg.in/yaml.v2"
+	"sigs.k8s.io/yaml"
+)
+
+// AssertDeterministicRendering renders the scenario renders times, each time in a new helm process and with the keys of
+// the chart's values.yaml and of the scenario values files in a new random order, shuffled from seed or from a random
+// seed when it is 0. Every render must be byte-identical to the first, apart from the fields in DefaultSnapshotMasks.
+// A chart rendering differently from one run to the next would change the checksum annotations, and so roll every
+// pod, on each upgrade.
+func AssertDeterministicRendering(t *testing.T, scenario Scenario, renders int, seed int64) {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	random := rand.New(rand.NewSource(seed))
+	dir := t.TempDir()
+	valuesFiles := append([]string{filepath.Join(ChartPath(scenario.Chart), "values.yaml")}, scenario.ValuesFiles...)
+
+	var first string
+	for render := 1; render <= renders; render++ {
+		shuffled := scenario
+		shuffled.ValuesFiles = nil
+		for i, valuesFile := range valuesFiles {
+			target := filepath.Join(dir, fmt.Sprintf("render%d-%d-%s", render, i, filepath.Base(valuesFile)))
+			require.NoError(t, shuffleValuesFile(valuesFile, target, random))
+			shuffled.ValuesFiles = append(shuffled.ValuesFiles, target)
+		}
+		helmTest := shuffled.HelmTest(t)
+		helmTest.HelmOptions.Logger = logger.Discard
+		output := helmTest.Render()
+		if render == 1 {
+			first = output
+			continue
+		}
+		differences, err := RenderDifferences(first, output, scenario.Namespace, DefaultSnapshotMasks)
+		require.NoError(t, err)
+		if len(differences) > 0 {
+			t.Errorf("render %d of %s differs from the first one, rerun with -render-seed %d to reproduce:\n  %s",
+				render, scenario.ScenarioName(), seed, strings.Join(differences, "\n  "))
+		}
+	}
+}
+
+// shuffleValuesFile writes the values file at path to target with the keys of every mapping in a random order
+func shuffleValuesFile(path string, target string, random *rand.Rand) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var values yamlv2.MapSlice
+	if err := yamlv2.Unmarshal(content, &values); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	shuffled, err := yamlv2.Marshal(shuffleKeys(values, random))
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(target, shuffled, 0644)
+}
+
+func shuffleKeys(value interface{}, random *rand.Rand) interface{} {
+	switch value := value.(type) {
+	case yamlv2.MapSlice:
+		shuffled := make(yamlv2.MapSlice, len(value))
+		for i, j := range random.Perm(len(value)) {
+			shuffled[i] = yamlv2.MapItem{Key: value[j].Key, Value: shuffleKeys(value[j].Value, random)}
+		}
+		return shuffled
+	case []interface{}:
+		items := make([]interface{}, len(value))
+		for i, item := range value {
+			items[i] = shuffleKeys(item, random)
+		}
+		return items
+	default:
+		return value
+	}
+}
+
+// RenderDifferences compares two renders of a chart and returns one line per difference, naming the resource and the
+// field: a resource rendered only once, a field with another value, resources rendered in another order or, when the
+// objects are equal but their text is not, the first line that differs. Fields matched by masks are ignored.
+func RenderDifferences(expected string, actual string, namespace string, masks []SnapshotMask) ([]string, error) {
+	if expected == actual {
+		return nil, nil
+	}
+	expectedResources, err := renderedResources(expected, namespace, masks)
+	if err != nil {
+		return nil, err
+	}
+	actualResources, err := renderedResources(actual, namespace, masks)
+	if err != nil {
+		return nil, err
+	}
+
+	var differences []string
+	actualByName := map[string]renderedResource{}
+	for _, resource := range actualResources {
+		actualByName[resource.name] = resource
+	}
+	expectedByName := map[string]bool{}
+	for _, resource := range expectedResources {
+		expectedByName[resource.name] = true
+		other, found := actualByName[resource.name]
+		if !found {
+			differences = append(differences, resource.name+": not rendered")
+			continue
+		}
+		fieldDifferences := diffFields("", resource.object, other.object)
+		for _, difference := range fieldDifferences {
+			differences = append(differences, resource.name+": "+difference)
+		}
+		if len(fieldDifferences) == 0 && !resource.masked && resource.text != other.text {
+			differences = append(differences, resource.name+": "+firstDifferentLine(resource.text, other.text))
+		}
+	}
+	for _, resource := range actualResources {
+		if !expectedByName[resource.name] {
+			differences = append(differences, resource.name+": rendered only this time")
+		}
+	}
+	if len(differences) == 0 && len(expectedResources) == len(actualResources) {
+		for i := range expectedResources {
+			if expectedResources[i].name != actualResources[i].name {
+				differences = append(differences, fmt.Sprintf("resources are rendered in another order: %s comes where %s did",
+					actualResources[i].name, expectedResources[i].name))
+				break
+			}
+		}
+	}
+	// a masked field changes the text of the output, so it can only be compared when nothing is masked
+	if len(differences) == 0 && !anyMasked(expectedResources) {
+		differences = append(differences, "the output differs outside of the resources: "+firstDifferentLine(expected, actual))
+	}
+	return differences, nil
+}
+
+// renderedResource is a rendered object decoded for comparison
+type renderedResource struct {
+	// name is Kind/name, followed by the namespace when it is not the one of the release
+	name   string
+	object interface{}
+	text   string
+	masked bool
+}
+
+func renderedResources(rendered string, namespace string, masks []SnapshotMask) ([]renderedResource, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+	parser, err := NewHelmChartParserE(nil, rendered, namespace)
+	if err != nil {
+		return nil, err
+	}
+	var resources []renderedResource
+	for _, resource := range parser.resources {
+		var object map[string]interface{}
+		if err := yaml.Unmarshal([]byte(resource.YAML), &object); err != nil {
+			return nil, fmt.Errorf("decoding %s %s: %w", resource.Kind, resource.Name, err)
+		}
+		masked := false
+		for _, mask := range masks {
+			if mask.Kind == resource.Kind && mask.Name == resource.Name {
+				maskField(object, strings.Split(mask.Field, "."))
+				masked = true
+			}
+		}
+		name := resource.Kind + "/" + resource.Name
+		if resource.Namespace != namespace && resource.Namespace != "" {
+			name += " in namespace " + resource.Namespace
+		}
+		// the document separator is kept with the first document only
+		text := strings.TrimPrefix(strings.TrimSpace(resource.YAML), "---\n")
+		resources = append(resources, renderedResource{name: name, object: object, text: text, masked: masked})
+	}
+	return resources, nil
+}
+
+func anyMasked(resources []renderedResource) bool {
+	for _, resource := range resources {
+		if resource.masked {
+			return true
+		}
+	}
+	return false
+}
+
+// diffFields lists the paths, e.g. spec.template.metadata.annotations.config-check, where expected and actual differ
+func diffFields(path string, expected interface{}, actual interface{}) []string {
+	switch expectedValue := expected.(type) {
+	case map[string]interface{}:
+		actualValue, ok := actual.(map[string]interface{})
+		if !ok {
+			break
+		}
+		var differences []string
+		keys := make([]string, 0, len(expectedValue)+len(actualValue))
+		for key := range expectedValue {
+			keys = append(keys, key)
+		}
+		for key := range actualValue {
+			if _, found := expectedValue[key]; !found {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			differences = append(differences, diffFields(joinFieldPath(path, key), expectedValue[key], actualValue[key])...)
+		}
+		return differences
+	case []interface{}:
+		actualValue, ok := actual.([]interface{})
+		if !ok || len(actualValue) != len(expectedValue) {
+			break
+		}
+		var differences []string
+		for i := range expectedValue {
+			differences = append(differences, diffFields(fmt.Sprintf("%s[%d]", path, i), expectedValue[i], actualValue[i])...)
+		}
+		return differences
+	}
+	if reflect.DeepEqual(expected, actual) {
+		return nil
+	}
+	return []string{fmt.Sprintf("%s: %s, then %s", path, shortJSON(expected), shortJSON(actual))}
+}
+
+func joinFieldPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// shortJSON formats a field value for a difference, cut to a length that keeps the report readable
+func shortJSON(value interface{}) string {
+	if value == nil {
+		return "<absent>"
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	if len(encoded) > 80 {
+		return string(encoded[:77]) + "..."
+	}
+	return string(encoded)
+}
+
+func firstDifferentLine(expected string, actual string) string {
+	expectedLines, actualLines := strings.Split(expected, "\n"), strings.Split(actual, "\n")
+	for i := 0; i < len(expectedLines) || i < len(actualLines); i++ {
+		var expectedLine, actualLine string
+		if i < len(expectedLines) {
+			expectedLine = expectedLines[i]
+		}
+		if i < len(actualLines) {
+			actualLine = actualLines[i]
+		}
+		if expectedLine != actualLine {
+			return fmt.Sprintf("line %d is %q, then %q", i+1, expectedLine, actualLine)
+		}
+	}
+	return "the text differs"
+}
@@ -0,0 +1,126 @@
+package helmtest
+
+import (
+	"io/ioutil"
+	"math/rand"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+const determinismValues = `global:
+  provider: k8s
+  tier:
+    - name: web
+      replicas: 1
+      custom:
+        env:
+          LOG_LEVEL: debug
+          FEATURE: "true"
+    - name: batch
+      replicas: 2
+installer:
+  adminPassword: install
+  upgrade:
+    upgradeType: zero-downtime
+`
+
+func TestShuffleValuesFileKeepsValues(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "values.yaml")
+	require.NoError(t, ioutil.WriteFile(source, []byte(determinismValues), 0644))
+	var expected interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(determinismValues), &expected))
+
+	orders := map[string]bool{}
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 10; i++ {
+		target := filepath.Join(dir, "shuffled.yaml")
+		require.NoError(t, shuffleValuesFile(source, target, random))
+		shuffled, err := ioutil.ReadFile(target)
+		require.NoError(t, err)
+		var actual interface{}
+		require.NoError(t, yaml.Unmarshal(shuffled, &actual))
+		require.Equal(t, expected, actual)
+		orders[string(shuffled)] = true
+	}
+	require.Greater(t, len(orders), 1, "the keys were never shuffled")
+}
+
+const determinismRender = `---
+# Source: pega/templates/pega-tier-config.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: pega-web
+data:
+  level: debug
+  mode: web
+---
+# Source: pega/templates/pega-tier-deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: pega-web
+spec:
+  template:
+    metadata:
+      annotations:
+        config-check: aaa
+    spec:
+      containers:
+      - name: pega-web-tomcat
+        image: pega:1
+---
+# Source: backingservices/templates/srs-secret.yaml
+apiVersion: v1
+kind: Secret
+metadata:
+  name: srs-elastic-credentials
+data:
+  password: c2VjcmV0
+`
+
+func TestRenderDifferences(t *testing.T) {
+	differences, err := RenderDifferences(determinismRender, determinismRender, "", DefaultSnapshotMasks)
+	require.NoError(t, err)
+	require.Empty(t, differences)
+
+	generatedPassword := strings.Replace(determinismRender, "c2VjcmV0", "b3RoZXI=", 1)
+	differences, err = RenderDifferences(determinismRender, generatedPassword, "", DefaultSnapshotMasks)
+	require.NoError(t, err)
+	require.Empty(t, differences)
+
+	changed := strings.Replace(determinismRender, "config-check: aaa", "config-check: bbb", 1)
+	changed = strings.Replace(changed, "image: pega:1", "image: pega:2", 1)
+	differences, err = RenderDifferences(determinismRender, changed, "", DefaultSnapshotMasks)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		`Deployment/pega-web: spec.template.metadata.annotations.config-check: "aaa", then "bbb"`,
+		`Deployment/pega-web: spec.template.spec.containers[0].image: "pega:1", then "pega:2"`,
+	}, differences)
+}
+
+func TestRenderDifferencesInTextAndOrder(t *testing.T) {
+	reordered := strings.Replace(determinismRender, "  level: debug\n  mode: web", "  mode: web\n  level: debug", 1)
+	differences, err := RenderDifferences(determinismRender, reordered, "", nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{`ConfigMap/pega-web: line 7 is "  level: debug", then "  mode: web"`}, differences)
+
+	documents := strings.Split(determinismRender, "---\n")
+	swapped := "---\n" + documents[2] + "---\n" + documents[1] + "---\n" + documents[3]
+	differences, err = RenderDifferences(determinismRender, swapped, "", nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"resources are rendered in another order: Deployment/pega-web comes where ConfigMap/pega-web did"}, differences)
+
+	withoutSecret := strings.Join(documents[:3], "---\n")
+	differences, err = RenderDifferences(determinismRender, withoutSecret, "", nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Secret/srs-elastic-credentials: not rendered"}, differences)
+	differences, err = RenderDifferences(withoutSecret, determinismRender, "", nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Secret/srs-elastic-credentials: rendered only this time"}, differences)
+}
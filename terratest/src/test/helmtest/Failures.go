@@ -0,0 +1,77 @@
+package helmtest
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/stretchr/testify/require"
+)
+
+// FailureCase is a values overlay that a chart must refuse to render
+type FailureCase struct {
+	Name string
+	// ValuesFiles and SetValues are applied on top of the base values of the suite
+	ValuesFiles []string
+	SetValues   map[string]string
+	// Templates restricts rendering as in HelmTest.Templates; the whole chart is rendered when empty
+	Templates     []string
+	ExtraHelmArgs []string
+	// ExpectedError is a regular expression matched against the helm error, see FailMessage and SchemaError
+	ExpectedError string
+}
+
+// FailureSuite renders a chart once per FailureCase and checks that every render fails with the expected error
+type FailureSuite struct {
+	Chart       string
+	ReleaseName string
+	// BaseValues are set for every case, typically the provider and action needed to reach the guard
+	BaseValues map[string]string
+	Cases      []FailureCase
+}
+
+// Run executes every case as a parallel subtest named after the case
+func (s FailureSuite) Run(t *testing.T) {
+	for _, failureCase := range s.Cases {
+		failureCase := failureCase
+		t.Run(failureCase.Name, func(t *testing.T) {
+			t.Parallel()
+			setValues := map[string]string{}
+			for key, value := range s.BaseValues {
+				setValues[key] = value
+			}
+			for key, value := range failureCase.SetValues {
+				setValues[key] = value
+			}
+			AssertRenderFails(&HelmTest{
+				T:             t,
+				ChartPath:     ChartPath(s.Chart),
+				ReleaseName:   s.ReleaseName,
+				HelmOptions:   &helm.Options{ValuesFiles: failureCase.ValuesFiles, SetValues: setValues},
+				Templates:     failureCase.Templates,
+				ExtraHelmArgs: failureCase.ExtraHelmArgs,
+			}, failureCase.ExpectedError)
+		})
+	}
+}
+
+// AssertRenderFails renders helmTest and requires the error to match expectedError
+func AssertRenderFails(helmTest *HelmTest, expectedError string) {
+	_, err := helmTest.RenderE()
+	require.Error(helmTest.T, err, "rendering was expected to fail with %s", expectedError)
+	require.Regexp(helmTest.T, regexp.MustCompile(expectedError), err.Error())
+}
+
+// FailMessage matches the message of a `fail` or `required` call in a template
+func FailMessage(message string) string {
+	return regexp.QuoteMeta(message)
+}
+
+// SchemaError matches helm rejecting a value because of the values.schema.json rule on field, given in dotted form
+// such as global.tier.0.name. Helm reports the field as global.tier.0.name or, from v3.18, as the JSON pointer
+// '/global/tier/0/name'.
+func SchemaError(field string) string {
+	pointer := "'/" + strings.ReplaceAll(field, ".", "/") + "'"
+	return `(?s)values don't meet the specifications of the schema.*(` + regexp.QuoteMeta(field+":") + `|` + regexp.QuoteMeta(pointer) + `)`
+}
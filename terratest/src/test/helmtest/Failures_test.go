@@ -0,0 +1,21 @@
+package helmtest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailMessage(t *testing.T) {
+	pattern := FailMessage("tier[pega-web] may not specify a deploymentStrategy (StatefulSet)")
+	require.Regexp(t, pattern, "Error: execution error at (pega/templates/_pega-deployment.tpl:42:5): tier[pega-web] may not specify a deploymentStrategy (StatefulSet)")
+	require.NotRegexp(t, pattern, "tierp may not specify a deploymentStrategy StatefulSet")
+}
+
+func TestSchemaError(t *testing.T) {
+	pattern := SchemaError("global.tier.0.name")
+	require.Regexp(t, pattern, "values don't meet the specifications of the schema(s) in the following chart(s):\npega:\n- global.tier.0.name: Does not match pattern")
+	require.Regexp(t, pattern, "values don't meet the specifications of the schema(s) in the following chart(s):\npega:\n- at '/global/tier/0/name': does not match pattern")
+	require.NotRegexp(t, pattern, "values don't meet the specifications of the schema(s) in the following chart(s):\npega:\n- global.tier.0.nodeType: Invalid type")
+	require.NotRegexp(t, pattern, "execution error: global.tier.0.name: invalid")
+}
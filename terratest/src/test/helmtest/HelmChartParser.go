@@ -0,0 +1,117 @@
+package helmtest
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// HelmChartParser holds the output of a rendered chart, decoded into one Resource per YAML document and indexed by
+// kind, name and namespace.
+type HelmChartParser struct {
+	T              *testing.T
+	SlicedResource []string
+	resources      []Resource
+	index          map[SearchResourceOption]int
+}
+
+func NewHelmConfigParser(helmTest *HelmTest) *HelmChartParser {
+	return NewHelmChartParser(helmTest.T, helmTest.Render(), helmTest.Namespace)
+}
+
+// NewHelmChartParser decodes already rendered output and fails the test if it is not a valid YAML stream. Resources
+// without an explicit namespace are indexed under defaultNamespace, which is the namespace the release is rendered
+// into.
+func NewHelmChartParser(t *testing.T, renderedChart string, defaultNamespace string) *HelmChartParser {
+	parser, err := NewHelmChartParserE(t, renderedChart, defaultNamespace)
+	require.NoError(t, err)
+	return parser
+}
+
+// NewHelmChartParserE decodes already rendered output, see NewHelmChartParser
+func NewHelmChartParserE(t *testing.T, renderedChart string, defaultNamespace string) (*HelmChartParser, error) {
+	parser := &HelmChartParser{T: t, index: map[SearchResourceOption]int{}}
+	reader := yaml.NewYAMLReader(bufio.NewReader(strings.NewReader(renderedChart)))
+	for {
+		document, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		resource, err := newResource(document, defaultNamespace)
+		if err != nil {
+			return nil, fmt.Errorf("decoding rendered document %d: %w", len(parser.SlicedResource), err)
+		}
+		parser.SlicedResource = append(parser.SlicedResource, string(document))
+		if resource == nil {
+			continue
+		}
+		key := resource.key()
+		if _, exists := parser.index[key]; !exists {
+			parser.index[key] = len(parser.resources)
+		}
+		parser.resources = append(parser.resources, *resource)
+	}
+	return parser, nil
+}
+
+// Resources lists the kind, name and namespace of every rendered resource, in rendering order. A resource rendered
+// twice is listed once, as Find only returns its first occurrence.
+func (p *HelmChartParser) Resources() []SearchResourceOption {
+	var keys []SearchResourceOption
+	for i, resource := range p.resources {
+		if key := resource.key(); p.index[key] == i {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func (p *HelmChartParser) lookup(searchOptions SearchResourceOption) (Resource, bool) {
+	if searchOptions.Kind == "" || searchOptions.Name == "" {
+		return Resource{}, false
+	}
+	if searchOptions.Namespace != "" {
+		i, ok := p.index[searchOptions]
+		if !ok {
+			return Resource{}, false
+		}
+		return p.resources[i], true
+	}
+	for _, resource := range p.resources {
+		if resource.Kind == searchOptions.Kind && resource.Name == searchOptions.Name {
+			return resource, true
+		}
+	}
+	return Resource{}, false
+}
+
+// Find unmarshals the resource identified by searchOptions into resource and fails the test if it was not rendered
+func (p *HelmChartParser) Find(searchOptions SearchResourceOption, resource interface{}) {
+	if err := p.FindE(searchOptions, resource); err != nil {
+		p.T.Log(err)
+		p.T.FailNow()
+	}
+}
+
+// FindE unmarshals the resource identified by searchOptions into resource, returning an error if it was not rendered
+func (p *HelmChartParser) FindE(searchOptions SearchResourceOption, resource interface{}) error {
+	found, ok := p.lookup(searchOptions)
+	if !ok {
+		return fmt.Errorf("resource not found: %+v", searchOptions)
+	}
+	return helm.UnmarshalK8SYamlE(p.T, found.YAML, resource)
+}
+
+func (p *HelmChartParser) Contains(searchOptions SearchResourceOption) bool {
+	_, ok := p.lookup(searchOptions)
+	return ok
+}
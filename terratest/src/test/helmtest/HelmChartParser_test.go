@@ -0,0 +1,141 @@
+package helmtest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	k8score "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const renderedServices = `---
+# Source: pega/templates/pega-tier-service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: pega-web
+spec:
+  type: ClusterIP
+---
+# Source: pega/templates/pega-tier-service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: pega-web
+  namespace: other
+spec:
+  type: NodePort
+`
+
+func TestHelmChartParserIndexesByNamespace(t *testing.T) {
+	parser := NewHelmChartParser(t, renderedServices, "pega")
+
+	require.Equal(t, []SearchResourceOption{
+		{Name: "pega-web", Kind: "Service", Namespace: "pega"},
+		{Name: "pega-web", Kind: "Service", Namespace: "other"},
+	}, parser.Resources())
+
+	var service k8score.Service
+	parser.Find(SearchResourceOption{Name: "pega-web", Kind: "Service", Namespace: "other"}, &service)
+	require.Equal(t, k8score.ServiceTypeNodePort, service.Spec.Type)
+
+	parser.Find(SearchResourceOption{Name: "pega-web", Kind: "Service"}, &service)
+	require.Equal(t, k8score.ServiceTypeClusterIP, service.Spec.Type)
+
+	require.False(t, parser.Contains(SearchResourceOption{Name: "pega-web", Kind: "Service", Namespace: "default"}))
+}
+
+const renderedTiers = `---
+# Source: pega/templates/pega-environment-config.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: pega
+data:
+  prconfig.xml: |-
+    <?xml version="1.0" encoding="UTF-8"?>
+    ---
+    <!-- a separator inside a block scalar is not a document boundary -->
+---
+# Source: pega/templates/pega-tier-deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: pega-web
+  labels:
+    app: pega-web
+spec:
+  replicas: 1
+---
+# Source: pega/templates/pega-tier-deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: pega-batch
+  labels:
+    app: pega-batch
+---
+# Source: pega/templates/pega-tier-hpa.yaml
+apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: pega-web-hpa
+---
+# Source: pega/templates/pega-tier-ingress.yaml
+# a template that rendered nothing
+`
+
+func TestHelmChartParserListsDuplicatesOnce(t *testing.T) {
+	parser := NewHelmChartParser(t, renderedServices+renderedServices, "pega")
+
+	require.Equal(t, []SearchResourceOption{
+		{Name: "pega-web", Kind: "Service", Namespace: "pega"},
+		{Name: "pega-web", Kind: "Service", Namespace: "other"},
+	}, parser.Resources())
+	require.Len(t, parser.Query(ResourceQuery{Kind: "Service"}), 4)
+}
+
+func TestHelmChartParserKeepsEmbeddedSeparators(t *testing.T) {
+	parser := NewHelmChartParser(t, renderedTiers, "default")
+
+	var configMap k8score.ConfigMap
+	parser.Find(SearchResourceOption{Name: "pega", Kind: "ConfigMap"}, &configMap)
+	require.Contains(t, configMap.Data["prconfig.xml"], "a separator inside a block scalar")
+	require.Len(t, parser.Resources(), 4)
+}
+
+func TestHelmChartParserQuery(t *testing.T) {
+	parser := NewHelmChartParser(t, renderedTiers, "default")
+
+	deployments := parser.Query(ResourceQuery{Kind: "Deployment", Name: "pega-*"})
+	require.Len(t, deployments, 2)
+	require.IsType(t, &appsv1.Deployment{}, deployments[0].Object)
+	require.Equal(t, "pega/templates/pega-tier-deployment.yaml", deployments[0].Source)
+
+	web := parser.QueryOne(ResourceQuery{LabelSelector: "app=pega-web"})
+	require.Equal(t, "pega-web", web.Name)
+
+	hpa := parser.QueryOne(ResourceQuery{Source: "templates/pega-tier-hpa.yaml"})
+	require.Equal(t, "autoscaling/v2", hpa.APIVersion)
+	require.IsType(t, &unstructured.Unstructured{}, hpa.Object)
+
+	require.Empty(t, parser.Query(ResourceQuery{Kind: "Deployment", APIVersion: "apps/v1beta1"}))
+
+	typed := QueryAs[appsv1.Deployment](parser, ResourceQuery{Kind: "Deployment", LabelSelector: "app in (pega-batch)"})
+	require.Len(t, typed, 1)
+	require.Equal(t, "pega-batch", typed[0].Name)
+}
+
+func TestHelmChartParserErrors(t *testing.T) {
+	parser := NewHelmChartParser(t, renderedTiers, "default")
+
+	_, err := parser.QueryE(ResourceQuery{LabelSelector: "app in (web"})
+	require.Error(t, err)
+
+	_, err = parser.QueryOneE(ResourceQuery{Kind: "Deployment"})
+	require.EqualError(t, err, "expected exactly one resource matching {Kind:Deployment APIVersion: Name: Namespace: LabelSelector: Source:}, found 2")
+
+	var deployment appsv1.Deployment
+	require.Error(t, parser.FindE(SearchResourceOption{Name: "pega-stream", Kind: "Deployment"}, &deployment))
+}
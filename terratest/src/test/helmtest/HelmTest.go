@@ -0,0 +1,95 @@
+package helmtest
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/gruntwork-io/terratest/modules/k8s"
+	"github.com/stretchr/testify/require"
+)
+
+// HelmTest describes a single `helm template` invocation: the chart, the release it is rendered as and the values it
+// is rendered with.
+type HelmTest struct {
+	T           *testing.T
+	ChartPath   string
+	ReleaseName string
+	Namespace   string
+	KubeVersion string
+	HelmOptions *helm.Options
+	Templates   []string
+	// ExtraHelmArgs are passed to `helm template` verbatim, after the arguments derived from the fields above
+	ExtraHelmArgs []string
+}
+
+// NewHelmTest marks t as parallel. Subtests started by Matrix.Run are already parallel and should build a HelmTest
+// literal instead.
+func NewHelmTest(t *testing.T, chartRelativePath string, releaseName string, options map[string]string) *HelmTest {
+	t.Parallel()
+
+	path, err := filepath.Abs(chartRelativePath)
+	require.NoError(t, err)
+
+	return &HelmTest{
+		T:           t,
+		ChartPath:   path,
+		ReleaseName: releaseName,
+		HelmOptions: &helm.Options{SetValues: options},
+	}
+}
+
+func NewHelmTestFromTemplate(t *testing.T, chartRelativePath string, releaseName string, options map[string]string, templateFiles []string) *HelmTest {
+	return NewHelmTest(t, chartRelativePath, releaseName, options).WithTemplates(templateFiles...)
+}
+
+// WithTemplates restricts rendering to the given templates, relative to the chart directory
+func (h *HelmTest) WithTemplates(templateFiles ...string) *HelmTest {
+	h.Templates = templateFiles
+	return h
+}
+
+// WithValuesFiles adds values files, applied in order before any --set values
+func (h *HelmTest) WithValuesFiles(valuesFiles ...string) *HelmTest {
+	h.HelmOptions.ValuesFiles = append(h.HelmOptions.ValuesFiles, valuesFiles...)
+	return h
+}
+
+// WithNamespace renders the release into the given namespace
+func (h *HelmTest) WithNamespace(namespace string) *HelmTest {
+	h.Namespace = namespace
+	return h
+}
+
+// WithKubeVersion sets the Kubernetes version reported to the templates through .Capabilities.KubeVersion
+func (h *HelmTest) WithKubeVersion(kubeVersion string) *HelmTest {
+	h.KubeVersion = kubeVersion
+	return h
+}
+
+// WithExtraHelmArgs appends raw arguments to the `helm template` command line
+func (h *HelmTest) WithExtraHelmArgs(args ...string) *HelmTest {
+	h.ExtraHelmArgs = append(h.ExtraHelmArgs, args...)
+	return h
+}
+
+// Render runs `helm template` and fails the test if rendering fails
+func (h *HelmTest) Render() string {
+	out, err := h.RenderE()
+	require.NoError(h.T, err)
+	return out
+}
+
+// RenderE runs `helm template` and returns the output together with any rendering error
+func (h *HelmTest) RenderE() (string, error) {
+	options := *h.HelmOptions
+	if h.Namespace != "" {
+		options.KubectlOptions = k8s.NewKubectlOptions("", "", h.Namespace)
+	}
+	var extraHelmArgs []string
+	if h.KubeVersion != "" {
+		extraHelmArgs = append(extraHelmArgs, "--kube-version", h.KubeVersion)
+	}
+	extraHelmArgs = append(extraHelmArgs, h.ExtraHelmArgs...)
+	return helm.RenderTemplateE(h.T, &options, h.ChartPath, h.ReleaseName, h.Templates, extraHelmArgs...)
+}
@@ -0,0 +1,31 @@
+package helmtest
+
+import (
+	"strconv"
+	"strings"
+)
+
+// SupportedKubeVersions are the Kubernetes versions the charts are rendered against. They bracket every
+// .Capabilities.KubeVersion branch in the templates: the beta GKE backend-config annotation (< 1.16), startup probes
+// (>= 1.18), networking.k8s.io/v1 ingresses and ManagedCertificates (>= 1.19), policy/v1 PDBs (>= 1.21) and
+// autoscaling/v2 HPAs (>= 1.23).
+var SupportedKubeVersions = []string{"1.15.0", "1.17.0", "1.18.0", "1.19.0", "1.21.0", "1.23.0", "1.25.0", "1.27.0", "1.29.0"}
+
+// ValidatedKubeVersions are the supported versions from 1.19, the oldest version with an OpenAPI schema bundled in
+// the validation package
+var ValidatedKubeVersions = []string{"1.19.0", "1.21.0", "1.23.0", "1.25.0", "1.27.0", "1.29.0"}
+
+// RepresentativeKubeVersions are the oldest and newest validated versions and 1.21, where PDBs move to policy/v1, for
+// matrices too large to render against every validated version
+var RepresentativeKubeVersions = []string{"1.19.0", "1.21.0", "1.29.0"}
+
+// KubeVersionAtLeast reports whether kubeVersion (e.g. "1.23.0" or "v1.23.4-gke.100") is at or above major.minor
+func KubeVersionAtLeast(kubeVersion string, major int, minor int) bool {
+	parts := strings.SplitN(strings.TrimPrefix(kubeVersion, "v"), ".", 3)
+	if len(parts) < 2 {
+		return false
+	}
+	actualMajor, _ := strconv.Atoi(parts[0])
+	actualMinor, _ := strconv.Atoi(strings.SplitN(parts[1], "-", 2)[0])
+	return actualMajor > major || (actualMajor == major && actualMinor >= minor)
+}
@@ -0,0 +1,15 @@
+package helmtest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKubeVersionAtLeast(t *testing.T) {
+	require.True(t, KubeVersionAtLeast("1.23.0", 1, 23))
+	require.True(t, KubeVersionAtLeast("v1.25.4-gke.100", 1, 23))
+	require.True(t, KubeVersionAtLeast("2.0.0", 1, 23))
+	require.False(t, KubeVersionAtLeast("1.15.0", 1, 16))
+	require.False(t, KubeVersionAtLeast("latest", 1, 16))
+}
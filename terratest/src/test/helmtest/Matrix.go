@@ -0,0 +1,149 @@
+package helmtest
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/helm"
+)
+
+// Providers and actions accepted by global.provider and global.actions.execute in the pega chart
+var (
+	SupportedProviders     = []string{"k8s", "openshift", "eks", "gke", "aks", "pks"}
+	SupportedDeployActions = []string{"deploy", "install-deploy", "upgrade-deploy"}
+	SupportedActions       = []string{"install", "upgrade", "deploy", "install-deploy", "upgrade-deploy"}
+)
+
+// Matrix declares the dimensions a test runs over. Run executes the test once for every combination in the cartesian
+// product of the non-empty dimensions; empty dimensions are left out of the product and of the subtest names.
+type Matrix struct {
+	Providers       []string
+	Actions         []string
+	DeploymentNames []string
+	ValuesFiles     []string
+	KubeVersions    []string
+	// Exclude skips combinations matching any of these patterns. Empty fields match any value, so
+	// Combination{Action: "upgrade-deploy"} excludes upgrade-deploy for every provider.
+	Exclude []Combination
+}
+
+// Combination is a single point in a Matrix
+type Combination struct {
+	Provider       string
+	Action         string
+	DeploymentName string
+	ValuesFile     string
+	KubeVersion    string
+}
+
+// Name identifies the combination in subtest names, e.g. eks/upgrade-deploy/myapp-dev
+func (c Combination) Name() string {
+	var parts []string
+	for _, part := range []string{c.Provider, c.Action, c.DeploymentName, strings.TrimSuffix(filepath.Base(c.ValuesFile), filepath.Ext(c.ValuesFile)), c.KubeVersion} {
+		if part != "" && part != "." {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+func (c Combination) matches(pattern Combination) bool {
+	return (pattern.Provider == "" || pattern.Provider == c.Provider) &&
+		(pattern.Action == "" || pattern.Action == c.Action) &&
+		(pattern.DeploymentName == "" || pattern.DeploymentName == c.DeploymentName) &&
+		(pattern.ValuesFile == "" || pattern.ValuesFile == c.ValuesFile) &&
+		(pattern.KubeVersion == "" || pattern.KubeVersion == c.KubeVersion)
+}
+
+// SetValues returns the pega chart values selecting this combination. Upgrade actions get the upgrade type they
+// require: zero-downtime for upgrade-deploy and out-of-place for upgrade.
+func (c Combination) SetValues() map[string]string {
+	values := map[string]string{}
+	if c.Provider != "" {
+		values["global.provider"] = c.Provider
+	}
+	if c.Action != "" {
+		values["global.actions.execute"] = c.Action
+		if c.Action == "upgrade-deploy" {
+			values["installer.upgrade.upgradeType"] = "zero-downtime"
+		} else if c.Action == "upgrade" {
+			values["installer.upgrade.upgradeType"] = "out-of-place"
+		}
+	}
+	if c.DeploymentName != "" {
+		values["global.deployment.name"] = c.DeploymentName
+	}
+	return values
+}
+
+// HelmOptions returns helm options for this combination with extraValues applied on top of SetValues
+func (c Combination) HelmOptions(extraValues map[string]string) *helm.Options {
+	values := c.SetValues()
+	for key, value := range extraValues {
+		values[key] = value
+	}
+	options := &helm.Options{SetValues: values}
+	if c.ValuesFile != "" {
+		options.ValuesFiles = []string{c.ValuesFile}
+	}
+	return options
+}
+
+// KubeVersionArgs returns the extra `helm template` arguments selecting the combination's Kubernetes version
+func (c Combination) KubeVersionArgs() []string {
+	if c.KubeVersion == "" {
+		return nil
+	}
+	return []string{"--kube-version", c.KubeVersion}
+}
+
+// Combinations returns the cartesian product of the declared dimensions, minus excluded combinations
+func (m Matrix) Combinations() []Combination {
+	combinations := []Combination{{}}
+	expand := func(values []string, set func(*Combination, string)) {
+		if len(values) == 0 {
+			return
+		}
+		var expanded []Combination
+		for _, combination := range combinations {
+			for _, value := range values {
+				next := combination
+				set(&next, value)
+				expanded = append(expanded, next)
+			}
+		}
+		combinations = expanded
+	}
+	expand(m.Providers, func(c *Combination, v string) { c.Provider = v })
+	expand(m.Actions, func(c *Combination, v string) { c.Action = v })
+	expand(m.DeploymentNames, func(c *Combination, v string) { c.DeploymentName = v })
+	expand(m.ValuesFiles, func(c *Combination, v string) { c.ValuesFile = v })
+	expand(m.KubeVersions, func(c *Combination, v string) { c.KubeVersion = v })
+
+	var included []Combination
+	for _, combination := range combinations {
+		excluded := false
+		for _, pattern := range m.Exclude {
+			if combination.matches(pattern) {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			included = append(included, combination)
+		}
+	}
+	return included
+}
+
+// Run runs test as a named parallel subtest of t for every combination
+func (m Matrix) Run(t *testing.T, test func(t *testing.T, combination Combination)) {
+	for _, combination := range m.Combinations() {
+		combination := combination
+		t.Run(combination.Name(), func(t *testing.T) {
+			t.Parallel()
+			test(t, combination)
+		})
+	}
+}
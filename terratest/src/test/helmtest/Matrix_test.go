@@ -0,0 +1,48 @@
+package helmtest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatrixCombinations(t *testing.T) {
+	matrix := Matrix{
+		Providers:    []string{"k8s", "eks"},
+		Actions:      SupportedDeployActions,
+		KubeVersions: []string{"1.21.0", "1.27.0"},
+		Exclude: []Combination{
+			{Action: "upgrade-deploy"},
+			{Provider: "eks", KubeVersion: "1.21.0"},
+		},
+	}
+
+	var names []string
+	for _, combination := range matrix.Combinations() {
+		names = append(names, combination.Name())
+	}
+	require.Equal(t, []string{
+		"k8s/deploy/1.21.0",
+		"k8s/deploy/1.27.0",
+		"k8s/install-deploy/1.21.0",
+		"k8s/install-deploy/1.27.0",
+		"eks/deploy/1.27.0",
+		"eks/install-deploy/1.27.0",
+	}, names)
+}
+
+func TestCombinationHelmOptions(t *testing.T) {
+	combination := Combination{Provider: "gke", Action: "upgrade-deploy", DeploymentName: "myapp-dev", ValuesFile: "data/values_pdb_enabled.yaml"}
+
+	options := combination.HelmOptions(map[string]string{"global.tier[0].name": "web"})
+	require.Equal(t, map[string]string{
+		"global.provider":               "gke",
+		"global.actions.execute":        "upgrade-deploy",
+		"installer.upgrade.upgradeType": "zero-downtime",
+		"global.deployment.name":        "myapp-dev",
+		"global.tier[0].name":           "web",
+	}, options.SetValues)
+	require.Equal(t, []string{"data/values_pdb_enabled.yaml"}, options.ValuesFiles)
+	require.Equal(t, "gke/upgrade-deploy/myapp-dev/values_pdb_enabled", combination.Name())
+	require.Nil(t, combination.KubeVersionArgs())
+}
@@ -0,0 +1,83 @@
+package helmtest
+
+import (
+	"encoding/json"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const sourceCommentPrefix = "# Source: "
+
+// PodSpecPaths locates the pod template of the workload kinds, as dotted paths into the object
+var PodSpecPaths = map[string]string{
+	"Pod":                   "spec",
+	"Deployment":            "spec.template.spec",
+	"StatefulSet":           "spec.template.spec",
+	"DaemonSet":             "spec.template.spec",
+	"ReplicaSet":            "spec.template.spec",
+	"ReplicationController": "spec.template.spec",
+	"Job":                   "spec.template.spec",
+	"CronJob":               "spec.jobTemplate.spec.template.spec",
+}
+
+// Resource is a single rendered Kubernetes object
+type Resource struct {
+	// Source is the template that produced the object as reported by helm, e.g. pega/templates/pega-tier-hpa.yaml
+	Source     string
+	APIVersion string
+	Kind       string
+	Name       string
+	Namespace  string
+	Labels     map[string]string
+	// Object is the typed object, or *unstructured.Unstructured when the kind is not known to the test scheme
+	Object runtime.Object
+	// YAML is the document as rendered, including the # Source: comment
+	YAML string
+}
+
+// newResource decodes a single YAML document, returning nil for documents that only contain comments or whitespace
+func newResource(document []byte, defaultNamespace string) (*Resource, error) {
+	resource := &Resource{YAML: string(document)}
+	empty := true
+	for _, line := range strings.Split(resource.YAML, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, sourceCommentPrefix) && resource.Source == "" {
+			resource.Source = strings.TrimSpace(strings.TrimPrefix(trimmed, sourceCommentPrefix))
+		}
+		if trimmed != "" && trimmed != "---" && !strings.HasPrefix(trimmed, "#") {
+			empty = false
+		}
+	}
+	if empty {
+		return nil, nil
+	}
+
+	jsonData, err := yaml.ToJSON(document)
+	if err != nil {
+		return nil, err
+	}
+	var metadata DeploymentMetadata
+	if err := json.Unmarshal(jsonData, &metadata); err != nil {
+		return nil, err
+	}
+	obj, err := decodeObject(document)
+	if err != nil {
+		return nil, err
+	}
+	resource.Object = obj
+	resource.APIVersion = metadata.APIVersion
+	resource.Kind = metadata.Kind
+	resource.Name = metadata.Name
+	resource.Namespace = metadata.Namespace
+	if resource.Namespace == "" {
+		resource.Namespace = defaultNamespace
+	}
+	resource.Labels = metadata.Labels
+	return resource, nil
+}
+
+func (r Resource) key() SearchResourceOption {
+	return SearchResourceOption{Name: r.Name, Kind: r.Kind, Namespace: r.Namespace}
+}
@@ -0,0 +1,130 @@
+package helmtest
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// ResourceQuery selects rendered resources. Empty fields match everything.
+type ResourceQuery struct {
+	Kind       string
+	APIVersion string
+	// Name is a glob as understood by path.Match, e.g. "pega-*-hpa"
+	Name      string
+	Namespace string
+	// LabelSelector uses the kubectl syntax, e.g. "app=pega-web,tier!=batch"
+	LabelSelector string
+	// Source is a glob matched against the template path from the # Source: comment. It may either include the chart
+	// name (pega/templates/*.yaml) or be relative to the chart (templates/pega-tier-hpa.yaml).
+	Source string
+}
+
+func (q ResourceQuery) matches(resource Resource, selector labels.Selector) (bool, error) {
+	if q.Kind != "" && q.Kind != resource.Kind {
+		return false, nil
+	}
+	if q.APIVersion != "" && q.APIVersion != resource.APIVersion {
+		return false, nil
+	}
+	if q.Namespace != "" && q.Namespace != resource.Namespace {
+		return false, nil
+	}
+	if q.Name != "" {
+		matched, err := path.Match(q.Name, resource.Name)
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+	if q.Source != "" {
+		matched, err := matchSource(q.Source, resource.Source)
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+	return selector.Matches(labels.Set(resource.Labels)), nil
+}
+
+func matchSource(pattern string, source string) (bool, error) {
+	matched, err := path.Match(pattern, source)
+	if err != nil || matched {
+		return matched, err
+	}
+	// strip the chart name so that patterns relative to the chart directory match too
+	if i := strings.Index(source, "/"); i >= 0 {
+		return path.Match(pattern, source[i+1:])
+	}
+	return false, nil
+}
+
+// Query returns every rendered resource matching q, in rendering order, and fails the test if q is invalid
+func (p *HelmChartParser) Query(q ResourceQuery) []Resource {
+	resources, err := p.QueryE(q)
+	require.NoError(p.T, err)
+	return resources
+}
+
+// QueryE returns every rendered resource matching q, in rendering order
+func (p *HelmChartParser) QueryE(q ResourceQuery) ([]Resource, error) {
+	selector, err := labels.Parse(q.LabelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid label selector %q: %w", q.LabelSelector, err)
+	}
+	var matches []Resource
+	for _, resource := range p.resources {
+		matched, err := q.matches(resource, selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid query %+v: %w", q, err)
+		}
+		if matched {
+			matches = append(matches, resource)
+		}
+	}
+	return matches, nil
+}
+
+// QueryOneE returns the single resource matching q, returning an error if there are none or several
+func (p *HelmChartParser) QueryOneE(q ResourceQuery) (Resource, error) {
+	matches, err := p.QueryE(q)
+	if err != nil {
+		return Resource{}, err
+	}
+	if len(matches) != 1 {
+		return Resource{}, fmt.Errorf("expected exactly one resource matching %+v, found %d", q, len(matches))
+	}
+	return matches[0], nil
+}
+
+// QueryOne returns the single resource matching q and fails the test if there are none or several
+func (p *HelmChartParser) QueryOne(q ResourceQuery) Resource {
+	resource, err := p.QueryOneE(q)
+	require.NoError(p.T, err)
+	return resource
+}
+
+// QueryAs unmarshals every resource matching q into a new T, e.g. QueryAs[appsv1.Deployment](parser, query). Unlike
+// Resource.Object this works for API versions the vendored k8s.io/api does not know, as long as T is compatible.
+func QueryAs[T any](p *HelmChartParser, q ResourceQuery) []T {
+	objects, err := QueryAsE[T](p, q)
+	require.NoError(p.T, err)
+	return objects
+}
+
+// QueryAsE is QueryAs returning an error instead of failing the test
+func QueryAsE[T any](p *HelmChartParser, q ResourceQuery) ([]T, error) {
+	matches, err := p.QueryE(q)
+	if err != nil {
+		return nil, err
+	}
+	objects := make([]T, len(matches))
+	for i, match := range matches {
+		if err := helm.UnmarshalK8SYamlE(p.T, match.YAML, &objects[i]); err != nil {
+			return nil, fmt.Errorf("unmarshalling %s %s from %s: %w", match.Kind, match.Name, match.Source, err)
+		}
+	}
+	return objects, nil
+}
@@ -0,0 +1,9 @@
+package helmtest
+
+// SearchResourceOption identifies a rendered resource. Namespace is optional; when empty, resources in any namespace
+// match.
+type SearchResourceOption struct {
+	Name      string
+	Kind      string
+	Namespace string
+}
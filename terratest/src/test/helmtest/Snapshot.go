@@ -0,0 +1,222 @@
+package helmtest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+const maskedValue = "<masked>"
+
+// Scenario is a named way of rendering a chart, used as the unit of golden-manifest snapshot testing.
+type Scenario struct {
+	// Name overrides the name derived from the provider, action and values files
+	Name        string
+	Chart       string
+	ReleaseName string
+	Namespace   string
+	// Provider and Action set global.provider and global.actions.execute for the pega chart
+	Provider    string
+	Action      string
+	ValuesFiles []string
+	SetValues   map[string]string
+	KubeVersion string
+}
+
+// SnapshotMask replaces a field that legitimately differs between renders, such as a generated password, before the
+// output is compared. Field is a dot separated path into the object, e.g. "data.password".
+type SnapshotMask struct {
+	Kind  string
+	Name  string
+	Field string
+}
+
+// DefaultSnapshotMasks hides values generated with randAlphaNum
+var DefaultSnapshotMasks = []SnapshotMask{
+	{Kind: "Secret", Name: "srs-elastic-credentials", Field: "data.password"},
+}
+
+// ScenarioName returns Name, or provider-action-valuesfile when Name is empty
+func (s Scenario) ScenarioName() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	parts := []string{s.Chart}
+	if s.Provider != "" {
+		parts = []string{s.Provider}
+	}
+	if s.Action != "" {
+		parts = append(parts, s.Action)
+	}
+	if len(s.ValuesFiles) == 0 {
+		parts = append(parts, "default")
+	}
+	for _, valuesFile := range s.ValuesFiles {
+		parts = append(parts, strings.TrimSuffix(filepath.Base(valuesFile), filepath.Ext(valuesFile)))
+	}
+	return strings.Join(parts, "-")
+}
+
+// HelmTest describes rendering the whole chart for this scenario
+func (s Scenario) HelmTest(t *testing.T) *HelmTest {
+	releaseName := s.ReleaseName
+	if releaseName == "" {
+		releaseName = "pega"
+	}
+	setValues := map[string]string{}
+	if s.Provider != "" {
+		setValues["global.provider"] = s.Provider
+	}
+	if s.Action != "" {
+		setValues["global.actions.execute"] = s.Action
+	}
+	for key, value := range s.SetValues {
+		setValues[key] = value
+	}
+	return &HelmTest{
+		T:           t,
+		ChartPath:   ChartPath(s.Chart),
+		ReleaseName: releaseName,
+		Namespace:   s.Namespace,
+		KubeVersion: s.KubeVersion,
+		HelmOptions: &helm.Options{SetValues: setValues, ValuesFiles: s.ValuesFiles},
+	}
+}
+
+// NormalizeManifests returns one normalized document per rendered resource, keyed by a file name derived from the
+// kind, name and (when it is not the release namespace) namespace. Keys are sorted and comments dropped so that only
+// semantic changes show up in a comparison.
+func NormalizeManifests(parser *HelmChartParser, releaseNamespace string, masks []SnapshotMask) (map[string]string, error) {
+	if releaseNamespace == "" {
+		releaseNamespace = "default"
+	}
+	manifests := map[string]string{}
+	for _, resource := range parser.resources {
+		var object map[string]interface{}
+		if err := yaml.Unmarshal([]byte(resource.YAML), &object); err != nil {
+			return nil, fmt.Errorf("normalizing %s %s: %w", resource.Kind, resource.Name, err)
+		}
+		for _, mask := range masks {
+			if mask.Kind == resource.Kind && mask.Name == resource.Name {
+				maskField(object, strings.Split(mask.Field, "."))
+			}
+		}
+		normalized, err := yaml.Marshal(object)
+		if err != nil {
+			return nil, err
+		}
+		fileName := strings.ToLower(resource.Kind) + "_" + resource.Name + ".yaml"
+		if resource.Namespace != releaseNamespace && resource.Namespace != "" {
+			fileName = resource.Namespace + "_" + fileName
+		}
+		if _, exists := manifests[fileName]; exists {
+			return nil, fmt.Errorf("%s %s is rendered more than once", resource.Kind, resource.Name)
+		}
+		manifests[fileName] = string(normalized)
+	}
+	return manifests, nil
+}
+
+func maskField(object map[string]interface{}, path []string) {
+	for i, key := range path {
+		value, ok := object[key]
+		if !ok {
+			return
+		}
+		if i == len(path)-1 {
+			object[key] = maskedValue
+			return
+		}
+		if object, ok = value.(map[string]interface{}); !ok {
+			return
+		}
+	}
+}
+
+// AssertGoldenManifests renders the scenario and compares every resource with goldenDir/<scenario name>/. With update
+// set it regenerates the golden directory instead, after an intended change.
+func AssertGoldenManifests(t *testing.T, goldenDir string, scenario Scenario, update bool) {
+	helmTest := scenario.HelmTest(t)
+	actual, err := NormalizeManifests(NewHelmConfigParser(helmTest), scenario.Namespace, DefaultSnapshotMasks)
+	require.NoError(t, err)
+
+	scenarioDir := filepath.Join(goldenDir, scenario.ScenarioName())
+	if update {
+		writeGoldenManifests(t, scenarioDir, actual)
+		return
+	}
+
+	expected, err := readGoldenManifests(scenarioDir)
+	require.NoError(t, err, "no golden manifests for scenario %s, run the test with -update to create them", scenario.ScenarioName())
+
+	for _, fileName := range sortedKeys(expected, actual) {
+		expectedManifest, inGolden := expected[fileName]
+		actualManifest, rendered := actual[fileName]
+		switch {
+		case !rendered:
+			t.Errorf("%s: resource in golden manifests was not rendered", fileName)
+		case !inGolden:
+			t.Errorf("%s: rendered resource has no golden manifest\n%s", fileName, actualManifest)
+		case expectedManifest != actualManifest:
+			diff, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+				A:        difflib.SplitLines(expectedManifest),
+				B:        difflib.SplitLines(actualManifest),
+				FromFile: "golden/" + fileName,
+				ToFile:   "rendered/" + fileName,
+				Context:  3,
+			})
+			t.Errorf("%s: rendered resource differs from golden manifest\n%s", fileName, diff)
+		}
+	}
+}
+
+func writeGoldenManifests(t *testing.T, scenarioDir string, manifests map[string]string) {
+	require.NoError(t, os.RemoveAll(scenarioDir))
+	require.NoError(t, os.MkdirAll(scenarioDir, 0755))
+	for fileName, manifest := range manifests {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(scenarioDir, fileName), []byte(manifest), 0644))
+	}
+}
+
+func readGoldenManifests(scenarioDir string) (map[string]string, error) {
+	files, err := ioutil.ReadDir(scenarioDir)
+	if err != nil {
+		return nil, err
+	}
+	manifests := map[string]string{}
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".yaml" {
+			continue
+		}
+		content, err := ioutil.ReadFile(filepath.Join(scenarioDir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		manifests[file.Name()] = strings.Replace(string(content), "\r", "", -1)
+	}
+	return manifests, nil
+}
+
+func sortedKeys(maps ...map[string]string) []string {
+	seen := map[string]bool{}
+	var keys []string
+	for _, m := range maps {
+		for key := range m {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
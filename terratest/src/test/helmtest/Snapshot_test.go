@@ -0,0 +1,56 @@
+package helmtest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const renderedSecrets = `---
+# Source: backingservices/charts/srs/templates/elasticsearchsecret.yaml
+kind: Secret
+apiVersion: v1
+metadata:
+  name: srs-elastic-credentials
+data:
+  username: ZWxhc3RpYw==
+  password: cmFuZG9t
+---
+# Source: backingservices/charts/srs/templates/registrysecret.yaml
+kind: Secret
+apiVersion: v1
+metadata:
+  name: srs-reg-secret
+  namespace: search
+type: kubernetes.io/dockerconfigjson
+`
+
+func TestNormalizeManifests(t *testing.T) {
+	manifests, err := NormalizeManifests(NewHelmChartParser(t, renderedSecrets, ""), "", DefaultSnapshotMasks)
+	require.NoError(t, err)
+
+	require.Equal(t, map[string]string{
+		"secret_srs-elastic-credentials.yaml": `apiVersion: v1
+data:
+  password: <masked>
+  username: ZWxhc3RpYw==
+kind: Secret
+metadata:
+  name: srs-elastic-credentials
+`,
+		"search_secret_srs-reg-secret.yaml": `apiVersion: v1
+kind: Secret
+metadata:
+  name: srs-reg-secret
+  namespace: search
+type: kubernetes.io/dockerconfigjson
+`,
+	}, manifests)
+}
+
+func TestScenarioName(t *testing.T) {
+	require.Equal(t, "k8s-deploy-default", Scenario{Chart: PegaChart, Provider: "k8s", Action: "deploy"}.ScenarioName())
+	require.Equal(t, "eks-install-values-large", Scenario{Provider: "eks", Action: "install", ValuesFiles: []string{"/charts/pega/values-large.yaml"}}.ScenarioName())
+	require.Equal(t, "backingservices-default", Scenario{Chart: BackingServicesChart}.ScenarioName())
+	require.Equal(t, "srs", Scenario{Name: "srs", Chart: BackingServicesChart}.ScenarioName())
+}
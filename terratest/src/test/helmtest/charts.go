@@ -0,0 +1,25 @@
+package helmtest
+
+import (
+	"path/filepath"
+	"runtime"
+)
+
+// Names of the charts shipped in this repository, relative to the charts directory.
+const (
+	PegaChart            = "pega"
+	AddonsChart          = "addons"
+	BackingServicesChart = "backingservices"
+)
+
+// repoRoot is resolved from the location of this source file so that the charts can be found
+// regardless of which package (or command) is importing helmtest.
+var repoRoot = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..", "..", "..")
+}()
+
+// ChartPath returns the absolute path of one of the charts in this repository, e.g. ChartPath(PegaChart)
+func ChartPath(chart string) string {
+	return filepath.Join(repoRoot, "charts", chart)
+}
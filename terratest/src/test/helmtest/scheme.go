@@ -0,0 +1,37 @@
+package helmtest
+
+import (
+	managedcertsv1beta1 "github.com/GoogleCloudPlatform/gke-managed-certs/pkg/apis/networking.gke.io/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	backendconfigv1 "k8s.io/ingress-gce/pkg/apis/backendconfig/v1"
+)
+
+// resourceScheme knows the built-in Kubernetes kinds plus the GKE custom resources rendered by the pega chart
+var resourceScheme = func() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(backendconfigv1.AddToScheme(scheme))
+	utilruntime.Must(managedcertsv1beta1.AddToScheme(scheme))
+	return scheme
+}()
+
+var resourceDeserializer = serializer.NewCodecFactory(resourceScheme).UniversalDeserializer()
+
+// decodeObject decodes a single YAML document into its typed Kubernetes object. Kinds unknown to resourceScheme, such
+// as Traefik CRDs or API versions newer than the vendored k8s.io/api, are returned as *unstructured.Unstructured or
+// *unstructured.UnstructuredList.
+func decodeObject(document []byte) (runtime.Object, error) {
+	if obj, _, err := resourceDeserializer.Decode(document, nil, nil); err == nil {
+		return obj, nil
+	}
+	jsonData, err := yaml.ToJSON(document)
+	if err != nil {
+		return nil, err
+	}
+	return runtime.Decode(unstructured.UnstructuredJSONScheme, jsonData)
+}
@@ -0,0 +1,117 @@
+//go:build integration
+
+package integration
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/k8s"
+	"github.com/gruntwork-io/terratest/modules/random"
+	"github.com/gruntwork-io/terratest/modules/shell"
+	"github.com/stretchr/testify/require"
+)
+
+// Providers of the local cluster, selected with the PEGA_TEST_CLUSTER environment variable
+const (
+	Kind = "kind"
+	K3d  = "k3d"
+)
+
+// Cluster is a throwaway local Kubernetes cluster running in Docker
+type Cluster struct {
+	Provider string
+	Name     string
+	// KubectlOptions point at the kubeconfig written for the cluster, leaving the one of the user untouched
+	KubectlOptions *k8s.KubectlOptions
+}
+
+// NewCluster creates a kind cluster, or a k3d cluster when PEGA_TEST_CLUSTER is k3d, and waits for its nodes to be
+// ready. The caller deletes it with Teardown.
+func NewCluster(t *testing.T) *Cluster {
+	provider := os.Getenv("PEGA_TEST_CLUSTER")
+	if provider == "" {
+		provider = Kind
+	}
+	require.Contains(t, []string{Kind, K3d}, provider, "PEGA_TEST_CLUSTER must be kind or k3d")
+
+	cluster := &Cluster{Provider: provider, Name: "pega-" + strings.ToLower(random.UniqueId())}
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	var err error
+	switch provider {
+	case Kind:
+		err = shell.RunCommandE(t, shell.Command{Command: "kind", Args: []string{
+			"create", "cluster", "--name", cluster.Name, "--kubeconfig", kubeconfig, "--wait", "5m"}})
+	case K3d:
+		err = shell.RunCommandE(t, shell.Command{Command: "k3d", Args: []string{
+			"cluster", "create", cluster.Name, "--kubeconfig-update-default=false", "--kubeconfig-switch-context=false",
+			"--wait", "--timeout", "5m"}})
+		if err == nil {
+			var config string
+			config, err = shell.RunCommandAndGetStdOutE(t, shell.Command{Command: "k3d", Args: []string{"kubeconfig", "get", cluster.Name}})
+			if err == nil {
+				err = ioutil.WriteFile(kubeconfig, []byte(config), 0600)
+			}
+		}
+	}
+	if err != nil {
+		cluster.Delete(t)
+		require.NoError(t, err, "creating the %s cluster %s", provider, cluster.Name)
+	}
+
+	cluster.KubectlOptions = k8s.NewKubectlOptions("", kubeconfig, "")
+	k8s.WaitUntilAllNodesReady(t, cluster.KubectlOptions, 60, 5*time.Second)
+	return cluster
+}
+
+// LoadImage copies a locally built image onto the nodes of the cluster, so pods can use it without a registry
+func (c *Cluster) LoadImage(t *testing.T, image string) {
+	switch c.Provider {
+	case Kind:
+		shell.RunCommand(t, shell.Command{Command: "kind", Args: []string{"load", "docker-image", image, "--name", c.Name}})
+	case K3d:
+		shell.RunCommand(t, shell.Command{Command: "k3d", Args: []string{"image", "import", image, "--cluster", c.Name}})
+	}
+}
+
+// Exists reports whether the provider still lists the cluster
+func (c *Cluster) Exists(t *testing.T) bool {
+	var output string
+	switch c.Provider {
+	case Kind:
+		output = shell.RunCommandAndGetStdOut(t, shell.Command{Command: "kind", Args: []string{"get", "clusters"}})
+	case K3d:
+		output = shell.RunCommandAndGetStdOut(t, shell.Command{Command: "k3d", Args: []string{"cluster", "list", "--no-headers"}})
+	}
+	for _, line := range strings.Split(output, "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 && fields[0] == c.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// Teardown deletes the cluster and checks that it is gone, for deferring right after NewCluster
+func (c *Cluster) Teardown(t *testing.T) {
+	c.Delete(t)
+	require.False(t, c.Exists(t), "the %s cluster %s was not deleted", c.Provider, c.Name)
+}
+
+// Delete removes the cluster and its Docker containers. Failures are reported without stopping the test, so that it
+// can be called from a defer.
+func (c *Cluster) Delete(t *testing.T) {
+	var err error
+	switch c.Provider {
+	case Kind:
+		err = shell.RunCommandE(t, shell.Command{Command: "kind", Args: []string{"delete", "cluster", "--name", c.Name}})
+	case K3d:
+		err = shell.RunCommandE(t, shell.Command{Command: "k3d", Args: []string{"cluster", "delete", c.Name}})
+	}
+	if err != nil {
+		t.Errorf("deleting the %s cluster %s: %v", c.Provider, c.Name, err)
+	}
+}
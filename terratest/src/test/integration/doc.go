@@ -0,0 +1,8 @@
+// Package integration installs the charts on a throwaway local cluster, where the other packages only render them.
+// The tests need Docker, helm, and kind or k3d, and only build with the integration tag:
+//
+//	go test -v -tags integration -timeout 30m ./integration
+//
+// Images built from stub/ stand in for Pega, search, Hazelcast, the installer and k8s-wait-for. Set PEGA_TEST_CLUSTER=k3d to use
+// k3d instead of kind.
+package integration
@@ -0,0 +1,43 @@
+//go:build integration
+
+package integration
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/shell"
+	"github.com/stretchr/testify/require"
+)
+
+// stubImage is an image built from a target of stub/Dockerfile
+type stubImage struct {
+	Target string
+	Image  string
+}
+
+// stubImages stand in for the images of the Pega chart, matching data/values_local_cluster.yaml
+var stubImages = []stubImage{
+	{Target: "pega", Image: "pega-stub/pega:integration"},
+	{Target: "search", Image: "pega-stub/search:integration"},
+	{Target: "hazelcast", Image: "pega-stub/hazelcast:integration"},
+	{Target: "installer", Image: "pega-stub/installer:integration"},
+	{Target: "k8s-wait-for", Image: "pega-stub/k8s-wait-for:integration"},
+}
+
+// BuildStubImages builds every stub image with the local Docker daemon
+func BuildStubImages(t *testing.T) {
+	stubDir, err := filepath.Abs("stub")
+	require.NoError(t, err)
+	for _, stub := range stubImages {
+		shell.RunCommand(t, shell.Command{Command: "docker", Args: []string{
+			"build", "--target", stub.Target, "--tag", stub.Image, stubDir}})
+	}
+}
+
+// LoadStubImages loads every stub image into the cluster
+func LoadStubImages(t *testing.T, cluster *Cluster) {
+	for _, stub := range stubImages {
+		cluster.LoadImage(t, stub.Image)
+	}
+}
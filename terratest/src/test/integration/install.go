@@ -0,0 +1,49 @@
+//go:build integration
+
+package integration
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/gruntwork-io/terratest/modules/k8s"
+	"github.com/gruntwork-io/terratest/modules/random"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+	"github.com/stretchr/testify/require"
+)
+
+// InstallPega installs the pega chart as release pega into a new namespace of cluster, without waiting for it to
+// become ready. valuesFiles are relative to this package. It returns the options for the namespace.
+func InstallPega(t *testing.T, cluster *Cluster, valuesFiles []string, setValues map[string]string) *k8s.KubectlOptions {
+	namespace := "pega-" + strings.ToLower(random.UniqueId())
+	options := k8s.NewKubectlOptions("", cluster.KubectlOptions.ConfigPath, namespace)
+	k8s.CreateNamespace(t, options, namespace)
+
+	helm.Install(t, &helm.Options{
+		KubectlOptions: options,
+		ValuesFiles:    absolutePaths(t, valuesFiles),
+		SetValues:      setValues,
+	}, helmtest.ChartPath(helmtest.PegaChart), "pega")
+	return options
+}
+
+// UpgradePega upgrades the release installed by InstallPega with new values, without waiting for it to become ready
+func UpgradePega(t *testing.T, options *k8s.KubectlOptions, valuesFiles []string, setValues map[string]string) {
+	helm.Upgrade(t, &helm.Options{
+		KubectlOptions: options,
+		ValuesFiles:    absolutePaths(t, valuesFiles),
+		SetValues:      setValues,
+	}, helmtest.ChartPath(helmtest.PegaChart), "pega")
+}
+
+func absolutePaths(t *testing.T, paths []string) []string {
+	var absolute []string
+	for _, path := range paths {
+		absolutePath, err := filepath.Abs(path)
+		require.NoError(t, err)
+		absolute = append(absolute, absolutePath)
+	}
+	return absolute
+}
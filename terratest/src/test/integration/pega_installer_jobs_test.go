@@ -0,0 +1,249 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/k8s"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// installerOnCommand are the values of the tests where the installer Jobs run until they are told how to end
+var installerOnCommand = []string{"data/values_local_cluster.yaml", "data/values_installer_on_command.yaml"}
+
+// TestInstallerJobsOnLocalCluster - runs the install-deploy and zero-downtime upgrade-deploy flows with stub installer
+// Jobs that end on command and a stub k8s-wait-for image. The tiers, and the Jobs depending on other Jobs, must wait in
+// their wait-for init containers until the right Jobs have completed, and must not start at all when the installer
+// fails. The stubs read the Jobs and workloads with the service account of the pods, so the flows also show that the
+// jobs-reader Role grants the verbs they need, and no more than reading is allowed.
+func TestInstallerJobsOnLocalCluster(t *testing.T) {
+	BuildStubImages(t)
+	cluster := NewCluster(t)
+	defer cluster.Teardown(t)
+	LoadStubImages(t, cluster)
+
+	t.Run("installer failure", func(t *testing.T) {
+		options := InstallPega(t, cluster, installerOnCommand, nil)
+		for _, tier := range pegaTiers {
+			WaitUntilInitContainerRunning(t, options, tier, "wait-for-pegainstall", 60, 5*time.Second)
+		}
+		EndInstallerJob(t, options, "pega-db-install", "fail")
+		WaitUntilJobFailed(t, options, "pega-db-install", 30, 2*time.Second)
+
+		// k8s-wait-for fails with the Job, and the kubelet keeps restarting it
+		for _, tier := range pegaTiers {
+			retry.DoWithRetry(t, tier+" wait-for-pegainstall failed", 30, 5*time.Second, func() (string, error) {
+				for _, pod := range ListAppPods(t, options, tier) {
+					if !initContainerFailed(pod, "wait-for-pegainstall") {
+						return "", fmt.Errorf("wait-for-pegainstall of %s has not failed", pod.Name)
+					}
+				}
+				return "", nil
+			})
+			for _, pod := range ListAppPods(t, options, tier) {
+				require.Equal(t, corev1.PodPending, pod.Status.Phase, pod.Name)
+			}
+		}
+	})
+
+	options := InstallPega(t, cluster, installerOnCommand, nil)
+	installed := t.Run("install-deploy", func(t *testing.T) {
+		for _, tier := range pegaTiers {
+			WaitUntilInitContainerRunning(t, options, tier, "wait-for-pegainstall", 60, 5*time.Second)
+		}
+		for _, tier := range pegaTiers {
+			RequireInitContainerKeepsRunning(t, options, tier, "wait-for-pegainstall", 4, 5*time.Second)
+		}
+		requireWaiting(t, options, pegaTiers, "wait-for-pegainstall")
+
+		EndInstallerJob(t, options, "pega-db-install", "succeed")
+		WaitUntilJobSucceeded(t, options, "pega-db-install", 30, 2*time.Second)
+		WaitUntilDeploymentReady(t, options, "pega-web", 60, 5*time.Second)
+		WaitUntilStatefulSetReady(t, options, "pega-stream", 60, 5*time.Second)
+		requireWaitedFor(t, options, pegaTiers, "wait-for-pegainstall", "pega-db-install")
+	})
+
+	t.Run("jobs-reader permissions", func(t *testing.T) {
+		serviceAccount := "system:serviceaccount:" + options.Namespace + ":default"
+		for _, resource := range []string{"jobs.batch", "deployments.apps", "statefulsets.apps"} {
+			for _, verb := range []string{"get", "list", "watch"} {
+				require.True(t, canI(t, options, serviceAccount, verb, resource), "%s cannot %s %s", serviceAccount, verb, resource)
+			}
+			for _, verb := range []string{"create", "update", "patch", "delete"} {
+				require.False(t, canI(t, options, serviceAccount, verb, resource), "%s can %s %s", serviceAccount, verb, resource)
+			}
+		}
+		for _, resource := range []string{"pods", "secrets", "configmaps"} {
+			require.False(t, canI(t, options, serviceAccount, "get", resource), "%s can get %s", serviceAccount, resource)
+		}
+	})
+
+	if !installed {
+		return
+	}
+	t.Run("zero-downtime upgrade-deploy", func(t *testing.T) {
+		UpgradePega(t, options, append(installerOnCommand, "data/values_zdt_upgrade.yaml"), nil)
+
+		// pega-zdt-upgrade waits for pega-pre-upgrade, and the tiers and pega-post-upgrade for pega-zdt-upgrade, while
+		// the pods of the previous release keep serving
+		WaitUntilInstallerInitContainerRunning(t, options, "pega-zdt-upgrade", "wait-for-pre-dbupgrade", 60, 5*time.Second)
+		WaitUntilInstallerInitContainerRunning(t, options, "pega-post-upgrade", "wait-for-pegaupgrade", 60, 5*time.Second)
+		for _, tier := range pegaTiers {
+			waitUntilRolledPodWaiting(t, options, tier, "wait-for-pegaupgrade")
+		}
+		requireDeploymentServing(t, options, "pega-web")
+
+		EndInstallerJob(t, options, "pega-pre-upgrade", "succeed")
+		WaitUntilJobSucceeded(t, options, "pega-pre-upgrade", 30, 2*time.Second)
+		for _, tier := range pegaTiers {
+			RequireInitContainerKeepsRunning(t, options, tier, "wait-for-pegaupgrade", 4, 5*time.Second)
+		}
+		requireWaiting(t, options, pegaTiers, "wait-for-pegaupgrade")
+		requireDeploymentServing(t, options, "pega-web")
+
+		EndInstallerJob(t, options, "pega-zdt-upgrade", "succeed")
+		WaitUntilJobSucceeded(t, options, "pega-zdt-upgrade", 30, 2*time.Second)
+		requireWaitedFor(t, options, []string{"installer-job=pega-zdt-upgrade"}, "wait-for-pre-dbupgrade", "pega-pre-upgrade")
+		WaitUntilDeploymentReady(t, options, "pega-web", 60, 5*time.Second)
+		WaitUntilStatefulSetReady(t, options, "pega-stream", 60, 5*time.Second)
+		requireWaitedFor(t, options, pegaTiers, "wait-for-pegaupgrade", "pega-zdt-upgrade")
+
+		// wait-for-rolling-updates lets pega-post-upgrade run once every tier has rolled out
+		EndInstallerJob(t, options, "pega-post-upgrade", "succeed")
+		WaitUntilJobSucceeded(t, options, "pega-post-upgrade", 30, 2*time.Second)
+		post := installerPod(t, options, "pega-post-upgrade")
+		rolled := InitContainerState(t, post, "wait-for-rolling-updates").Terminated
+		require.NotNil(t, rolled)
+		require.Zero(t, rolled.ExitCode)
+		for _, tier := range pegaTiers {
+			for _, pod := range ListAppPods(t, options, tier) {
+				ready := podCondition(pod, corev1.PodReady)
+				require.NotNil(t, ready, pod.Name)
+				require.False(t, rolled.FinishedAt.Before(&ready.LastTransitionTime), "pega-post-upgrade ran before %s was ready", pod.Name)
+			}
+		}
+	})
+}
+
+// EndInstallerJob tells the stub installer of job, run with data/values_installer_on_command.yaml, to succeed or fail.
+// It waits for the init containers of the Job to complete first.
+func EndInstallerJob(t *testing.T, options *k8s.KubectlOptions, job string, result string) {
+	var pod corev1.Pod
+	retry.DoWithRetry(t, job+" running", 60, 5*time.Second, func() (string, error) {
+		pod = installerPod(t, options, job)
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name == "pega-installer" && status.State.Running != nil {
+				return "", nil
+			}
+		}
+		return "", fmt.Errorf("%s is not running the installer", pod.Name)
+	})
+
+	tunnel := k8s.NewTunnel(options, k8s.ResourceTypePod, pod.Name, 0, 8080)
+	defer tunnel.Close()
+	tunnel.ForwardPort(t)
+	stubRequest(t, http.MethodPost, "http://"+tunnel.Endpoint()+"/stub/"+result, http.StatusNoContent, nil)
+}
+
+// installerPod returns the pod of an installer Job, which runs a single pod as its backoffLimit is 0
+func installerPod(t *testing.T, options *k8s.KubectlOptions, job string) corev1.Pod {
+	pods := k8s.ListPods(t, options, metav1.ListOptions{LabelSelector: "installer-job=" + job})
+	require.Len(t, pods, 1, "pods of %s", job)
+	return pods[0]
+}
+
+// requireWaiting checks that the pods of the tiers with the init container name still run it, without their main
+// container having started
+func requireWaiting(t *testing.T, options *k8s.KubectlOptions, tiers []string, name string) {
+	for _, tier := range tiers {
+		for _, pod := range podsWithInitContainer(ListAppPods(t, options, tier), name) {
+			require.NotNil(t, InitContainerState(t, pod, name).Running, "%s stopped waiting in %s", pod.Name, name)
+			require.Nil(t, containerStatus(t, pod, pod.Spec.Containers[0].Name).State.Running, "%s started", pod.Name)
+		}
+	}
+}
+
+// requireWaitedFor checks that the init container name of every pod selected exited successfully, not before the
+// installer of job had finished. selectors are tier names, or label selectors when they contain "=".
+func requireWaitedFor(t *testing.T, options *k8s.KubectlOptions, selectors []string, name string, job string) {
+	installer := containerStatus(t, installerPod(t, options, job), "pega-installer").State.Terminated
+	require.NotNil(t, installer, "the installer of %s has not terminated", job)
+	require.Zero(t, installer.ExitCode)
+	for _, selector := range selectors {
+		if !strings.Contains(selector, "=") {
+			selector = "app=" + selector
+		}
+		pods := podsWithInitContainer(k8s.ListPods(t, options, metav1.ListOptions{LabelSelector: selector}), name)
+		require.NotEmpty(t, pods, "no pods with %s run %s", selector, name)
+		for _, pod := range pods {
+			waited := InitContainerState(t, pod, name).Terminated
+			require.NotNil(t, waited, "%s of %s has not terminated", name, pod.Name)
+			require.Zero(t, waited.ExitCode, pod.Name)
+			require.False(t, waited.FinishedAt.Before(&installer.FinishedAt), "%s of %s finished before %s", name, pod.Name, job)
+		}
+	}
+}
+
+// waitUntilRolledPodWaiting waits for a pod of the new revision of a tier to run the init container name
+func waitUntilRolledPodWaiting(t *testing.T, options *k8s.KubectlOptions, tier string, name string) {
+	retry.DoWithRetry(t, tier+" rolled pod running "+name, 60, 5*time.Second, func() (string, error) {
+		for _, pod := range podsWithInitContainer(ListAppPods(t, options, tier), name) {
+			if InitContainerState(t, pod, name).Running != nil {
+				return "", nil
+			}
+		}
+		return "", fmt.Errorf("no pod of %s is running %s", tier, name)
+	})
+}
+
+// requireDeploymentServing checks that a Deployment still has a ready replica while its rollout waits
+func requireDeploymentServing(t *testing.T, options *k8s.KubectlOptions, name string) {
+	deployment, err := client(t, options).AppsV1().Deployments(options.Namespace).Get(context.Background(), name, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, deployment.Status.ReadyReplicas, int32(1), "%s has no ready replica during the upgrade", name)
+}
+
+func podsWithInitContainer(pods []corev1.Pod, name string) []corev1.Pod {
+	var selected []corev1.Pod
+	for _, pod := range pods {
+		for _, container := range pod.Spec.InitContainers {
+			if container.Name == name {
+				selected = append(selected, pod)
+			}
+		}
+	}
+	return selected
+}
+
+// initContainerFailed reports whether the init container name of pod has exited with an error, now or before a restart
+func initContainerFailed(pod corev1.Pod, name string) bool {
+	for _, status := range pod.Status.InitContainerStatuses {
+		if status.Name != name {
+			continue
+		}
+		for _, state := range []corev1.ContainerState{status.State, status.LastTerminationState} {
+			if state.Terminated != nil && state.Terminated.ExitCode != 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// canI asks the API server whether user may perform verb on resource in the namespace of options
+func canI(t *testing.T, options *k8s.KubectlOptions, user string, verb string, resource string) bool {
+	output, err := k8s.RunKubectlAndGetOutputE(t, options, "auth", "can-i", verb, resource, "--as", user)
+	output = strings.TrimSpace(output)
+	answer := output[strings.LastIndex(output, "\n")+1:]
+	require.Contains(t, []string{"yes", "no"}, answer, "kubectl auth can-i: %v", err)
+	return answer == "yes"
+}
@@ -0,0 +1,58 @@
+//go:build integration
+
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/k8s"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// pegaTiers are the tiers of data/values_local_cluster.yaml, which wait for search before starting Pega
+var pegaTiers = []string{"pega-web", "pega-stream"}
+
+// TestPegaOnLocalCluster - installs the pega chart with stub images on a kind or k3d cluster. Search is installed
+// without replicas at first, to show that the tiers block in wait-for-pegasearch until it is scaled up. Every
+// workload then becomes ready, every Service gets endpoints and the cluster is deleted.
+func TestPegaOnLocalCluster(t *testing.T) {
+	BuildStubImages(t)
+	cluster := NewCluster(t)
+	defer cluster.Teardown(t)
+	LoadStubImages(t, cluster)
+
+	options := InstallPega(t, cluster, []string{"data/values_local_cluster.yaml"}, map[string]string{"pegasearch.replicas": "0"})
+
+	// wait-for-pegainstall releases the tiers once the installer Job has completed
+	WaitUntilJobSucceeded(t, options, "pega-db-install", 60, 5*time.Second)
+	for _, tier := range pegaTiers {
+		WaitUntilInitContainerRunning(t, options, tier, "wait-for-pegasearch", 60, 5*time.Second)
+	}
+
+	// without search the tiers stay blocked
+	for _, tier := range pegaTiers {
+		RequireInitContainerKeepsRunning(t, options, tier, "wait-for-pegasearch", 6, 5*time.Second)
+		for _, pod := range ListAppPods(t, options, tier) {
+			require.Equal(t, corev1.PodPending, pod.Status.Phase, pod.Name)
+		}
+	}
+
+	k8s.RunKubectl(t, options, "scale", "statefulset/pega-search", "--replicas=1")
+	WaitUntilStatefulSetReady(t, options, "pega-search", 60, 5*time.Second)
+	WaitUntilStatefulSetReady(t, options, "pega-hazelcast", 60, 5*time.Second)
+	WaitUntilDeploymentReady(t, options, "pega-web", 60, 5*time.Second)
+	WaitUntilStatefulSetReady(t, options, "pega-stream", 60, 5*time.Second)
+	for _, tier := range pegaTiers {
+		for _, pod := range ListAppPods(t, options, tier) {
+			terminated := InitContainerState(t, pod, "wait-for-pegasearch").Terminated
+			require.NotNil(t, terminated, pod.Name)
+			require.Zero(t, terminated.ExitCode, pod.Name)
+		}
+	}
+
+	for _, service := range []string{"pega-web", "pega-stream", "pega-search", "pega-search-transport", "pega-hazelcast-service"} {
+		WaitUntilServiceHasEndpoints(t, options, service, 30, 5*time.Second)
+	}
+}
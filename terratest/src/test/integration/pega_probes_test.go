@@ -0,0 +1,120 @@
+//go:build integration
+
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/k8s"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// stubPing is a ping request recorded by the stub Pega image, see stub/pega.go
+type stubPing struct {
+	Time      time.Time `json:"time"`
+	UserAgent string    `json:"userAgent"`
+	Status    int       `json:"status"`
+}
+
+// TestPegaProbesOnLocalCluster - runs the web tier on the stub Pega image under a custom context path. The stub
+// reports that it is starting for 40 seconds, so the pod may only turn ready after that, with the probes calling the
+// ping service under the context path. The environment set by the chart reaches the container, and once the stub is
+// told to fail the liveness probe restarts it.
+func TestPegaProbesOnLocalCluster(t *testing.T) {
+	BuildStubImages(t)
+	cluster := NewCluster(t)
+	defer cluster.Teardown(t)
+	LoadStubImages(t, cluster)
+
+	options := InstallPega(t, cluster, []string{"data/values_local_cluster.yaml", "data/values_stub_probes.yaml"}, nil)
+	WaitUntilDeploymentReady(t, options, "pega-web", 60, 5*time.Second)
+
+	pods := ListAppPods(t, options, "pega-web")
+	require.Len(t, pods, 1)
+	pod := pods[0]
+	started := containerStatus(t, pod, "pega-web-tomcat").State.Running
+	require.NotNil(t, started, "pega-web-tomcat is not running")
+	ready := podCondition(pod, corev1.PodReady)
+	require.NotNil(t, ready)
+	require.GreaterOrEqual(t, ready.LastTransitionTime.Sub(started.StartedAt.Time), 40*time.Second,
+		"the pod turned ready before the stub finished starting")
+
+	tunnel := k8s.NewTunnel(options, k8s.ResourceTypePod, pod.Name, 0, 8080)
+	tunnel.ForwardPort(t)
+	defer tunnel.Close()
+	baseURL := "http://" + tunnel.Endpoint()
+
+	env := map[string]string{}
+	stubRequest(t, http.MethodGet, baseURL+"/stub/env", http.StatusOK, &env)
+	require.Equal(t, "stubweb", strings.Trim(env["PEGA_APP_CONTEXT_PATH"], "/"))
+	require.Equal(t, "WebUser", env["NODE_TYPE"])
+	require.Equal(t, "web", env["NODE_TIER"])
+	require.Equal(t, "10", env["RETRY_TIMEOUT"])
+	require.Equal(t, "4", env["MAX_RETRIES"])
+	require.Equal(t, pod.Name, env["POD_NAME"])
+	require.NotEmpty(t, env["JDBC_URL"], "pega-environment-config is not passed to the container")
+
+	var pings []stubPing
+	stubRequest(t, http.MethodGet, baseURL+"/stub/pings", http.StatusOK, &pings)
+	require.NotEmpty(t, pings)
+	require.Equal(t, http.StatusServiceUnavailable, pings[0].Status, "the first ping came after the stub had started")
+	sawHealthy := false
+	for _, ping := range pings {
+		require.True(t, strings.HasPrefix(ping.UserAgent, "kube-probe/"), "ping from %s", ping.UserAgent)
+		if ping.Status == http.StatusOK {
+			sawHealthy = true
+		}
+	}
+	require.True(t, sawHealthy, "no ping answered while healthy")
+
+	// a failing node is restarted by the liveness probe, and starts again with the startup delay
+	stubRequest(t, http.MethodPost, baseURL+"/stub/fail", http.StatusNoContent, nil)
+	tunnel.Close()
+	retry.DoWithRetry(t, "pega-web-tomcat restarted", 30, 5*time.Second, func() (string, error) {
+		pod := k8s.GetPod(t, options, pod.Name)
+		if restarts := containerStatus(t, *pod, "pega-web-tomcat").RestartCount; restarts == 0 {
+			return "", fmt.Errorf("not restarted yet")
+		}
+		return "", nil
+	})
+	WaitUntilDeploymentReady(t, options, "pega-web", 60, 5*time.Second)
+}
+
+// stubRequest calls an endpoint of the stub Pega image, decoding the JSON response into out unless it is nil
+func stubRequest(t *testing.T, method string, url string, expectedStatus int, out interface{}) {
+	request, err := http.NewRequest(method, url, nil)
+	require.NoError(t, err)
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	defer response.Body.Close()
+	require.Equal(t, expectedStatus, response.StatusCode, "%s %s", method, url)
+	if out != nil {
+		require.NoError(t, json.NewDecoder(response.Body).Decode(out))
+	}
+}
+
+func containerStatus(t *testing.T, pod corev1.Pod, name string) corev1.ContainerStatus {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == name {
+			return status
+		}
+	}
+	require.Failf(t, "container not found", "%s has no container %s", pod.Name, name)
+	return corev1.ContainerStatus{}
+}
+
+func podCondition(pod corev1.Pod, conditionType corev1.PodConditionType) *corev1.PodCondition {
+	for i := range pod.Status.Conditions {
+		if pod.Status.Conditions[i].Type == conditionType {
+			return &pod.Status.Conditions[i]
+		}
+	}
+	return nil
+}
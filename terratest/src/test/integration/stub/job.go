@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// runJob stands in for the installer, logging the ACTION the chart passes. STUB_JOB_RESULT sets how the job ends:
+// succeed (the default) or fail after STUB_JOB_SECONDS, or command to wait until a test posts to /stub/succeed or
+// /stub/fail on port 8080. It returns the exit code of the job.
+func runJob() int {
+	action := getenv("ACTION", "none")
+	switch result := getenv("STUB_JOB_RESULT", "succeed"); result {
+	case "succeed", "fail":
+		seconds, err := strconv.Atoi(getenv("STUB_JOB_SECONDS", "5"))
+		if err != nil {
+			log.Fatalf("STUB_JOB_SECONDS: %v", err)
+		}
+		log.Printf("running %s for %ds", action, seconds)
+		time.Sleep(time.Duration(seconds) * time.Second)
+		return jobExit(action, result)
+	case "command":
+		control := newJobControl()
+		server := &http.Server{Addr: ":8080", Handler: control}
+		go func() {
+			if err := server.ListenAndServe(); err != http.ErrServerClosed {
+				log.Fatal(err)
+			}
+		}()
+		log.Printf("running %s until POST /stub/succeed or /stub/fail", action)
+		result := <-control.result
+		// lets the response to the command go out before the process exits
+		server.Shutdown(context.Background())
+		return jobExit(action, result)
+	default:
+		log.Fatalf("unknown STUB_JOB_RESULT %s", result)
+		return 2
+	}
+}
+
+func jobExit(action string, result string) int {
+	if result == "fail" {
+		log.Printf("%s failed", action)
+		return 1
+	}
+	log.Printf("%s completed", action)
+	return 0
+}
+
+// jobControl receives the command ending a job run with STUB_JOB_RESULT=command
+type jobControl struct {
+	result chan string
+}
+
+func newJobControl() *jobControl {
+	return &jobControl{result: make(chan string, 1)}
+}
+
+func (c *jobControl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || (r.URL.Path != "/stub/succeed" && r.URL.Path != "/stub/fail") {
+		http.NotFound(w, r)
+		return
+	}
+	select {
+	case c.result <- r.URL.Path[len("/stub/"):]:
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "the job has already been told how to end", http.StatusConflict)
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobControlEndsOnCommand(t *testing.T) {
+	control := newJobControl()
+	require.Equal(t, http.StatusNotFound, serve(control, http.MethodGet, "/stub/succeed"))
+	require.Equal(t, http.StatusNoContent, serve(control, http.MethodPost, "/stub/fail"))
+	require.Equal(t, http.StatusConflict, serve(control, http.MethodPost, "/stub/succeed"))
+	require.Equal(t, "fail", <-control.result)
+}
+
+func TestJobExitCodes(t *testing.T) {
+	require.Equal(t, 0, jobExit("install", "succeed"))
+	require.Equal(t, 1, jobExit("install", "fail"))
+}
+
+func serve(handler http.Handler, method string, path string) int {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(method, path, nil))
+	return recorder.Code
+}
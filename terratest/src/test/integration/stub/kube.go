@@ -0,0 +1,134 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// serviceAccountDir is where Kubernetes mounts the token of the service account of a pod
+const serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// kubeClient calls the Kubernetes API as the service account of the pod, like kubectl in the real k8s-wait-for image.
+// It only reads, so a missing permission in the jobs-reader Role of the chart makes the wait fail.
+type kubeClient struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// apiError is a response of the API other than 200
+type apiError struct {
+	Status  int
+	Message string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.Status, http.StatusText(e.Status), e.Message)
+}
+
+func isNotFound(err error) bool {
+	var apiErr *apiError
+	return errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound
+}
+
+func newInClusterClient() (*kubeClient, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, errors.New("KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT are not set, the stub must run in a pod")
+	}
+	token, err := os.ReadFile(filepath.Join(serviceAccountDir, "token"))
+	if err != nil {
+		return nil, err
+	}
+	ca, err := os.ReadFile(filepath.Join(serviceAccountDir, "ca.crt"))
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, errors.New("no certificate found in " + filepath.Join(serviceAccountDir, "ca.crt"))
+	}
+	return &kubeClient{
+		baseURL: "https://" + net.JoinHostPort(host, port),
+		token:   strings.TrimSpace(string(token)),
+		client:  &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}},
+	}, nil
+}
+
+// podNamespace returns the namespace of the pod, the default of kubectl
+func podNamespace() (string, error) {
+	namespace, err := os.ReadFile(filepath.Join(serviceAccountDir, "namespace"))
+	return strings.TrimSpace(string(namespace)), err
+}
+
+// open sends a GET request for path, returning the response when it is 200
+func (c *kubeClient) open(path string) (*http.Response, error) {
+	request, err := http.NewRequest(http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", "application/json")
+	if c.token != "" {
+		request.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	response, err := c.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		defer response.Body.Close()
+		var status struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(response.Body).Decode(&status)
+		return nil, &apiError{Status: response.StatusCode, Message: status.Message}
+	}
+	return response, nil
+}
+
+// get decodes the object at path into out
+func (c *kubeClient) get(path string, out interface{}) error {
+	response, err := c.open(path)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	return json.NewDecoder(response.Body).Decode(out)
+}
+
+// watchEvent is an event of a watch request
+type watchEvent struct {
+	Type   string          `json:"type"`
+	Object json.RawMessage `json:"object"`
+}
+
+// watch streams the events of path to handle until it returns true or an error, or the API server ends the watch
+func (c *kubeClient) watch(path string, handle func(watchEvent) (bool, error)) (bool, error) {
+	response, err := c.open(path)
+	if err != nil {
+		return false, err
+	}
+	defer response.Body.Close()
+	decoder := json.NewDecoder(response.Body)
+	for {
+		var event watchEvent
+		if err := decoder.Decode(&event); err != nil {
+			// the API server closes watches after a timeout
+			return false, nil
+		}
+		if event.Type == "ERROR" {
+			return false, fmt.Errorf("watch %s: %s", path, event.Object)
+		}
+		if done, err := handle(event); done || err != nil {
+			return done, err
+		}
+	}
+}
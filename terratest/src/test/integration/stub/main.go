@@ -0,0 +1,94 @@
+// Command stub stands in for the Pega, search, Hazelcast, installer and k8s-wait-for images on a local test cluster.
+// It does no real work: as a Pega node it serves the ping service the probes call (see pegaStub), as another server it
+// answers every HTTP request on the configured ports with 200, which is enough to pass the probes of the real images,
+// as a job it ends as told (see runJob), and as k8s-wait-for it waits for a Job (see waitForJob). Run as kubectl, it
+// only supports the rollout status commands of wait-for-rolling-updates.
+//
+// The behaviour is set through the environment, which the Dockerfile targets preset for each image:
+//
+//	STUB_MODE         server (default), pega, job or wait-for
+//	STUB_PORTS        comma separated ports a server listens on, 8080 by default
+//	STUB_JOB_SECONDS  how long a job runs before it exits, 5 by default
+//	STUB_JOB_RESULT   succeed (default), fail or command
+//	WAIT_TIME         seconds between the checks of wait-for, set by the chart; 2 by default
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func main() {
+	if filepath.Base(os.Args[0]) == "kubectl" {
+		if len(os.Args) < 3 || os.Args[1] != "rollout" || os.Args[2] != "status" {
+			log.Fatalf("the stub kubectl only supports rollout status, got %s", strings.Join(os.Args[1:], " "))
+		}
+		if err := rolloutStatus(inClusterClient(), os.Args[3:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	var handler http.Handler
+	switch mode := getenv("STUB_MODE", "server"); mode {
+	case "job":
+		os.Exit(runJob())
+	case "wait-for":
+		seconds, err := strconv.Atoi(getenv("WAIT_TIME", "2"))
+		if err != nil {
+			log.Fatalf("WAIT_TIME: %v", err)
+		}
+		namespace, err := podNamespace()
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := waitForJob(inClusterClient(), namespace, os.Args[1:], time.Duration(seconds)*time.Second, time.Sleep); err != nil {
+			log.Fatal(err)
+		}
+		return
+	case "pega":
+		stub, err := newPegaStub()
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("serving %s, starting for %s", stub.pingPath(), stub.startupDelay)
+		log.Printf("environment: %s", strings.Join(sortedKeys(stub.env()), " "))
+		handler = stub
+	case "server":
+		handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, "ok")
+		})
+	default:
+		log.Fatalf("unknown STUB_MODE %s", mode)
+	}
+
+	ports := strings.Split(getenv("STUB_PORTS", "8080"), ",")
+	errors := make(chan error, len(ports))
+	for _, port := range ports {
+		address := ":" + strings.TrimSpace(port)
+		log.Printf("listening on %s", address)
+		go func() { errors <- http.ListenAndServe(address, handler) }()
+	}
+	log.Fatal(<-errors)
+}
+
+func inClusterClient() *kubeClient {
+	client, err := newInClusterClient()
+	if err != nil {
+		log.Fatal(err)
+	}
+	return client
+}
+
+func getenv(name string, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
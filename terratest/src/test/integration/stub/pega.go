@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxPings bounds the ping requests a pegaStub remembers
+const maxPings = 100
+
+// pegaStub implements the ping service of a Pega node, the endpoint of its liveness, readiness and startup probes,
+// with the startup and failure behaviour set through the environment:
+//
+//	PEGA_APP_CONTEXT_PATH  context the ping service is served under, set by the chart; prweb by default
+//	STUB_STARTUP_DELAY     how long the node reports it is starting, e.g. 90s
+//	STUB_PING_LATENCY      how long every ping takes to answer, to exceed a probe timeoutSeconds
+//	STUB_FAIL_AFTER        how long after startup the node turns unhealthy, to trigger the liveness probe
+//
+// Next to the ping service it serves endpoints for the tests:
+//
+//	GET  /stub/env      the environment of the container as a JSON object
+//	GET  /stub/pings    the ping requests received, most recent last
+//	POST /stub/fail     turn the node unhealthy
+//	POST /stub/recover  turn it healthy again
+type pegaStub struct {
+	contextPath  string
+	startupDelay time.Duration
+	pingLatency  time.Duration
+	failAfter    time.Duration
+	environment  func() []string
+	now          func() time.Time
+	sleep        func(time.Duration)
+
+	mu      sync.Mutex
+	started time.Time
+	failing bool
+	pings   []pingRecord
+}
+
+// pingRecord is a ping request as returned by /stub/pings. Probes identify themselves as kube-probe/<version>.
+type pingRecord struct {
+	Time      time.Time `json:"time"`
+	UserAgent string    `json:"userAgent"`
+	Status    int       `json:"status"`
+}
+
+// pingResponse follows the body of the Pega ping service
+type pingResponse struct {
+	NodeID   string   `json:"node_id"`
+	NodeType []string `json:"node_type"`
+	State    string   `json:"state"`
+}
+
+func newPegaStub() (*pegaStub, error) {
+	stub := &pegaStub{
+		contextPath: strings.Trim(getenv("PEGA_APP_CONTEXT_PATH", "prweb"), "/"),
+		environment: os.Environ,
+		now:         time.Now,
+		sleep:       time.Sleep,
+	}
+	for name, duration := range map[string]*time.Duration{
+		"STUB_STARTUP_DELAY": &stub.startupDelay,
+		"STUB_PING_LATENCY":  &stub.pingLatency,
+		"STUB_FAIL_AFTER":    &stub.failAfter,
+	} {
+		if value := os.Getenv(name); value != "" {
+			parsed, err := time.ParseDuration(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			*duration = parsed
+		}
+	}
+	stub.started = stub.now()
+	return stub, nil
+}
+
+func (s *pegaStub) pingPath() string {
+	return "/" + s.contextPath + "/PRRestService/monitor/pingService/ping"
+}
+
+func (s *pegaStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == s.pingPath():
+		s.ping(w, r)
+	case r.URL.Path == "/stub/env" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, s.env())
+	case r.URL.Path == "/stub/pings" && r.Method == http.MethodGet:
+		s.mu.Lock()
+		pings := append([]pingRecord{}, s.pings...)
+		s.mu.Unlock()
+		writeJSON(w, http.StatusOK, pings)
+	case (r.URL.Path == "/stub/fail" || r.URL.Path == "/stub/recover") && r.Method == http.MethodPost:
+		s.mu.Lock()
+		s.failing = r.URL.Path == "/stub/fail"
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// ping answers 503 while the node starts, 500 once it has failed and 200 otherwise
+func (s *pegaStub) ping(w http.ResponseWriter, r *http.Request) {
+	if s.pingLatency > 0 {
+		s.sleep(s.pingLatency)
+	}
+	s.mu.Lock()
+	uptime := s.now().Sub(s.started)
+	status, state := http.StatusOK, "healthy"
+	switch {
+	case uptime < s.startupDelay:
+		status, state = http.StatusServiceUnavailable, "starting"
+	case s.failing || (s.failAfter > 0 && uptime >= s.failAfter):
+		status, state = http.StatusInternalServerError, "unhealthy"
+	}
+	s.pings = append(s.pings, pingRecord{Time: s.now(), UserAgent: r.UserAgent(), Status: status})
+	if len(s.pings) > maxPings {
+		s.pings = s.pings[len(s.pings)-maxPings:]
+	}
+	s.mu.Unlock()
+
+	env := s.env()
+	var nodeTypes []string
+	if env["NODE_TYPE"] != "" {
+		nodeTypes = strings.Split(env["NODE_TYPE"], ",")
+	}
+	writeJSON(w, status, pingResponse{NodeID: env["POD_NAME"], NodeType: nodeTypes, State: state})
+}
+
+func (s *pegaStub) env() map[string]string {
+	env := map[string]string{}
+	for _, entry := range s.environment() {
+		if parts := strings.SplitN(entry, "=", 2); len(parts) == 2 {
+			env[parts[0]] = parts[1]
+		}
+	}
+	return env
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(value)
+}
+
+// sortedKeys is used for logging the configuration on startup
+func sortedKeys(env map[string]string) []string {
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const stubPingPath = "/stubweb/PRRestService/monitor/pingService/ping"
+
+// newTestStub returns a stub started at a fixed time, and a function moving its clock forward
+func newTestStub(t *testing.T, env map[string]string) (*pegaStub, func(time.Duration)) {
+	for name, value := range env {
+		t.Setenv(name, value)
+	}
+	t.Setenv("PEGA_APP_CONTEXT_PATH", "/stubweb/")
+	t.Setenv("NODE_TYPE", "WebUser,Stream")
+	t.Setenv("POD_NAME", "pega-web-0")
+
+	stub, err := newPegaStub()
+	require.NoError(t, err)
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	stub.now = func() time.Time { return now }
+	stub.started = now
+	stub.sleep = func(time.Duration) {}
+	return stub, func(d time.Duration) { now = now.Add(d) }
+}
+
+func ping(t *testing.T, stub *pegaStub) (int, pingResponse) {
+	request := httptest.NewRequest(http.MethodGet, stubPingPath, nil)
+	request.Header.Set("User-Agent", "kube-probe/1.27")
+	recorder := httptest.NewRecorder()
+	stub.ServeHTTP(recorder, request)
+	var response pingResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	return recorder.Code, response
+}
+
+func post(stub *pegaStub, path string) int {
+	recorder := httptest.NewRecorder()
+	stub.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, path, nil))
+	return recorder.Code
+}
+
+func TestPingServedUnderContextPath(t *testing.T) {
+	stub, _ := newTestStub(t, nil)
+	status, response := ping(t, stub)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, pingResponse{NodeID: "pega-web-0", NodeType: []string{"WebUser", "Stream"}, State: "healthy"}, response)
+
+	recorder := httptest.NewRecorder()
+	stub.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/prweb/PRRestService/monitor/pingService/ping", nil))
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestPingStartupDelayAndFailAfter(t *testing.T) {
+	stub, advance := newTestStub(t, map[string]string{"STUB_STARTUP_DELAY": "90s", "STUB_FAIL_AFTER": "10m"})
+
+	status, response := ping(t, stub)
+	require.Equal(t, http.StatusServiceUnavailable, status)
+	require.Equal(t, "starting", response.State)
+	advance(89 * time.Second)
+	status, _ = ping(t, stub)
+	require.Equal(t, http.StatusServiceUnavailable, status)
+	advance(time.Second)
+	status, _ = ping(t, stub)
+	require.Equal(t, http.StatusOK, status)
+	advance(10*time.Minute - 90*time.Second)
+	status, response = ping(t, stub)
+	require.Equal(t, http.StatusInternalServerError, status)
+	require.Equal(t, "unhealthy", response.State)
+}
+
+func TestPingFailAndRecoverOnCommand(t *testing.T) {
+	stub, _ := newTestStub(t, nil)
+
+	require.Equal(t, http.StatusNoContent, post(stub, "/stub/fail"))
+	status, _ := ping(t, stub)
+	require.Equal(t, http.StatusInternalServerError, status)
+	require.Equal(t, http.StatusNoContent, post(stub, "/stub/recover"))
+	status, _ = ping(t, stub)
+	require.Equal(t, http.StatusOK, status)
+}
+
+func TestPingLatency(t *testing.T) {
+	stub, _ := newTestStub(t, map[string]string{"STUB_PING_LATENCY": "15s"})
+	var slept time.Duration
+	stub.sleep = func(d time.Duration) { slept += d }
+	ping(t, stub)
+	ping(t, stub)
+	require.Equal(t, 30*time.Second, slept)
+}
+
+func TestEnvAndPingsEcho(t *testing.T) {
+	stub, advance := newTestStub(t, map[string]string{"STUB_STARTUP_DELAY": "5s", "RETRY_TIMEOUT": "30", "MAX_RETRIES": "4"})
+	ping(t, stub)
+	advance(10 * time.Second)
+	ping(t, stub)
+
+	recorder := httptest.NewRecorder()
+	stub.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/stub/env", nil))
+	env := map[string]string{}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &env))
+	require.Equal(t, "30", env["RETRY_TIMEOUT"])
+	require.Equal(t, "4", env["MAX_RETRIES"])
+	require.Equal(t, "/stubweb/", env["PEGA_APP_CONTEXT_PATH"])
+
+	recorder = httptest.NewRecorder()
+	stub.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/stub/pings", nil))
+	var pings []pingRecord
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &pings))
+	started := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.Equal(t, []pingRecord{
+		{Time: started, UserAgent: "kube-probe/1.27", Status: http.StatusServiceUnavailable},
+		{Time: started.Add(10 * time.Second), UserAgent: "kube-probe/1.27", Status: http.StatusOK},
+	}, pings)
+}
+
+func TestInvalidDuration(t *testing.T) {
+	t.Setenv("STUB_FAIL_AFTER", "ten minutes")
+	_, err := newPegaStub()
+	require.EqualError(t, err, `STUB_FAIL_AFTER: time: invalid duration "ten minutes"`)
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// workload holds the fields of a Job, Deployment or StatefulSet the waits look at
+type workload struct {
+	Metadata struct {
+		Name       string `json:"name"`
+		Generation int64  `json:"generation"`
+	} `json:"metadata"`
+	Spec struct {
+		Replicas *int32 `json:"replicas"`
+	} `json:"spec"`
+	Status struct {
+		ObservedGeneration int64  `json:"observedGeneration"`
+		Replicas           int32  `json:"replicas"`
+		UpdatedReplicas    int32  `json:"updatedReplicas"`
+		ReadyReplicas      int32  `json:"readyReplicas"`
+		AvailableReplicas  int32  `json:"availableReplicas"`
+		CurrentRevision    string `json:"currentRevision"`
+		UpdateRevision     string `json:"updateRevision"`
+		Succeeded          int32  `json:"succeeded"`
+		Failed             int32  `json:"failed"`
+	} `json:"status"`
+}
+
+// waitForJob stands in for the k8s-wait-for image, which the chart runs with the arguments job <name>. It gets the
+// Job every interval until it has succeeded, waiting while it does not exist yet, and fails when the Job fails.
+func waitForJob(client *kubeClient, namespace string, args []string, interval time.Duration, sleep func(time.Duration)) error {
+	if len(args) != 2 || args[0] != "job" {
+		return fmt.Errorf("the stub only waits for jobs: job <name>, got %s", strings.Join(args, " "))
+	}
+	name := args[1]
+	path := fmt.Sprintf("/apis/batch/v1/namespaces/%s/jobs/%s", namespace, name)
+	for {
+		var job workload
+		err := client.get(path, &job)
+		switch {
+		case isNotFound(err):
+			log.Printf("job %s does not exist yet", name)
+		case err != nil:
+			return fmt.Errorf("get job %s: %w", name, err)
+		case job.Status.Failed > 0:
+			return fmt.Errorf("job %s failed", name)
+		case job.Status.Succeeded > 0:
+			log.Printf("job %s succeeded", name)
+			return nil
+		default:
+			log.Printf("waiting for job %s", name)
+		}
+		sleep(interval)
+	}
+}
+
+// rolloutStatus stands in for kubectl rollout status <kind>/<name> [--namespace <namespace>], which the chart runs in
+// wait-for-rolling-updates. Like kubectl it lists the workload, then watches it until the rollout has completed.
+func rolloutStatus(client *kubeClient, args []string) error {
+	var resource, namespace string
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case (arg == "--namespace" || arg == "-n") && i+1 < len(args):
+			i++
+			namespace = args[i]
+		case strings.HasPrefix(arg, "--namespace="):
+			namespace = strings.TrimPrefix(arg, "--namespace=")
+		case resource == "" && !strings.HasPrefix(arg, "-"):
+			resource = arg
+		default:
+			return fmt.Errorf("unsupported argument %s", arg)
+		}
+	}
+	kind, name, found := strings.Cut(resource, "/")
+	var plural string
+	switch kind {
+	case "deployment":
+		plural = "deployments"
+	case "statefulset":
+		plural = "statefulsets"
+	}
+	if !found || plural == "" || name == "" {
+		return fmt.Errorf("the stub only supports rollout status deployment/<name> or statefulset/<name>, got %q", resource)
+	}
+	if namespace == "" {
+		var err error
+		if namespace, err = podNamespace(); err != nil {
+			return err
+		}
+	}
+
+	path := fmt.Sprintf("/apis/apps/v1/namespaces/%s/%s?fieldSelector=%s", namespace, plural, url.QueryEscape("metadata.name="+name))
+	for {
+		var list struct {
+			Metadata struct {
+				ResourceVersion string `json:"resourceVersion"`
+			} `json:"metadata"`
+			Items []workload `json:"items"`
+		}
+		if err := client.get(path, &list); err != nil {
+			return fmt.Errorf("list %s: %w", resource, err)
+		}
+		if len(list.Items) == 0 {
+			return fmt.Errorf("%s not found", resource)
+		}
+		if rolledOut(kind, list.Items[0]) {
+			return nil
+		}
+		done, err := client.watch(path+"&watch=true&resourceVersion="+list.Metadata.ResourceVersion, func(event watchEvent) (bool, error) {
+			var w workload
+			if err := json.Unmarshal(event.Object, &w); err != nil {
+				return false, err
+			}
+			if event.Type == "DELETED" {
+				return false, fmt.Errorf("%s was deleted", resource)
+			}
+			return rolledOut(kind, w), nil
+		})
+		if err != nil || done {
+			return err
+		}
+	}
+}
+
+// rolledOut reports whether every replica of a Deployment or StatefulSet runs its latest template, logging progress
+// like kubectl rollout status
+func rolledOut(kind string, w workload) bool {
+	replicas := int32(1)
+	if w.Spec.Replicas != nil {
+		replicas = *w.Spec.Replicas
+	}
+	status := w.Status
+	switch {
+	case status.ObservedGeneration < w.Metadata.Generation:
+		log.Printf("waiting for %s %q spec update to be observed", kind, w.Metadata.Name)
+	case status.UpdatedReplicas < replicas:
+		log.Printf("waiting for %s %q rollout to finish: %d out of %d new replicas have been updated", kind, w.Metadata.Name, status.UpdatedReplicas, replicas)
+	case kind == "deployment" && status.Replicas > status.UpdatedReplicas:
+		log.Printf("waiting for %s %q rollout to finish: %d old replicas are pending termination", kind, w.Metadata.Name, status.Replicas-status.UpdatedReplicas)
+	case kind == "deployment" && status.AvailableReplicas < status.UpdatedReplicas:
+		log.Printf("waiting for %s %q rollout to finish: %d of %d updated replicas are available", kind, w.Metadata.Name, status.AvailableReplicas, status.UpdatedReplicas)
+	case kind == "statefulset" && (status.ReadyReplicas < replicas || status.CurrentRevision != status.UpdateRevision):
+		log.Printf("waiting for %s %q rollout to finish: %d of %d replicas are ready", kind, w.Metadata.Name, status.ReadyReplicas, replicas)
+	default:
+		log.Printf("%s %q successfully rolled out", kind, w.Metadata.Name)
+		return true
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAPIServer serves the objects of a test as the Kubernetes API, recording the requests it receives
+type fakeAPIServer struct {
+	mu       sync.Mutex
+	objects  map[string][]string
+	requests []string
+}
+
+func newFakeClient(t *testing.T, objects map[string][]string) (*kubeClient, *fakeAPIServer) {
+	api := &fakeAPIServer{objects: objects}
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+	return &kubeClient{baseURL: server.URL, token: "token", client: server.Client()}, api
+}
+
+// ServeHTTP answers each request for a path with the next of its objects, repeating the last one
+func (a *fakeAPIServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.requests = append(a.requests, r.Header.Get("Authorization")+" "+r.URL.RequestURI())
+	path := r.URL.Path
+	if r.URL.Query().Get("watch") == "true" {
+		path += "?watch"
+	}
+	objects := a.objects[path]
+	if len(objects) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message": "not found"}`)
+		return
+	}
+	if len(objects) > 1 {
+		a.objects[path] = objects[1:]
+	}
+	if objects[0] == "forbidden" {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"message": "jobs.batch \"pega-db-install\" is forbidden"}`)
+		return
+	}
+	fmt.Fprint(w, objects[0])
+}
+
+func noSleep(time.Duration) {}
+
+func TestWaitForJob(t *testing.T) {
+	client, api := newFakeClient(t, map[string][]string{
+		"/apis/batch/v1/namespaces/pega/jobs/pega-db-install": {`{"status": {}}`, `{"status": {"succeeded": 1}}`},
+	})
+	require.NoError(t, waitForJob(client, "pega", []string{"job", "pega-db-install"}, time.Second, noSleep))
+	require.Equal(t, []string{
+		"Bearer token /apis/batch/v1/namespaces/pega/jobs/pega-db-install",
+		"Bearer token /apis/batch/v1/namespaces/pega/jobs/pega-db-install",
+	}, api.requests)
+}
+
+func TestWaitForJobNotCreatedYet(t *testing.T) {
+	client, api := newFakeClient(t, map[string][]string{})
+	var sleeps int
+	sleep := func(time.Duration) {
+		if sleeps++; sleeps == 2 {
+			api.objects["/apis/batch/v1/namespaces/pega/jobs/pega-zdt-upgrade"] = []string{`{"status": {"succeeded": 1}}`}
+		}
+	}
+	require.NoError(t, waitForJob(client, "pega", []string{"job", "pega-zdt-upgrade"}, time.Second, sleep))
+	require.Len(t, api.requests, 3)
+}
+
+func TestWaitForJobFailures(t *testing.T) {
+	client, _ := newFakeClient(t, map[string][]string{
+		"/apis/batch/v1/namespaces/pega/jobs/pega-db-install":  {`{"status": {"failed": 1}}`},
+		"/apis/batch/v1/namespaces/pega/jobs/pega-pre-upgrade": {"forbidden"},
+	})
+	require.EqualError(t, waitForJob(client, "pega", []string{"job", "pega-db-install"}, time.Second, noSleep),
+		"job pega-db-install failed")
+	require.EqualError(t, waitForJob(client, "pega", []string{"job", "pega-pre-upgrade"}, time.Second, noSleep),
+		`get job pega-pre-upgrade: 403 Forbidden: jobs.batch "pega-db-install" is forbidden`)
+	require.EqualError(t, waitForJob(client, "pega", []string{"pod", "pega-web"}, time.Second, noSleep),
+		"the stub only waits for jobs: job <name>, got pod pega-web")
+}
+
+func deploymentJSON(generation int64, observed int64, replicas int32, updated int32, available int32) string {
+	var w workload
+	w.Metadata.Name = "pega-web"
+	w.Metadata.Generation = generation
+	w.Spec.Replicas = &replicas
+	w.Status.ObservedGeneration = observed
+	w.Status.Replicas = replicas
+	w.Status.UpdatedReplicas = updated
+	w.Status.AvailableReplicas = available
+	object, _ := json.Marshal(w)
+	return string(object)
+}
+
+func TestRolloutStatusWatchesUntilRolledOut(t *testing.T) {
+	listPath := "/apis/apps/v1/namespaces/pega/deployments"
+	client, api := newFakeClient(t, map[string][]string{
+		listPath: {`{"metadata": {"resourceVersion": "42"}, "items": [` + deploymentJSON(2, 1, 1, 0, 1) + `]}`},
+		listPath + "?watch": {
+			`{"type": "MODIFIED", "object": ` + deploymentJSON(2, 2, 1, 1, 0) + `}` + "\n" +
+				`{"type": "MODIFIED", "object": ` + deploymentJSON(2, 2, 1, 1, 1) + `}` + "\n",
+		},
+	})
+	require.NoError(t, rolloutStatus(client, []string{"deployment/pega-web", "--namespace", "pega"}))
+	require.Equal(t, []string{
+		"Bearer token " + listPath + "?fieldSelector=metadata.name%3Dpega-web",
+		"Bearer token " + listPath + "?fieldSelector=metadata.name%3Dpega-web&watch=true&resourceVersion=42",
+	}, api.requests)
+}
+
+func TestRolloutStatusStatefulSet(t *testing.T) {
+	client, _ := newFakeClient(t, map[string][]string{
+		"/apis/apps/v1/namespaces/pega/statefulsets": {`{"items": [{"metadata": {"name": "pega-stream", "generation": 3},
+			"spec": {"replicas": 2}, "status": {"observedGeneration": 3, "replicas": 2, "updatedReplicas": 2,
+			"readyReplicas": 2, "currentRevision": "pega-stream-2", "updateRevision": "pega-stream-2"}}]}`},
+	})
+	require.NoError(t, rolloutStatus(client, []string{"statefulset/pega-stream", "--namespace=pega"}))
+}
+
+func TestRolloutStatusErrors(t *testing.T) {
+	client, _ := newFakeClient(t, map[string][]string{
+		"/apis/apps/v1/namespaces/pega/deployments": {`{"items": []}`},
+	})
+	require.EqualError(t, rolloutStatus(client, []string{"deployment/pega-web", "-n", "pega"}), "deployment/pega-web not found")
+	require.EqualError(t, rolloutStatus(client, []string{"daemonset/pega-web", "-n", "pega"}),
+		`the stub only supports rollout status deployment/<name> or statefulset/<name>, got "daemonset/pega-web"`)
+	require.EqualError(t, rolloutStatus(client, []string{"deployment/pega-web", "--watch=false"}), "unsupported argument --watch=false")
+}
@@ -0,0 +1,169 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/k8s"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+func client(t *testing.T, options *k8s.KubectlOptions) *kubernetes.Clientset {
+	clientset, err := k8s.GetKubernetesClientFromOptionsE(t, options)
+	require.NoError(t, err)
+	return clientset
+}
+
+// WaitUntilDeploymentReady waits for every replica of a Deployment to be updated and ready
+func WaitUntilDeploymentReady(t *testing.T, options *k8s.KubectlOptions, name string, retries int, sleepBetweenRetries time.Duration) {
+	clientset := client(t, options)
+	retry.DoWithRetry(t, "Deployment "+name+" ready", retries, sleepBetweenRetries, func() (string, error) {
+		deployment, err := clientset.AppsV1().Deployments(options.Namespace).Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil {
+			return "", err
+		}
+		status := deployment.Status
+		if deployment.Spec.Replicas == nil || status.UpdatedReplicas != *deployment.Spec.Replicas || status.ReadyReplicas != *deployment.Spec.Replicas {
+			return "", fmt.Errorf("%d of %d replicas ready", status.ReadyReplicas, status.Replicas)
+		}
+		return "", nil
+	})
+}
+
+// WaitUntilStatefulSetReady waits for every replica of a StatefulSet to be ready
+func WaitUntilStatefulSetReady(t *testing.T, options *k8s.KubectlOptions, name string, retries int, sleepBetweenRetries time.Duration) {
+	clientset := client(t, options)
+	retry.DoWithRetry(t, "StatefulSet "+name+" ready", retries, sleepBetweenRetries, func() (string, error) {
+		statefulSet, err := clientset.AppsV1().StatefulSets(options.Namespace).Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil {
+			return "", err
+		}
+		if statefulSet.Spec.Replicas == nil || statefulSet.Status.ReadyReplicas != *statefulSet.Spec.Replicas {
+			return "", fmt.Errorf("%d of %d replicas ready", statefulSet.Status.ReadyReplicas, statefulSet.Status.Replicas)
+		}
+		return "", nil
+	})
+}
+
+// WaitUntilJobSucceeded waits for a Job to complete, failing at once when it fails
+func WaitUntilJobSucceeded(t *testing.T, options *k8s.KubectlOptions, name string, retries int, sleepBetweenRetries time.Duration) {
+	clientset := client(t, options)
+	retry.DoWithRetry(t, "Job "+name+" succeeded", retries, sleepBetweenRetries, func() (string, error) {
+		job, err := clientset.BatchV1().Jobs(options.Namespace).Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil {
+			return "", err
+		}
+		require.Zero(t, job.Status.Failed, "Job %s failed", name)
+		if job.Status.Succeeded == 0 {
+			return "", fmt.Errorf("Job %s has not completed", name)
+		}
+		return "", nil
+	})
+}
+
+// WaitUntilJobFailed waits for a Job to fail, failing at once when it succeeds
+func WaitUntilJobFailed(t *testing.T, options *k8s.KubectlOptions, name string, retries int, sleepBetweenRetries time.Duration) {
+	clientset := client(t, options)
+	retry.DoWithRetry(t, "Job "+name+" failed", retries, sleepBetweenRetries, func() (string, error) {
+		job, err := clientset.BatchV1().Jobs(options.Namespace).Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil {
+			return "", err
+		}
+		require.Zero(t, job.Status.Succeeded, "Job %s succeeded", name)
+		if job.Status.Failed == 0 {
+			return "", fmt.Errorf("Job %s has not failed", name)
+		}
+		return "", nil
+	})
+}
+
+// WaitUntilServiceHasEndpoints waits for a Service to route to at least one ready pod
+func WaitUntilServiceHasEndpoints(t *testing.T, options *k8s.KubectlOptions, name string, retries int, sleepBetweenRetries time.Duration) {
+	clientset := client(t, options)
+	retry.DoWithRetry(t, "Service "+name+" endpoints", retries, sleepBetweenRetries, func() (string, error) {
+		endpoints, err := clientset.CoreV1().Endpoints(options.Namespace).Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil {
+			return "", err
+		}
+		for _, subset := range endpoints.Subsets {
+			if len(subset.Addresses) > 0 {
+				return "", nil
+			}
+		}
+		return "", fmt.Errorf("Service %s has no ready endpoints", name)
+	})
+}
+
+// ListAppPods returns the pods labelled with app, the label the Pega chart puts on the pods of every tier
+func ListAppPods(t *testing.T, options *k8s.KubectlOptions, app string) []corev1.Pod {
+	return k8s.ListPods(t, options, metav1.ListOptions{LabelSelector: "app=" + app})
+}
+
+// InitContainerState returns the state of an init container of pod, failing the test when the pod has none by that
+// name or it has not been created yet
+func InitContainerState(t *testing.T, pod corev1.Pod, name string) corev1.ContainerState {
+	for _, status := range pod.Status.InitContainerStatuses {
+		if status.Name == name {
+			return status.State
+		}
+	}
+	require.Failf(t, "init container not found", "pod %s has no status for init container %s", pod.Name, name)
+	return corev1.ContainerState{}
+}
+
+// WaitUntilInitContainerRunning waits for every pod of app to be running the init container name, which means the
+// init containers before it have completed
+func WaitUntilInitContainerRunning(t *testing.T, options *k8s.KubectlOptions, app string, name string, retries int, sleepBetweenRetries time.Duration) {
+	waitUntilInitContainerRunning(t, options, "app="+app, name, retries, sleepBetweenRetries)
+}
+
+// WaitUntilInstallerInitContainerRunning waits for the pod of the installer Job job to be running the init container
+// name
+func WaitUntilInstallerInitContainerRunning(t *testing.T, options *k8s.KubectlOptions, job string, name string, retries int, sleepBetweenRetries time.Duration) {
+	waitUntilInitContainerRunning(t, options, "installer-job="+job, name, retries, sleepBetweenRetries)
+}
+
+func waitUntilInitContainerRunning(t *testing.T, options *k8s.KubectlOptions, selector string, name string, retries int, sleepBetweenRetries time.Duration) {
+	retry.DoWithRetry(t, selector+" running "+name, retries, sleepBetweenRetries, func() (string, error) {
+		pods := k8s.ListPods(t, options, metav1.ListOptions{LabelSelector: selector})
+		if len(pods) == 0 {
+			return "", fmt.Errorf("no pods with %s", selector)
+		}
+		for _, pod := range pods {
+			running := false
+			for _, status := range pod.Status.InitContainerStatuses {
+				running = running || (status.Name == name && status.State.Running != nil)
+			}
+			if !running {
+				return "", fmt.Errorf("pod %s is not running %s", pod.Name, name)
+			}
+		}
+		return "", nil
+	})
+}
+
+// RequireInitContainerKeepsRunning checks times, sleepBetweenChecks apart, that every pod of app running the init
+// container name is still blocked in it, for the tests showing that a wait-for container does not let go too early
+func RequireInitContainerKeepsRunning(t *testing.T, options *k8s.KubectlOptions, app string, name string, checks int, sleepBetweenChecks time.Duration) {
+	for check := 0; check < checks; check++ {
+		time.Sleep(sleepBetweenChecks)
+		waiting := 0
+		for _, pod := range ListAppPods(t, options, app) {
+			for _, status := range pod.Status.InitContainerStatuses {
+				if status.Name == name {
+					require.NotNil(t, status.State.Running, "%s stopped waiting in %s", pod.Name, name)
+					waiting++
+				}
+			}
+		}
+		require.NotZero(t, waiting, "no pods of %s run %s", app, name)
+	}
+}
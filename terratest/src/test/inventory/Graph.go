@@ -0,0 +1,264 @@
+package inventory
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+	"sigs.k8s.io/yaml"
+)
+
+// Node is a rendered object, or an object only known because a rendered one references it
+type Node struct {
+	// ID is Kind/name for objects in the release namespace and cluster scoped objects, and Kind/namespace/name for
+	// objects in other namespaces
+	ID        string `json:"id"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Source    string `json:"source,omitempty"`
+	Rendered  bool   `json:"rendered"`
+	// External objects are expected to exist in the cluster without being rendered, because they are declared in
+	// Options.External or always exist, like the default ServiceAccount
+	External bool `json:"external,omitempty"`
+}
+
+// Edge is a reference from one object to another
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Type tells how the object is referenced, e.g. volume, projected-volume, env or backend
+	Type string `json:"type"`
+	// Path is the location of the reference in the referencing object, e.g. spec.template.spec.volumes[1]
+	Path string `json:"path"`
+	// Optional references, such as a volume of an optional Secret, do not need the object to exist
+	Optional bool `json:"optional,omitempty"`
+}
+
+// Graph is the inventory of a rendered chart: every object and the references between them
+type Graph struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+// Node returns the node with the given ID, or nil
+func (g *Graph) Node(id string) *Node {
+	for i := range g.Nodes {
+		if g.Nodes[i].ID == id {
+			return &g.Nodes[i]
+		}
+	}
+	return nil
+}
+
+// References lists the references of the object id, e.g. the Secrets and ConfigMaps a tier mounts
+func (g *Graph) References(id string) []Edge {
+	var edges []Edge
+	for _, edge := range g.Edges {
+		if edge.From == id {
+			edges = append(edges, edge)
+		}
+	}
+	return edges
+}
+
+// ReferencedBy lists the references to the object id, e.g. every workload mounting pega-db-secret
+func (g *Graph) ReferencedBy(id string) []Edge {
+	var edges []Edge
+	for _, edge := range g.Edges {
+		if edge.To == id {
+			edges = append(edges, edge)
+		}
+	}
+	return edges
+}
+
+// Dangling lists the references to objects that are neither rendered nor external. Such a reference keeps pods from
+// starting, or leaves a Service, Ingress or autoscaler without a target.
+func (g *Graph) Dangling() []Edge {
+	var dangling []Edge
+	for _, edge := range g.Edges {
+		if node := g.Node(edge.To); !edge.Optional && node != nil && !node.Rendered && !node.External {
+			dangling = append(dangling, edge)
+		}
+	}
+	return dangling
+}
+
+// Neighborhood returns the part of the graph around the object id: the object, what it references and what
+// references it
+func (g *Graph) Neighborhood(id string) *Graph {
+	neighborhood := &Graph{Nodes: []Node{}, Edges: []Edge{}}
+	ids := map[string]bool{id: true}
+	for _, edge := range g.Edges {
+		if edge.From == id || edge.To == id {
+			neighborhood.Edges = append(neighborhood.Edges, edge)
+			ids[edge.From], ids[edge.To] = true, true
+		}
+	}
+	for _, node := range g.Nodes {
+		if ids[node.ID] {
+			neighborhood.Nodes = append(neighborhood.Nodes, node)
+		}
+	}
+	return neighborhood
+}
+
+// Options control the rendering of the chart the inventory is built from
+type Options struct {
+	// ChartPath is a chart directory or packaged chart, the pega chart of this repository by default
+	ChartPath   string
+	ValuesFiles []string
+	SetValues   map[string]string
+	ReleaseName string
+	Namespace   string
+	KubeVersion string
+	// External declares the objects created outside of the chart, as Kind/name, e.g. Secret/my-db-credentials
+	External []string
+}
+
+// Build renders the chart and returns its inventory
+func Build(options Options) (*Graph, error) {
+	rendered, err := helmtest.CommandRender{
+		ChartPath:   options.ChartPath,
+		ReleaseName: options.ReleaseName,
+		Namespace:   options.Namespace,
+		KubeVersion: options.KubeVersion,
+		ValuesFiles: options.ValuesFiles,
+		SetValues:   options.SetValues,
+	}.RenderE()
+	if err != nil {
+		return nil, err
+	}
+	return FromManifests(rendered, options.Namespace, options.External)
+}
+
+// implicitObjects exist in every namespace without being rendered
+var implicitObjects = []string{"ServiceAccount/default", "ConfigMap/kube-root-ca.crt"}
+
+// clusterScopedKinds are the kinds without a namespace the charts render or reference
+var clusterScopedKinds = map[string]bool{
+	"ClusterRole":        true,
+	"ClusterRoleBinding": true,
+	"IngressClass":       true,
+	"StorageClass":       true,
+	"PriorityClass":      true,
+	"Namespace":          true,
+}
+
+// FromManifests builds the inventory of rendered manifests. Objects without a namespace are placed in
+// releaseNamespace. external lists the objects created outside of the chart as Kind/name.
+func FromManifests(rendered string, releaseNamespace string, external []string) (*Graph, error) {
+	if releaseNamespace == "" {
+		releaseNamespace = "default"
+	}
+	for _, declared := range external {
+		if parts := strings.Split(declared, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("external object %q is not in the Kind/name form", declared)
+		}
+	}
+	parser, err := helmtest.NewHelmChartParserE(nil, rendered, releaseNamespace)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := parser.QueryE(helmtest.ResourceQuery{})
+	if err != nil {
+		return nil, err
+	}
+
+	builder := &graphBuilder{
+		releaseNamespace: releaseNamespace,
+		external:         append(append([]string{}, implicitObjects...), external...),
+		nodes:            map[string]*Node{},
+	}
+	var objects []renderedObject
+	for _, resource := range resources {
+		object := map[string]interface{}{}
+		if err := yaml.Unmarshal([]byte(resource.YAML), &object); err != nil {
+			return nil, fmt.Errorf("decoding rendered %s/%s: %w", resource.Kind, resource.Name, err)
+		}
+		namespace := resource.Namespace
+		if clusterScopedKinds[resource.Kind] {
+			namespace = ""
+		}
+		id := builder.id(resource.Kind, namespace, resource.Name)
+		if node, exists := builder.nodes[id]; exists && node.Rendered {
+			return nil, fmt.Errorf("%s is rendered more than once", id)
+		}
+		builder.nodes[id] = &Node{ID: id, Kind: resource.Kind, Name: resource.Name, Namespace: namespace, Source: resource.Source, Rendered: true}
+		objects = append(objects, renderedObject{id: id, kind: resource.Kind, namespace: namespace, object: object})
+	}
+	for _, object := range objects {
+		if err := builder.addReferences(object, objects); err != nil {
+			return nil, fmt.Errorf("%s: %w", object.id, err)
+		}
+	}
+	return builder.graph(), nil
+}
+
+// renderedObject is a rendered resource in the generic form the references are read from
+type renderedObject struct {
+	id        string
+	kind      string
+	namespace string
+	object    map[string]interface{}
+}
+
+type graphBuilder struct {
+	releaseNamespace string
+	external         []string
+	nodes            map[string]*Node
+	edges            []Edge
+}
+
+func (b *graphBuilder) id(kind string, namespace string, name string) string {
+	if namespace == "" || namespace == b.releaseNamespace {
+		return kind + "/" + name
+	}
+	return kind + "/" + namespace + "/" + name
+}
+
+// reference adds an edge from the object to the one of kind and name in namespace, adding a node for the target when
+// it is not rendered
+func (b *graphBuilder) reference(from renderedObject, kind string, namespace string, name string, edgeType string, path string, optional bool) {
+	if name == "" {
+		return
+	}
+	if clusterScopedKinds[kind] {
+		namespace = ""
+	} else if namespace == "" {
+		namespace = from.namespace
+	}
+	id := b.id(kind, namespace, name)
+	if _, exists := b.nodes[id]; !exists {
+		b.nodes[id] = &Node{ID: id, Kind: kind, Name: name, Namespace: namespace, External: b.isExternal(kind, name)}
+	}
+	b.edges = append(b.edges, Edge{From: from.id, To: id, Type: edgeType, Path: path, Optional: optional})
+}
+
+func (b *graphBuilder) isExternal(kind string, name string) bool {
+	for _, external := range b.external {
+		if external == kind+"/"+name {
+			return true
+		}
+	}
+	return false
+}
+
+// graph returns the nodes sorted by ID and the edges in a stable order
+func (b *graphBuilder) graph() *Graph {
+	graph := &Graph{Nodes: []Node{}, Edges: []Edge{}}
+	for _, node := range b.nodes {
+		graph.Nodes = append(graph.Nodes, *node)
+	}
+	sort.Slice(graph.Nodes, func(i, j int) bool { return graph.Nodes[i].ID < graph.Nodes[j].ID })
+	graph.Edges = append(graph.Edges, b.edges...)
+	sort.SliceStable(graph.Edges, func(i, j int) bool {
+		if graph.Edges[i].From != graph.Edges[j].From {
+			return graph.Edges[i].From < graph.Edges[j].From
+		}
+		return graph.Edges[i].Path < graph.Edges[j].Path
+	})
+	return graph
+}
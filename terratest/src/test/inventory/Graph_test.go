@@ -0,0 +1,304 @@
+package inventory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const rendered = `---
+# Source: pega/templates/pega-tier-deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: pega-web
+spec:
+  template:
+    metadata:
+      labels:
+        app: pega-web
+    spec:
+      serviceAccountName: pega
+      imagePullSecrets:
+      - name: pega-registry-secret
+      initContainers:
+      - name: wait-for-pegasearch
+        env:
+        - name: TOKEN
+          valueFrom:
+            secretKeyRef:
+              name: optional-token
+              key: token
+              optional: true
+      containers:
+      - name: pega-web-tomcat
+        envFrom:
+        - configMapRef:
+            name: pega-environment-config
+        env:
+        - name: DB_PASSWORD
+          valueFrom:
+            secretKeyRef:
+              name: external-db-credentials
+              key: password
+      volumes:
+      - name: pega-web-config
+        configMap:
+          name: pega-web
+      - name: pega-volume-credentials
+        projected:
+          sources:
+          - secret:
+              name: pega-db-secret
+          - secret:
+              name: pega-certificates
+      - name: pega-storage
+        persistentVolumeClaim:
+          claimName: pega-shared-storage
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: pega-web
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: pega-environment-config
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: pega-db-secret
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: pega-registry-secret
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: pega
+---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: pega-shared-storage
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: pega-web
+  annotations:
+    cloud.google.com/backend-config: '{"ports": {"80": "pega-web"}}'
+spec:
+  selector:
+    app: pega-web
+---
+apiVersion: cloud.google.com/v1
+kind: BackendConfig
+metadata:
+  name: pega-web
+---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: pega-web
+  annotations:
+    networking.gke.io/managed-certificates: managed-certificate-web
+spec:
+  tls:
+  - secretName: pega-web-tls
+  rules:
+  - http:
+      paths:
+      - backend:
+          service:
+            name: pega-web
+---
+apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: pega-web-hpa
+spec:
+  scaleTargetRef:
+    kind: Deployment
+    name: pega-web
+---
+apiVersion: policy/v1
+kind: PodDisruptionBudget
+metadata:
+  name: pega-web-pdb
+spec:
+  selector:
+    matchLabels:
+      app: pega-web
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: check-installer-status
+roleRef:
+  kind: Role
+  name: jobs-reader
+subjects:
+- kind: ServiceAccount
+  name: default
+- kind: ServiceAccount
+  name: builder
+  namespace: ci
+`
+
+func TestFromManifestsReferences(t *testing.T) {
+	graph, err := FromManifests(rendered, "pega", nil)
+	require.NoError(t, err)
+
+	require.ElementsMatch(t, []Edge{
+		{From: "Deployment/pega-web", To: "ServiceAccount/pega", Type: "service-account", Path: "spec.template.spec.serviceAccountName"},
+		{From: "Deployment/pega-web", To: "Secret/pega-registry-secret", Type: "image-pull-secret", Path: "spec.template.spec.imagePullSecrets[0]"},
+		{From: "Deployment/pega-web", To: "ConfigMap/pega-web", Type: "volume", Path: "spec.template.spec.volumes[0]"},
+		{From: "Deployment/pega-web", To: "Secret/pega-db-secret", Type: "projected-volume", Path: "spec.template.spec.volumes[1].projected.sources[0]"},
+		{From: "Deployment/pega-web", To: "Secret/pega-certificates", Type: "projected-volume", Path: "spec.template.spec.volumes[1].projected.sources[1]"},
+		{From: "Deployment/pega-web", To: "PersistentVolumeClaim/pega-shared-storage", Type: "volume", Path: "spec.template.spec.volumes[2]"},
+		{From: "Deployment/pega-web", To: "Secret/optional-token", Type: "env", Path: "spec.template.spec.initContainers[0].env[0]", Optional: true},
+		{From: "Deployment/pega-web", To: "ConfigMap/pega-environment-config", Type: "env-from", Path: "spec.template.spec.containers[0].envFrom[0]"},
+		{From: "Deployment/pega-web", To: "Secret/external-db-credentials", Type: "env", Path: "spec.template.spec.containers[0].env[0]"},
+	}, graph.References("Deployment/pega-web"))
+
+	require.ElementsMatch(t, []Edge{
+		{From: "Service/pega-web", To: "Deployment/pega-web", Type: "selects", Path: "spec.selector"},
+		{From: "Service/pega-web", To: "BackendConfig/pega-web", Type: "backend-config", Path: "metadata.annotations.cloud.google.com/backend-config"},
+	}, graph.References("Service/pega-web"))
+	require.ElementsMatch(t, []Edge{
+		{From: "Ingress/pega-web", To: "Service/pega-web", Type: "backend", Path: "spec.rules[0].http.paths[0].backend"},
+		{From: "Ingress/pega-web", To: "Secret/pega-web-tls", Type: "tls", Path: "spec.tls[0]"},
+		{From: "Ingress/pega-web", To: "ManagedCertificate/managed-certificate-web", Type: "managed-certificate", Path: "metadata.annotations.networking.gke.io/managed-certificates"},
+	}, graph.References("Ingress/pega-web"))
+	require.Equal(t, []Edge{
+		{From: "HorizontalPodAutoscaler/pega-web-hpa", To: "Deployment/pega-web", Type: "scale-target", Path: "spec.scaleTargetRef"},
+	}, graph.References("HorizontalPodAutoscaler/pega-web-hpa"))
+	require.Equal(t, []Edge{
+		{From: "PodDisruptionBudget/pega-web-pdb", To: "Deployment/pega-web", Type: "selects", Path: "spec.selector"},
+	}, graph.References("PodDisruptionBudget/pega-web-pdb"))
+	require.Equal(t, []Edge{
+		{From: "RoleBinding/check-installer-status", To: "Role/jobs-reader", Type: "role-ref", Path: "roleRef"},
+		{From: "RoleBinding/check-installer-status", To: "ServiceAccount/default", Type: "subject", Path: "subjects[0]"},
+		{From: "RoleBinding/check-installer-status", To: "ServiceAccount/ci/builder", Type: "subject", Path: "subjects[1]"},
+	}, graph.References("RoleBinding/check-installer-status"))
+
+	require.Equal(t, &Node{ID: "ServiceAccount/ci/builder", Kind: "ServiceAccount", Name: "builder", Namespace: "ci"}, graph.Node("ServiceAccount/ci/builder"))
+	require.Equal(t, &Node{ID: "ServiceAccount/default", Kind: "ServiceAccount", Name: "default", Namespace: "pega", External: true}, graph.Node("ServiceAccount/default"))
+	require.Len(t, graph.ReferencedBy("Deployment/pega-web"), 3)
+}
+
+func TestDangling(t *testing.T) {
+	graph, err := FromManifests(rendered, "pega", nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"Deployment/pega-web -> Secret/external-db-credentials",
+		"Deployment/pega-web -> Secret/pega-certificates",
+		"Ingress/pega-web -> ManagedCertificate/managed-certificate-web",
+		"Ingress/pega-web -> Secret/pega-web-tls",
+		"RoleBinding/check-installer-status -> Role/jobs-reader",
+		"RoleBinding/check-installer-status -> ServiceAccount/ci/builder",
+	}, danglingTargets(graph))
+	require.Contains(t, DescribeDangling(graph),
+		"Deployment/pega-web references Secret/pega-certificates, which is not rendered (projected-volume at spec.template.spec.volumes[1].projected.sources[1])")
+
+	graph, err = FromManifests(rendered, "pega", []string{
+		"Secret/pega-certificates", "Secret/external-db-credentials", "Secret/pega-web-tls",
+		"ManagedCertificate/managed-certificate-web", "Role/jobs-reader", "ServiceAccount/builder",
+	})
+	require.NoError(t, err)
+	require.Empty(t, danglingTargets(graph))
+	require.True(t, graph.Node("Secret/pega-certificates").External)
+	require.False(t, graph.Node("Secret/optional-token").External)
+}
+
+func TestFromManifestsErrors(t *testing.T) {
+	_, err := FromManifests(rendered, "pega", []string{"pega-db-secret"})
+	require.EqualError(t, err, `external object "pega-db-secret" is not in the Kind/name form`)
+
+	_, err = FromManifests(rendered+"---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: pega-web\n", "pega", nil)
+	require.EqualError(t, err, "ConfigMap/pega-web is rendered more than once")
+}
+
+func TestNeighborhood(t *testing.T) {
+	graph, err := FromManifests(rendered, "pega", nil)
+	require.NoError(t, err)
+	neighborhood := graph.Neighborhood("Secret/pega-db-secret")
+	require.Equal(t, []Edge{
+		{From: "Deployment/pega-web", To: "Secret/pega-db-secret", Type: "projected-volume", Path: "spec.template.spec.volumes[1].projected.sources[0]"},
+	}, neighborhood.Edges)
+	require.Len(t, neighborhood.Nodes, 2)
+}
+
+func TestDOT(t *testing.T) {
+	graph, err := FromManifests(rendered, "pega", []string{"Secret/external-db-credentials"})
+	require.NoError(t, err)
+	dot := DOT(graph.Neighborhood("Deployment/pega-web"))
+	require.Contains(t, dot, "digraph inventory {\n  rankdir=LR;\n")
+	require.Contains(t, dot, `  "Deployment/pega-web" [label="Deployment\npega-web", shape=box];`)
+	require.Contains(t, dot, `  "Secret/external-db-credentials" [label="Secret\nexternal-db-credentials", shape=ellipse, style=dashed];`)
+	require.Contains(t, dot, `  "Secret/pega-certificates" [label="Secret\npega-certificates", shape=ellipse, color=red, fontcolor=red];`)
+	require.Contains(t, dot, `  "Deployment/pega-web" -> "Secret/pega-db-secret" [label="projected-volume"];`)
+	require.Contains(t, dot, `  "HorizontalPodAutoscaler/pega-web-hpa" -> "Deployment/pega-web" [label="scale-target"];`)
+}
+
+func TestBuildPegaChart(t *testing.T) {
+	deploy := map[string]string{"global.provider": "k8s", "global.actions.execute": "deploy"}
+	graph, err := Build(Options{SetValues: deploy, Namespace: "pega"})
+	require.NoError(t, err)
+	require.Empty(t, graph.Dangling())
+	for _, tier := range []string{"Deployment/pega-web", "Deployment/pega-batch", "StatefulSet/pega-stream"} {
+		require.Contains(t, graph.References(tier),
+			Edge{From: tier, To: "Secret/pega-db-secret", Type: "projected-volume", Path: "spec.template.spec.volumes[1].projected.sources[0]"})
+	}
+	require.Contains(t, graph.ReferencedBy("Deployment/pega-web"),
+		Edge{From: "HorizontalPodAutoscaler/pega-web-hpa", To: "Deployment/pega-web", Type: "scale-target", Path: "spec.scaleTargetRef"})
+	require.Contains(t, graph.ReferencedBy("Service/pega-web"),
+		Edge{From: "Ingress/pega-web", To: "Service/pega-web", Type: "backend", Path: "spec.rules[0].http.paths[0].backend"})
+}
+
+func TestBuildPegaChartWithExternalSecrets(t *testing.T) {
+	options := Options{
+		ValuesFiles: []string{"../pega/data/values_with_externalcerts.yaml"},
+		SetValues:   map[string]string{"global.provider": "k8s", "global.actions.execute": "deploy"},
+	}
+	graph, err := Build(options)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"Deployment/pega-batch -> Secret/secret-to-be-created1",
+		"Deployment/pega-batch -> Secret/secret-to-be-created2",
+		"Deployment/pega-web -> Secret/secret-to-be-created1",
+		"Deployment/pega-web -> Secret/secret-to-be-created2",
+		"StatefulSet/pega-stream -> Secret/secret-to-be-created1",
+		"StatefulSet/pega-stream -> Secret/secret-to-be-created2",
+	}, danglingTargets(graph))
+
+	options.External = []string{"Secret/secret-to-be-created1", "Secret/secret-to-be-created2"}
+	graph, err = Build(options)
+	require.NoError(t, err)
+	require.Empty(t, graph.Dangling())
+}
+
+func TestBuildPegaChartOnGKE(t *testing.T) {
+	graph, err := Build(Options{
+		ValuesFiles: []string{"../pega/data/values_gke_managedcertificate.yaml"},
+		SetValues:   map[string]string{"global.provider": "gke", "global.actions.execute": "deploy"},
+	})
+	require.NoError(t, err)
+	require.Empty(t, graph.Dangling())
+	require.Contains(t, graph.References("Service/pega-web"),
+		Edge{From: "Service/pega-web", To: "BackendConfig/pega-web", Type: "backend-config", Path: "metadata.annotations.cloud.google.com/backend-config"})
+	require.True(t, graph.Node("BackendConfig/pega-web").Rendered)
+}
+
+func danglingTargets(graph *Graph) []string {
+	var targets []string
+	for _, edge := range graph.Dangling() {
+		targets = append(targets, edge.From+" -> "+edge.To)
+	}
+	return targets
+}
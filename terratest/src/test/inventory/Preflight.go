@@ -0,0 +1,211 @@
+package inventory
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// preflightKinds are the kinds a pod cannot start without, and that are usually created outside of the chart
+var preflightKinds = map[string]bool{
+	"Secret":                true,
+	"ConfigMap":             true,
+	"ServiceAccount":        true,
+	"PersistentVolumeClaim": true,
+}
+
+// resourceKinds maps the resource names printed by `kubectl get -o name` to kinds
+var resourceKinds = map[string]string{
+	"secret":                "Secret",
+	"configmap":             "ConfigMap",
+	"serviceaccount":        "ServiceAccount",
+	"persistentvolumeclaim": "PersistentVolumeClaim",
+}
+
+// ClusterObjects are the objects found in the cluster before the chart is installed, by node ID
+type ClusterObjects map[string]bool
+
+// LoadClusterObjects reads the objects existing in namespace from a file, which holds either the output of
+// `kubectl get secrets,configmaps,serviceaccounts,pvc -o yaml` (or -o json), or one object per line as Kind/name or
+// the resource/name form of `kubectl get -o name`. Blank lines and lines starting with # are ignored.
+func LoadClusterObjects(path string, namespace string) (ClusterObjects, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	objects := ClusterObjects{}
+	if err := objects.add(content, namespace); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return objects, nil
+}
+
+func (o ClusterObjects) add(content []byte, namespace string) error {
+	if namespace == "" {
+		namespace = "default"
+	}
+	builder := &graphBuilder{releaseNamespace: namespace}
+	trimmed := bytes.TrimSpace(content)
+	if isDocument(trimmed) {
+		var list struct {
+			Items []struct {
+				Kind     string `json:"kind"`
+				Metadata struct {
+					Name      string `json:"name"`
+					Namespace string `json:"namespace"`
+				} `json:"metadata"`
+			} `json:"items"`
+		}
+		if err := yaml.Unmarshal(trimmed, &list); err != nil {
+			return err
+		}
+		for _, item := range list.Items {
+			if item.Kind == "" || item.Metadata.Name == "" {
+				return fmt.Errorf("an item of the list has no kind or name")
+			}
+			o[builder.id(item.Kind, item.Metadata.Namespace, item.Metadata.Name)] = true
+		}
+		return nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(trimmed))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		parts := strings.Split(text, "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return fmt.Errorf("line %d: %q is not in the Kind/name form", line, text)
+		}
+		kind := parts[0]
+		if resourceKind, ok := resourceKinds[strings.SplitN(kind, ".", 2)[0]]; ok {
+			kind = resourceKind
+		}
+		o[builder.id(kind, "", parts[1])] = true
+	}
+	return scanner.Err()
+}
+
+// Unresolved is a reference of a rendered workload to an object that neither the chart nor the cluster provides
+type Unresolved struct {
+	Edge
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// ValueKeys are the keys of the values files set to the name of the object, e.g. global.jdbc.external_secret_name
+	ValueKeys []string `json:"valueKeys,omitempty"`
+}
+
+// Preflight reports the references to Secrets, ConfigMaps, ServiceAccounts and PersistentVolumeClaims that will not
+// resolve once the chart is installed: the object is not rendered, does not exist in the cluster and is not one of
+// the objects every namespace has. Objects declared external when the graph was built are checked as well, since
+// the point of the check is to make sure they exist. values are the values the chart was rendered with, when known,
+// to tell which key named the missing object.
+func Preflight(graph *Graph, existing ClusterObjects, values ...map[string]interface{}) []Unresolved {
+	var unresolved []Unresolved
+	for _, edge := range graph.Edges {
+		node := graph.Node(edge.To)
+		if node == nil || node.Rendered || edge.Optional || !preflightKinds[node.Kind] || existing[node.ID] || isImplicit(node) {
+			continue
+		}
+		var keys []string
+		for _, valuesMap := range values {
+			keys = append(keys, valueKeys("", valuesMap, node.Name)...)
+		}
+		sort.Strings(keys)
+		unresolved = append(unresolved, Unresolved{Edge: edge, Kind: node.Kind, Name: node.Name, ValueKeys: dedupe(keys)})
+	}
+	return unresolved
+}
+
+// DescribeUnresolved lists the unresolved references one per line, for the command output
+func DescribeUnresolved(unresolved []Unresolved) string {
+	var lines []string
+	for _, reference := range unresolved {
+		line := fmt.Sprintf("%s references %s, which neither the chart nor the cluster provides (%s at %s)",
+			reference.From, reference.To, reference.Type, reference.Path)
+		if len(reference.ValueKeys) > 0 {
+			line += "; it is named by " + strings.Join(reference.ValueKeys, ", ")
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// LoadValues reads a values file for Preflight
+func LoadValues(path string) (map[string]interface{}, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]interface{}{}
+	if err := yaml.Unmarshal(content, &values); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return values, nil
+}
+
+// isDocument tells a YAML or JSON document from a list of names by its first line: a name cannot contain a colon
+func isDocument(content []byte) bool {
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return strings.HasPrefix(line, "{") || strings.Contains(line, ":")
+		}
+	}
+	return false
+}
+
+func isImplicit(node *Node) bool {
+	for _, implicit := range implicitObjects {
+		if node.ID == implicit {
+			return true
+		}
+	}
+	return false
+}
+
+// valueKeys returns the keys of values set to name, with the index of list items, e.g. global.tier[0].service.tls.external_secret_names[1]
+func valueKeys(path string, value interface{}, name string) []string {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		var keys []string
+		for key, item := range value {
+			keys = append(keys, valueKeys(joinPath(path, key), item, name)...)
+		}
+		return keys
+	case []interface{}:
+		var keys []string
+		for i, item := range value {
+			keys = append(keys, valueKeys(fmt.Sprintf("%s[%d]", path, i), item, name)...)
+		}
+		return keys
+	case string:
+		if value == name {
+			return []string{path}
+		}
+	}
+	return nil
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func dedupe(sorted []string) []string {
+	var result []string
+	for i, value := range sorted {
+		if i == 0 || value != sorted[i-1] {
+			result = append(result, value)
+		}
+	}
+	return result
+}
@@ -0,0 +1,88 @@
+package inventory
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const preflightValues = "data/values_preflight.yaml"
+
+func TestLoadClusterObjects(t *testing.T) {
+	objects, err := LoadClusterObjects("data/existing_objects.yaml", "pega")
+	require.NoError(t, err)
+	require.Equal(t, ClusterObjects{
+		"Secret/pega-db-credentials":         true,
+		"Secret/pega-srs-credentials":        true,
+		"Secret/pega-hazelcast-credentials":  true,
+		"Secret/pega-web-keystore":           true,
+		"Secret/shared/pega-ca-certificates": true,
+		"ServiceAccount/default":             true,
+	}, objects)
+
+	names := filepath.Join(t.TempDir(), "existing.txt")
+	require.NoError(t, ioutil.WriteFile(names, []byte("# kubectl get secrets,pvc -o name\nsecret/pega-db-credentials\n\npersistentvolumeclaim/pega-shared\nConfigMap/pega-custom-config\n"), 0644))
+	objects, err = LoadClusterObjects(names, "pega")
+	require.NoError(t, err)
+	require.Equal(t, ClusterObjects{
+		"Secret/pega-db-credentials":        true,
+		"PersistentVolumeClaim/pega-shared": true,
+		"ConfigMap/pega-custom-config":      true,
+	}, objects)
+
+	require.NoError(t, ioutil.WriteFile(names, []byte("secret/pega-db-credentials\npega-srs-credentials\n"), 0644))
+	_, err = LoadClusterObjects(names, "pega")
+	require.EqualError(t, err, names+`: line 2: "pega-srs-credentials" is not in the Kind/name form`)
+}
+
+func TestPreflight(t *testing.T) {
+	graph, err := Build(Options{ValuesFiles: []string{preflightValues}, Namespace: "pega"})
+	require.NoError(t, err)
+	values, err := LoadValues(preflightValues)
+	require.NoError(t, err)
+
+	unresolved := Preflight(graph, ClusterObjects{}, values)
+	valueKeys := map[string][]string{}
+	for _, reference := range unresolved {
+		require.Equal(t, "Secret", reference.Kind)
+		valueKeys[reference.Name] = reference.ValueKeys
+	}
+	require.Equal(t, map[string][]string{
+		"pega-db-credentials":        {"global.jdbc.external_secret_name"},
+		"pega-srs-credentials":       {"pegasearch.srsAuth.external_secret_name"},
+		"pega-hazelcast-credentials": {"hazelcast.external_secret_name"},
+		"pega-ca-certificates":       {"global.certificatesSecrets[0]"},
+		"pega-web-keystore":          {"global.tier[0].service.tls.external_secret_names[0]"},
+	}, valueKeys)
+	require.Contains(t, unresolved, Unresolved{
+		Edge: Edge{From: "Deployment/pega-web", To: "Secret/pega-web-keystore", Type: "projected-volume", Path: "spec.template.spec.volumes[3].projected.sources[0]"},
+		Kind: "Secret", Name: "pega-web-keystore", ValueKeys: []string{"global.tier[0].service.tls.external_secret_names[0]"},
+	})
+
+	// pega-ca-certificates exists, but in another namespace
+	existing, err := LoadClusterObjects("data/existing_objects.yaml", "pega")
+	require.NoError(t, err)
+	unresolved = Preflight(graph, existing, values)
+	require.Len(t, unresolved, 2)
+	for _, reference := range unresolved {
+		require.Equal(t, "Secret/pega-ca-certificates", reference.To)
+	}
+	require.Contains(t, DescribeUnresolved(unresolved), "Deployment/pega-batch references Secret/pega-ca-certificates, which neither the chart nor "+
+		"the cluster provides (projected-volume at spec.template.spec.volumes[2].projected.sources[0]); it is named by global.certificatesSecrets[0]")
+
+	existing["Secret/pega-ca-certificates"] = true
+	require.Empty(t, Preflight(graph, existing))
+}
+
+func TestPreflightIgnoresOtherKinds(t *testing.T) {
+	graph, err := FromManifests(rendered, "pega", nil)
+	require.NoError(t, err)
+	var targets []string
+	for _, reference := range Preflight(graph, ClusterObjects{"Secret/external-db-credentials": true}) {
+		targets = append(targets, reference.To)
+	}
+	// the Role, ManagedCertificate and the optional Secret are not checked, the default ServiceAccount always exists
+	require.Equal(t, []string{"Secret/pega-certificates", "Secret/pega-web-tls", "ServiceAccount/ci/builder"}, targets)
+}
@@ -0,0 +1,92 @@
+package inventory
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+)
+
+// workloadKinds are drawn as boxes in the DOT output, the objects they use as ellipses
+var workloadKinds = map[string]bool{}
+
+func init() {
+	for kind := range helmtest.PodSpecPaths {
+		workloadKinds[kind] = true
+	}
+}
+
+// DOT renders the graph in the Graphviz language, e.g. for `dot -Tsvg`. External objects are dashed and objects
+// behind dangling references red.
+func DOT(graph *Graph) string {
+	dangling := map[string]bool{}
+	for _, edge := range graph.Dangling() {
+		dangling[edge.To] = true
+	}
+
+	var dot strings.Builder
+	dot.WriteString("digraph inventory {\n")
+	dot.WriteString("  rankdir=LR;\n")
+	dot.WriteString("  node [fontname=\"Helvetica\", fontsize=10];\n")
+	dot.WriteString("  edge [fontname=\"Helvetica\", fontsize=8];\n")
+	for _, node := range graph.Nodes {
+		var attributes []string
+		attributes = append(attributes, "label="+quote(node.Kind+"\n"+node.Name+namespaceSuffix(node)))
+		if workloadKinds[node.Kind] {
+			attributes = append(attributes, "shape=box")
+		} else {
+			attributes = append(attributes, "shape=ellipse")
+		}
+		switch {
+		case dangling[node.ID]:
+			attributes = append(attributes, "color=red", "fontcolor=red")
+		case node.External:
+			attributes = append(attributes, "style=dashed")
+		}
+		fmt.Fprintf(&dot, "  %s [%s];\n", quote(node.ID), strings.Join(attributes, ", "))
+	}
+
+	// several references of the same type between two objects are drawn once
+	type drawnEdge struct{ from, to, label string }
+	drawn := map[drawnEdge]bool{}
+	var edges []drawnEdge
+	for _, edge := range graph.Edges {
+		key := drawnEdge{edge.From, edge.To, edge.Type}
+		if !drawn[key] {
+			drawn[key] = true
+			edges = append(edges, key)
+		}
+	}
+	sort.SliceStable(edges, func(i, j int) bool {
+		if edges[i].from != edges[j].from {
+			return edges[i].from < edges[j].from
+		}
+		return edges[i].to < edges[j].to
+	})
+	for _, edge := range edges {
+		fmt.Fprintf(&dot, "  %s -> %s [label=%s];\n", quote(edge.from), quote(edge.to), quote(edge.label))
+	}
+	dot.WriteString("}\n")
+	return dot.String()
+}
+
+// DescribeDangling lists the dangling references one per line, for the command output
+func DescribeDangling(graph *Graph) string {
+	var lines []string
+	for _, edge := range graph.Dangling() {
+		lines = append(lines, fmt.Sprintf("%s references %s, which is not rendered (%s at %s)", edge.From, edge.To, edge.Type, edge.Path))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func namespaceSuffix(node Node) string {
+	if strings.Count(node.ID, "/") > 1 {
+		return "\n(" + node.Namespace + ")"
+	}
+	return ""
+}
+
+func quote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value) + `"`
+}
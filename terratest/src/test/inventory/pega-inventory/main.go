@@ -0,0 +1,115 @@
+// Command pega-inventory lists the objects a chart renders and the references between them: the Secrets, ConfigMaps,
+// PersistentVolumeClaims and ServiceAccounts of every workload, the workloads behind every Service, and the targets
+// of Ingresses, autoscalers and disruption budgets.
+//
+//	go run ./inventory/pega-inventory -values prod.yaml
+//	go run ./inventory/pega-inventory -values prod.yaml -object Secret/pega-db-secret
+//	go run ./inventory/pega-inventory -values prod.yaml -format dot | dot -Tsvg > inventory.svg
+//
+// Objects created outside of the chart, such as the Secrets named in certificatesSecrets or external_secret_name,
+// are declared with -external Kind/name. The exit code is 0 when every reference resolves, 1 when a reference is
+// dangling and 2 when the inventory could not be built.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/inventory"
+)
+
+// stringList collects a flag given more than once
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func main() {
+	var valuesFiles, setValues, external stringList
+	flag.Var(&valuesFiles, "values", "values file, may be repeated")
+	flag.Var(&setValues, "set", "key=value applied after the values files, may be repeated")
+	flag.Var(&external, "external", "object created outside of the chart as Kind/name, e.g. Secret/my-db-credentials, may be repeated")
+	chartPath := flag.String("chart", "", "chart directory or packaged chart, defaults to charts/pega of this repository")
+	releaseName := flag.String("release", "pega", "release name")
+	namespace := flag.String("namespace", "", "namespace of the release")
+	kubeVersion := flag.String("kube-version", "", "Kubernetes version reported to the templates")
+	object := flag.String("object", "", "only show this object, as Kind/name, with what it references and what references it")
+	format := flag.String("format", "json", "output format, json or dot")
+	outPath := flag.String("out", "", "write the inventory to this file instead of stdout")
+	flag.Parse()
+	if *format != "json" && *format != "dot" {
+		flag.Usage()
+		fmt.Fprintln(os.Stderr, "-format must be json or dot")
+		os.Exit(2)
+	}
+
+	set, err := parseSet(setValues)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	graph, err := inventory.Build(inventory.Options{
+		ChartPath:   *chartPath,
+		ValuesFiles: valuesFiles,
+		SetValues:   set,
+		ReleaseName: *releaseName,
+		Namespace:   *namespace,
+		KubeVersion: *kubeVersion,
+		External:    external,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	shown := graph
+	if *object != "" {
+		if graph.Node(*object) == nil {
+			fmt.Fprintf(os.Stderr, "%s is neither rendered nor referenced\n", *object)
+			os.Exit(2)
+		}
+		shown = graph.Neighborhood(*object)
+	}
+	content := []byte(inventory.DOT(shown))
+	if *format == "json" {
+		if content, err = json.MarshalIndent(shown, "", "  "); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		content = append(content, '\n')
+	}
+	if *outPath == "" {
+		os.Stdout.Write(content)
+	} else if err := ioutil.WriteFile(*outPath, content, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	dangling := graph.Dangling()
+	fmt.Fprintf(os.Stderr, "%d object(s), %d reference(s), %d dangling\n", len(graph.Nodes), len(graph.Edges), len(dangling))
+	if len(dangling) > 0 {
+		fmt.Fprintln(os.Stderr, inventory.DescribeDangling(graph))
+		os.Exit(1)
+	}
+}
+
+// parseSet turns key=value flags into helm --set values, later flags overriding earlier ones
+func parseSet(values stringList) (map[string]string, error) {
+	set := map[string]string{}
+	for _, value := range values {
+		parts := strings.SplitN(value, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%s is not in the key=value form", value)
+		}
+		set[parts[0]] = parts[1]
+	}
+	return set, nil
+}
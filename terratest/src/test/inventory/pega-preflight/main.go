@@ -0,0 +1,146 @@
+// Command pega-preflight checks, before an install or upgrade, that every Secret, ConfigMap, ServiceAccount and
+// PersistentVolumeClaim the Pega workloads reference is either rendered by the chart or already in the cluster. It
+// catches a misspelled global.jdbc.external_secret_name, a tier tls.external_secret_names Secret that was never
+// created and the like, before the pods are stuck in ContainerCreating.
+//
+//	kubectl get secrets,configmaps,serviceaccounts,pvc -n pega -o yaml > existing.yaml
+//	go run ./inventory/pega-preflight -values prod.yaml -namespace pega -existing existing.yaml
+//	helm template pega charts/pega -f prod.yaml -n pega | go run ./inventory/pega-preflight -manifests - -existing existing.yaml
+//
+// The exit code is 0 when every reference resolves, 1 when one does not and 2 when the check could not run.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/inventory"
+)
+
+// stringList collects a flag given more than once
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func main() {
+	var valuesFiles, setValues, existingFiles stringList
+	flag.Var(&valuesFiles, "values", "values file, may be repeated")
+	flag.Var(&setValues, "set", "key=value applied after the values files, may be repeated")
+	flag.Var(&existingFiles, "existing", "objects already in the cluster, as the output of kubectl get -o yaml, -o json or -o name, or one Kind/name per line; may be repeated")
+	manifestsPath := flag.String("manifests", "", "rendered manifests to check instead of rendering the chart, - for stdin")
+	chartPath := flag.String("chart", "", "chart directory or packaged chart, defaults to charts/pega of this repository")
+	releaseName := flag.String("release", "pega", "release name")
+	namespace := flag.String("namespace", "", "namespace of the release")
+	kubeVersion := flag.String("kube-version", "", "Kubernetes version reported to the templates")
+	format := flag.String("format", "text", "output format, text or json")
+	flag.Parse()
+	if *format != "text" && *format != "json" {
+		flag.Usage()
+		fmt.Fprintln(os.Stderr, "-format must be text or json")
+		os.Exit(2)
+	}
+
+	set, err := parseSet(setValues)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	var graph *inventory.Graph
+	if *manifestsPath != "" {
+		graph, err = fromManifests(*manifestsPath, *namespace)
+	} else {
+		graph, err = inventory.Build(inventory.Options{
+			ChartPath:   *chartPath,
+			ValuesFiles: valuesFiles,
+			SetValues:   set,
+			ReleaseName: *releaseName,
+			Namespace:   *namespace,
+			KubeVersion: *kubeVersion,
+		})
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	existing := inventory.ClusterObjects{}
+	for _, path := range existingFiles {
+		objects, err := inventory.LoadClusterObjects(path, *namespace)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		for id := range objects {
+			existing[id] = true
+		}
+	}
+	var values []map[string]interface{}
+	for _, path := range valuesFiles {
+		valuesMap, err := inventory.LoadValues(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		values = append(values, valuesMap)
+	}
+	setMap := map[string]interface{}{}
+	for key, value := range set {
+		setMap[key] = value
+	}
+	values = append(values, setMap)
+
+	unresolved := inventory.Preflight(graph, existing, values...)
+	if *format == "json" {
+		if unresolved == nil {
+			unresolved = []inventory.Unresolved{}
+		}
+		content, err := json.MarshalIndent(unresolved, "", "  ")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		os.Stdout.Write(append(content, '\n'))
+	} else if len(unresolved) > 0 {
+		fmt.Println(inventory.DescribeUnresolved(unresolved))
+	}
+	fmt.Fprintf(os.Stderr, "%d reference(s) checked against %d existing object(s), %d unresolved\n", len(graph.Edges), len(existing), len(unresolved))
+	if len(unresolved) > 0 {
+		os.Exit(1)
+	}
+}
+
+func fromManifests(path string, namespace string) (*inventory.Graph, error) {
+	var content []byte
+	var err error
+	if path == "-" {
+		content, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		content, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return inventory.FromManifests(string(content), namespace, nil)
+}
+
+// parseSet turns key=value flags into helm --set values, later flags overriding earlier ones
+func parseSet(values stringList) (map[string]string, error) {
+	set := map[string]string{}
+	for _, value := range values {
+		parts := strings.SplitN(value, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%s is not in the key=value form", value)
+		}
+		set[parts[0]] = parts[1]
+	}
+	return set, nil
+}
@@ -0,0 +1,209 @@
+package inventory
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Annotations of GKE naming the objects that configure a Service or Ingress
+const (
+	backendConfigAnnotation     = "cloud.google.com/backend-config"
+	betaBackendConfigAnnotation = "beta.cloud.google.com/backend-config"
+	managedCertsAnnotation      = "networking.gke.io/managed-certificates"
+)
+
+func (b *graphBuilder) addReferences(object renderedObject, objects []renderedObject) error {
+	if path, ok := helmtest.PodSpecPaths[object.kind]; ok {
+		if spec, found := nestedMap(object.object, path); found {
+			b.podReferences(object, path, spec)
+		}
+	}
+
+	switch object.kind {
+	case "StatefulSet":
+		// the Service gives the pods their DNS names, the StatefulSet rolls out without it
+		b.reference(object, "Service", "", nestedString(object.object, "spec.serviceName"), "governing-service", "spec.serviceName", true)
+	case "Service":
+		if selector, found := nestedMap(object.object, "spec.selector"); found {
+			b.selects(object, "spec.selector", selector, objects)
+		}
+		for _, annotation := range []string{backendConfigAnnotation, betaBackendConfigAnnotation} {
+			if value := nestedString(object.object, "metadata.annotations", annotation); value != "" {
+				names, err := backendConfigNames(value)
+				if err != nil {
+					return fmt.Errorf("annotation %s: %w", annotation, err)
+				}
+				for _, name := range names {
+					b.reference(object, "BackendConfig", "", name, "backend-config", "metadata.annotations."+annotation, false)
+				}
+			}
+		}
+	case "Ingress":
+		b.ingressReferences(object)
+	case "Route":
+		b.reference(object, "Service", "", nestedString(object.object, "spec.to.name"), "backend", "spec.to", false)
+		for i, backend := range nestedSlice(object.object, "spec.alternateBackends") {
+			b.reference(object, "Service", "", nestedString(backend, "name"), "backend", fmt.Sprintf("spec.alternateBackends[%d]", i), false)
+		}
+	case "HorizontalPodAutoscaler":
+		b.reference(object, nestedString(object.object, "spec.scaleTargetRef.kind"), "", nestedString(object.object, "spec.scaleTargetRef.name"),
+			"scale-target", "spec.scaleTargetRef", false)
+	case "PodDisruptionBudget":
+		if selector, found := nestedMap(object.object, "spec.selector.matchLabels"); found {
+			b.selects(object, "spec.selector", selector, objects)
+		}
+	case "RoleBinding", "ClusterRoleBinding":
+		b.reference(object, nestedString(object.object, "roleRef.kind"), "", nestedString(object.object, "roleRef.name"), "role-ref", "roleRef", false)
+		for i, subject := range nestedSlice(object.object, "subjects") {
+			if nestedString(subject, "kind") == "ServiceAccount" {
+				b.reference(object, "ServiceAccount", nestedString(subject, "namespace"), nestedString(subject, "name"), "subject",
+					fmt.Sprintf("subjects[%d]", i), false)
+			}
+		}
+	}
+	return nil
+}
+
+// podReferences adds the ServiceAccount, image pull Secrets, volumes and environment of a pod spec
+func (b *graphBuilder) podReferences(object renderedObject, path string, spec map[string]interface{}) {
+	b.reference(object, "ServiceAccount", "", nestedString(spec, "serviceAccountName"), "service-account", path+".serviceAccountName", false)
+	for i, pullSecret := range nestedSlice(spec, "imagePullSecrets") {
+		b.reference(object, "Secret", "", nestedString(pullSecret, "name"), "image-pull-secret", fmt.Sprintf("%s.imagePullSecrets[%d]", path, i), false)
+	}
+
+	for i, volume := range nestedSlice(spec, "volumes") {
+		volumePath := fmt.Sprintf("%s.volumes[%d]", path, i)
+		b.reference(object, "Secret", "", nestedString(volume, "secret.secretName"), "volume", volumePath, nestedBool(volume, "secret.optional"))
+		b.reference(object, "ConfigMap", "", nestedString(volume, "configMap.name"), "volume", volumePath, nestedBool(volume, "configMap.optional"))
+		b.reference(object, "PersistentVolumeClaim", "", nestedString(volume, "persistentVolumeClaim.claimName"), "volume", volumePath, false)
+		for j, source := range nestedSlice(volume, "projected.sources") {
+			sourcePath := fmt.Sprintf("%s.projected.sources[%d]", volumePath, j)
+			b.reference(object, "Secret", "", nestedString(source, "secret.name"), "projected-volume", sourcePath, nestedBool(source, "secret.optional"))
+			b.reference(object, "ConfigMap", "", nestedString(source, "configMap.name"), "projected-volume", sourcePath, nestedBool(source, "configMap.optional"))
+		}
+	}
+
+	for _, containers := range []string{"initContainers", "containers"} {
+		for i, container := range nestedSlice(spec, containers) {
+			containerPath := fmt.Sprintf("%s.%s[%d]", path, containers, i)
+			for j, env := range nestedSlice(container, "env") {
+				envPath := fmt.Sprintf("%s.env[%d]", containerPath, j)
+				b.reference(object, "Secret", "", nestedString(env, "valueFrom.secretKeyRef.name"), "env", envPath, nestedBool(env, "valueFrom.secretKeyRef.optional"))
+				b.reference(object, "ConfigMap", "", nestedString(env, "valueFrom.configMapKeyRef.name"), "env", envPath, nestedBool(env, "valueFrom.configMapKeyRef.optional"))
+			}
+			for j, envFrom := range nestedSlice(container, "envFrom") {
+				envFromPath := fmt.Sprintf("%s.envFrom[%d]", containerPath, j)
+				b.reference(object, "Secret", "", nestedString(envFrom, "secretRef.name"), "env-from", envFromPath, nestedBool(envFrom, "secretRef.optional"))
+				b.reference(object, "ConfigMap", "", nestedString(envFrom, "configMapRef.name"), "env-from", envFromPath, nestedBool(envFrom, "configMapRef.optional"))
+			}
+		}
+	}
+}
+
+// ingressReferences adds the backends and TLS Secrets of an Ingress, in the networking.k8s.io/v1 form or the older
+// serviceName form, and its GKE managed certificates
+func (b *graphBuilder) ingressReferences(object renderedObject) {
+	backend := func(backend map[string]interface{}, path string) {
+		name := nestedString(backend, "service.name")
+		if name == "" {
+			name = nestedString(backend, "serviceName")
+		}
+		b.reference(object, "Service", "", name, "backend", path, false)
+	}
+	for _, defaultBackend := range []string{"spec.defaultBackend", "spec.backend"} {
+		if spec, found := nestedMap(object.object, defaultBackend); found {
+			backend(spec, defaultBackend)
+		}
+	}
+	for i, rule := range nestedSlice(object.object, "spec.rules") {
+		for j, path := range nestedSlice(rule, "http.paths") {
+			if spec, found := nestedMap(path, "backend"); found {
+				backend(spec, fmt.Sprintf("spec.rules[%d].http.paths[%d].backend", i, j))
+			}
+		}
+	}
+	for i, tls := range nestedSlice(object.object, "spec.tls") {
+		b.reference(object, "Secret", "", nestedString(tls, "secretName"), "tls", fmt.Sprintf("spec.tls[%d]", i), false)
+	}
+	for _, name := range strings.Split(nestedString(object.object, "metadata.annotations", managedCertsAnnotation), ",") {
+		b.reference(object, "ManagedCertificate", "", strings.TrimSpace(name), "managed-certificate", "metadata.annotations."+managedCertsAnnotation, false)
+	}
+}
+
+// selects adds an edge to every workload in the namespace of object whose pods carry all the labels of selector
+func (b *graphBuilder) selects(object renderedObject, path string, selector map[string]interface{}, objects []renderedObject) {
+	if len(selector) == 0 {
+		return
+	}
+	for _, candidate := range objects {
+		podSpecPath, ok := helmtest.PodSpecPaths[candidate.kind]
+		if !ok || candidate.namespace != object.namespace {
+			continue
+		}
+		labelsPath := strings.TrimSuffix(podSpecPath, "spec") + "metadata.labels"
+		labels, _ := nestedMap(candidate.object, labelsPath)
+		matches := true
+		for key, value := range selector {
+			matches = matches && labels[key] == value
+		}
+		if matches {
+			b.edges = append(b.edges, Edge{From: object.id, To: candidate.id, Type: "selects", Path: path})
+		}
+	}
+}
+
+// backendConfigNames reads the BackendConfig names of the GKE annotation, {"default": "name"} or
+// {"ports": {"80": "name"}}
+func backendConfigNames(annotation string) ([]string, error) {
+	var config struct {
+		Default string            `json:"default"`
+		Ports   map[string]string `json:"ports"`
+	}
+	if err := json.Unmarshal([]byte(annotation), &config); err != nil {
+		return nil, err
+	}
+	names := []string{config.Default}
+	for _, name := range config.Ports {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func fields(path string, more []string) []string {
+	return append(strings.Split(path, "."), more...)
+}
+
+// nestedMap returns the map at the dot separated path; further fields are appended as is, for keys containing dots
+func nestedMap(object map[string]interface{}, path string, more ...string) (map[string]interface{}, bool) {
+	value, found, err := unstructured.NestedFieldNoCopy(object, fields(path, more)...)
+	result, ok := value.(map[string]interface{})
+	return result, found && err == nil && ok
+}
+
+func nestedString(object map[string]interface{}, path string, more ...string) string {
+	value, _, _ := unstructured.NestedFieldNoCopy(object, fields(path, more)...)
+	result, _ := value.(string)
+	return result
+}
+
+func nestedBool(object map[string]interface{}, path string) bool {
+	value, _, _ := unstructured.NestedFieldNoCopy(object, strings.Split(path, ".")...)
+	result, _ := value.(bool)
+	return result
+}
+
+// nestedSlice returns the items of the list at path as maps, with nil in place of items of other types so that the
+// indexes match the object
+func nestedSlice(object map[string]interface{}, path string) []map[string]interface{} {
+	value, _, _ := unstructured.NestedFieldNoCopy(object, strings.Split(path, ".")...)
+	items, _ := value.([]interface{})
+	result := make([]map[string]interface{}, len(items))
+	for i, item := range items {
+		result[i], _ = item.(map[string]interface{})
+	}
+	return result
+}
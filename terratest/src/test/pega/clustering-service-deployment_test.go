@@ -1,43 +1,31 @@
 package pega
 
 import (
-	"fmt"
 	"path/filepath"
 	"strings"
 	"testing"
 
-	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	appsv1beta2 "k8s.io/api/apps/v1beta2"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 func TestClusteringServiceDeployment(t *testing.T) {
-	var supportedVendors = []string{"k8s", "openshift", "eks", "gke", "aks", "pks"}
-	var supportedOperations = []string{"deploy", "install-deploy"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-
-		for _, operation := range supportedOperations {
-
-			fmt.Println(vendor + "-" + operation)
-
-			var options = &helm.Options{
-				SetValues: map[string]string{
-					"global.provider":                    vendor,
-					"global.actions.execute":             operation,
-					"hazelcast.clusteringServiceEnabled": "true",
-				},
-			}
-
-			yamlContent := RenderTemplate(t, options, helmChartPath, []string{"charts/hazelcast/templates/clustering-service-deployment.yaml"})
-			VerifyClusteringServiceDeployment(t, yamlContent)
-
-		}
-	}
+	helmtest.Matrix{
+		Providers: helmtest.SupportedProviders,
+		Actions:   []string{"deploy", "install-deploy"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"hazelcast.clusteringServiceEnabled": "true",
+		})
+
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"charts/hazelcast/templates/clustering-service-deployment.yaml"})
+		VerifyClusteringServiceDeployment(t, yamlContent)
+	})
 }
 
 func VerifyClusteringServiceDeployment(t *testing.T, yamlContent string) {
@@ -67,34 +55,23 @@ func VerifyClusteringServiceDeployment(t *testing.T, yamlContent string) {
 }
 
 func TestClusteringServiceDeploymentSecurityContext(t *testing.T) {
-	var supportedVendors = []string{"k8s", "openshift", "eks", "gke", "aks", "pks"}
-	var supportedOperations = []string{"deploy", "install-deploy"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-
-		for _, operation := range supportedOperations {
-
-			fmt.Println(vendor + "-" + operation)
-
-			var options = &helm.Options{
-				SetValues: map[string]string{
-					"global.provider":                     vendor,
-					"global.actions.execute":              operation,
-					"hazelcast.clusteringServiceEnabled":  "true",
-					"hazelcast.securityContext.runAsUser": "1000",
-				},
-			}
-
-			yamlContent := RenderTemplate(t, options, helmChartPath, []string{"charts/hazelcast/templates/clustering-service-deployment.yaml"})
-			yamlSplit := strings.Split(yamlContent, "---")
-			var statefulsetObj appsv1beta2.StatefulSet
-			UnmarshalK8SYaml(t, yamlSplit[1], &statefulsetObj)
-
-			require.Equal(t, int64(1000), *statefulsetObj.Spec.Template.Spec.SecurityContext.RunAsUser)
-
-		}
-	}
+	helmtest.Matrix{
+		Providers: helmtest.SupportedProviders,
+		Actions:   []string{"deploy", "install-deploy"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"hazelcast.clusteringServiceEnabled":  "true",
+			"hazelcast.securityContext.runAsUser": "1000",
+		})
+
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"charts/hazelcast/templates/clustering-service-deployment.yaml"})
+		yamlSplit := strings.Split(yamlContent, "---")
+		var statefulsetObj appsv1beta2.StatefulSet
+		UnmarshalK8SYaml(t, yamlSplit[1], &statefulsetObj)
+
+		require.Equal(t, int64(1000), *statefulsetObj.Spec.Template.Spec.SecurityContext.RunAsUser)
+	})
 }
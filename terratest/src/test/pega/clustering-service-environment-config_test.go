@@ -1,8 +1,8 @@
 package pega
 
 import (
-	"fmt"
 	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	k8score "k8s.io/api/core/v1"
 	"path/filepath"
@@ -10,33 +10,21 @@ import (
 	"testing"
 )
 
-func TestClusteringServiceEnvironmentConfig(t *testing.T){
-	var supportedVendors = []string{"k8s","openshift","eks","gke","aks","pks"}
-	var supportedOperations =  []string{"deploy","install-deploy"}
-
+func TestClusteringServiceEnvironmentConfig(t *testing.T) {
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _,vendor := range supportedVendors{
-
-		for _,operation := range supportedOperations{
-
-			fmt.Println(vendor + "-" + operation)
-
-			var options = &helm.Options{
-				SetValues: map[string]string{
-					"global.provider":        vendor,
-					"global.actions.execute": operation,
-					"hazelcast.clusteringServiceEnabled": "true",
-				},
-			}
-
-			yamlContent := RenderTemplate(t, options, helmChartPath, []string{"charts/hazelcast/templates/clustering-service-environment-config.yaml"})
-			VerifyClusteringServiceEnvironmentConfig(t,yamlContent, options)
-
-		}
-	}
+	helmtest.Matrix{
+		Providers: helmtest.SupportedProviders,
+		Actions:   []string{"deploy", "install-deploy"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"hazelcast.clusteringServiceEnabled": "true",
+		})
 
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"charts/hazelcast/templates/clustering-service-environment-config.yaml"})
+		VerifyClusteringServiceEnvironmentConfig(t, yamlContent, options)
+	})
 
 }
 
@@ -52,15 +40,15 @@ func VerifyClusteringServiceEnvironmentConfig(t *testing.T, yamlContent string,
 			require.Equal(t, clusteringServiceEnvConfigData["JAVA_OPTS"], "-XX:MaxRAMPercentage=80.0 -XX:InitialRAMPercentage=80.0 -XX:+HeapDumpOnOutOfMemoryError -XX:HeapDumpPath=/opt/hazelcast/logs/heapdump.hprof -XX:+UseG1GC -XX:NewRatio=3 -XshowSettings:vm -XX:InitiatingHeapOccupancyPercent=45 -Xlog:gc*,gc+phases=debug:file=/opt/hazelcast/logs/gc.log:time,pid,tags:filecount=5,filesize=3m")
 			require.Equal(t, clusteringServiceEnvConfigData["SERVICE_NAME"], "clusteringservice-service")
 			require.Equal(t, clusteringServiceEnvConfigData["MIN_CLUSTER_SIZE"], "3")
-            require.Equal(t, clusteringServiceEnvConfigData["JMX_ENABLED"], "true")
-            require.Equal(t, clusteringServiceEnvConfigData["HEALTH_MONITORING_LEVEL"], "OFF")
-            require.Equal(t, clusteringServiceEnvConfigData["GROUP_NAME"], "prpchz")
-            require.Equal(t, clusteringServiceEnvConfigData["GRACEFUL_SHUTDOWN_MAX_WAIT_SECONDS"], "600")
-            require.Equal(t, clusteringServiceEnvConfigData["LOGGING_LEVEL"], "info")
-            require.Equal(t, clusteringServiceEnvConfigData["DIAGNOSTICS_ENABLED"], "true")
-            require.Equal(t, clusteringServiceEnvConfigData["DIAGNOSTICS_METRIC_LEVEL"], "info")
-            require.Equal(t, clusteringServiceEnvConfigData["DIAGNOSTICS_FILE_COUNT"], "3")
-            require.Equal(t, clusteringServiceEnvConfigData["DIAGNOSTIC_LOG_FILE_SIZE_MB"], "50")
+			require.Equal(t, clusteringServiceEnvConfigData["JMX_ENABLED"], "true")
+			require.Equal(t, clusteringServiceEnvConfigData["HEALTH_MONITORING_LEVEL"], "OFF")
+			require.Equal(t, clusteringServiceEnvConfigData["GROUP_NAME"], "prpchz")
+			require.Equal(t, clusteringServiceEnvConfigData["GRACEFUL_SHUTDOWN_MAX_WAIT_SECONDS"], "600")
+			require.Equal(t, clusteringServiceEnvConfigData["LOGGING_LEVEL"], "info")
+			require.Equal(t, clusteringServiceEnvConfigData["DIAGNOSTICS_ENABLED"], "true")
+			require.Equal(t, clusteringServiceEnvConfigData["DIAGNOSTICS_METRIC_LEVEL"], "info")
+			require.Equal(t, clusteringServiceEnvConfigData["DIAGNOSTICS_FILE_COUNT"], "3")
+			require.Equal(t, clusteringServiceEnvConfigData["DIAGNOSTIC_LOG_FILE_SIZE_MB"], "50")
 
 		}
 	}
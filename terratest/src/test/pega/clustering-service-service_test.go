@@ -2,43 +2,30 @@ package pega
 
 import (
 	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	k8score "k8s.io/api/core/v1"
 	intstr "k8s.io/apimachinery/pkg/util/intstr"
 	"path/filepath"
 	"strings"
 	"testing"
-	"fmt"
 )
 
-
-
-func TestClusteringService(t *testing.T){
-	var supportedVendors = []string{"k8s","openshift","eks","gke","aks","pks"}
-	var supportedOperations =  []string{"deploy","install-deploy"}
-
+func TestClusteringService(t *testing.T) {
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-
-	for _,vendor := range supportedVendors{
-
-		for _,operation := range supportedOperations{
-
-			fmt.Println(vendor + "-" + operation)
-
-			var options = &helm.Options{
-				SetValues: map[string]string{
-					"global.provider":        vendor,
-					"global.actions.execute": operation,
-					"hazelcast.clusteringServiceEnabled":  "true",
-				},
-			}
-
-			yamlContent := RenderTemplate(t, options, helmChartPath, []string{"charts/hazelcast/templates/clustering-service-service.yaml"})
-			VerifyClusteringService(t,yamlContent,options)
-		}
-	}
+	helmtest.Matrix{
+		Providers: helmtest.SupportedProviders,
+		Actions:   []string{"deploy", "install-deploy"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"hazelcast.clusteringServiceEnabled": "true",
+		})
+
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"charts/hazelcast/templates/clustering-service-service.yaml"})
+		VerifyClusteringService(t, yamlContent, options)
+	})
 }
 
 func VerifyClusteringService(t *testing.T, yamlContent string, options *helm.Options) {
@@ -55,4 +42,4 @@ func VerifyClusteringService(t *testing.T, yamlContent string, options *helm.Opt
 			require.Equal(t, intstr.FromInt(5701), clusteringServiceObj.Spec.Ports[0].TargetPort)
 		}
 	}
-}
\ No newline at end of file
+}
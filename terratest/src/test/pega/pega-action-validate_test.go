@@ -46,9 +46,8 @@ func TestPegaActonValidate_WithInValidAction(t *testing.T){
 	 	},
 	}
 
-	yamlContent, err := RenderTemplateE(t, options, helmChartPath, []string{"templates/pega-action-validate.yaml"})
-	require.Contains(t,yamlContent,"Action value is not correct")
-	require.Contains(t,err.Error(),"Action value is not correct")
+	_, err = RenderTemplateE(t, options, helmChartPath, []string{"templates/pega-action-validate.yaml"})
+	requireValuesSchemaError(t, err, "global.actions.execute")
 		
 }
 
@@ -0,0 +1,114 @@
+package pega
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+	"github.com/stretchr/testify/require"
+	k8score "k8s.io/api/core/v1"
+)
+
+// presetAntiAffinityTerm - the single pod anti-affinity term generated by a soft or hard preset
+func presetAntiAffinityTerm(t *testing.T, workload tierWorkload, preset string) k8score.PodAffinityTerm {
+	affinity := workload.Spec.Template.Spec.Affinity
+	require.NotNil(t, affinity, "%s has no affinity", workload.Metadata.Name)
+	require.NotNil(t, affinity.PodAntiAffinity, "%s has no pod anti-affinity", workload.Metadata.Name)
+	antiAffinity := affinity.PodAntiAffinity
+	if preset == "hard" {
+		require.Empty(t, antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution)
+		require.Len(t, antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution, 1)
+		return antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution[0]
+	}
+	require.Empty(t, antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution)
+	require.Len(t, antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution, 1)
+	require.Equal(t, int32(100), antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution[0].Weight)
+	return antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution[0].PodAffinityTerm
+}
+
+// requirePresetAntiAffinity - the preset keeps the pods of the workload apart from each other by node and leaves the
+// pods of every other workload alone
+func requirePresetAntiAffinity(t *testing.T, workloads []tierWorkload, name string, preset string) {
+	for _, workload := range workloads {
+		if workload.Metadata.Name != name {
+			continue
+		}
+		term := presetAntiAffinityTerm(t, workload, preset)
+		require.Equal(t, "kubernetes.io/hostname", term.TopologyKey)
+		require.Empty(t, term.Namespaces)
+		selected := selectedWorkloads(t, workloads, term.LabelSelector)
+		require.Len(t, selected, 1, "anti-affinity of %s selects %d workloads", name, len(selected))
+		require.Equal(t, name, selected[0].Metadata.Name)
+		return
+	}
+	require.Failf(t, "workload not rendered", "no Deployment or StatefulSet named %s", name)
+}
+
+// TestPegaAffinity - tiers, search and Hazelcast get the pod anti-affinity of their preset next to any affinity passed
+// through, and tiers without either get none
+func TestPegaAffinity(t *testing.T) {
+	testsPath, err := filepath.Abs(PegaHelmChartTestsPath)
+	require.NoError(t, err)
+
+	matrix := helmtest.Matrix{
+		Providers:       helmtest.SupportedProviders,
+		Actions:         helmtest.SupportedDeployActions,
+		DeploymentNames: []string{"pega", "myapp-dev"},
+	}
+	matrix.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		for _, preset := range []string{"soft", "hard"} {
+			preset := preset
+			t.Run(preset, func(t *testing.T) {
+				options := pegaOptions(combination, map[string]string{
+					"pegasearch.podAntiAffinityPreset": preset,
+					"hazelcast.podAntiAffinityPreset":  preset,
+				})
+				helmTest := newPegaChartTest(t, options, testsPath+"/data/values_affinity.yaml")
+				workloads := podWorkloads(helmtest.NewHelmConfigParser(helmTest))
+
+				requirePresetAntiAffinity(t, workloads, getObjName(options, "-web"), "hard")
+				requirePresetAntiAffinity(t, workloads, getObjName(options, "-batch"), "soft")
+				requirePresetAntiAffinity(t, workloads, getObjName(options, "-search"), preset)
+				requirePresetAntiAffinity(t, workloads, "pega-hazelcast", preset)
+				requirePresetAntiAffinity(t, workloads, "clusteringservice", preset)
+
+				for _, workload := range workloads {
+					affinity := workload.Spec.Template.Spec.Affinity
+					switch workload.Metadata.Name {
+					case getObjName(options, "-web"):
+						require.NotNil(t, affinity.NodeAffinity)
+						terms := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+						require.Len(t, terms, 1)
+						require.Equal(t, "topology.kubernetes.io/zone", terms[0].MatchExpressions[0].Key)
+						require.Equal(t, []string{"zone-a", "zone-b"}, terms[0].MatchExpressions[0].Values)
+					case getObjName(options, "-stream"):
+						require.NotNil(t, affinity)
+						require.Nil(t, affinity.NodeAffinity)
+						preferred := affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+						require.Len(t, preferred, 1)
+						require.Equal(t, int32(50), preferred[0].Weight)
+						require.Equal(t, "topology.kubernetes.io/zone", preferred[0].PodAffinityTerm.TopologyKey)
+						require.Equal(t, map[string]string{"app": "pega-stream"}, preferred[0].PodAffinityTerm.LabelSelector.MatchLabels)
+						require.Empty(t, affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution)
+					case getObjName(options, "-batch"), getObjName(options, "-search"), "pega-hazelcast", "clusteringservice":
+						require.Nil(t, affinity.NodeAffinity)
+						require.Nil(t, affinity.PodAffinity)
+					}
+				}
+			})
+		}
+	})
+}
+
+// TestPegaAffinityDisabled - the example values.yaml sets no affinity, so no pod gets one
+func TestPegaAffinityDisabled(t *testing.T) {
+	options := pegaOptions(k8sDeploy, map[string]string{
+		"hazelcast.clusteringServiceEnabled": "true",
+	})
+	helmTest := newPegaChartTest(t, options)
+	workloads := podWorkloads(helmtest.NewHelmConfigParser(helmTest))
+	require.NotEmpty(t, workloads)
+	for _, workload := range workloads {
+		require.Nil(t, workload.Spec.Template.Spec.Affinity, "%s has an affinity", workload.Metadata.Name)
+	}
+}
@@ -1,39 +1,33 @@
 package pega
 
 import (
-	"path/filepath"
-	"testing"
-	k8score "k8s.io/api/core/v1"
-	appsv1 "k8s.io/api/apps/v1"
 	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	k8score "k8s.io/api/core/v1"
 	intstr "k8s.io/apimachinery/pkg/util/intstr"
+	"path/filepath"
+	"testing"
 )
 
 func TestConstellationDeployment(t *testing.T) {
 
-	var supportedVendors = []string{"k8s"}
-	var supportedOperations =  []string{"deploy","install-deploy","upgrade-deploy"}
-
-		helmChartPath, err := filepath.Abs(PegaHelmChartPath)
-		require.NoError(t, err)
+	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
+	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-		for _, operation := range supportedOperations {
-			var options = &helm.Options{
-				SetValues: map[string]string{
-					"global.provider":        vendor,
-					"global.actions.execute": operation,
-					"constellation.enabled":  "true",
-					"installer.upgrade.upgradeType": "zero-downtime",
-				},
-			}
-	yamlContent := RenderTemplate(t, options, helmChartPath, []string{"charts/constellation/templates/clln-deployment.yaml"})
-	assertConstellationDeployment(t, yamlContent, options)
-	constellationService := RenderTemplate(t, options, helmChartPath, []string{"charts/constellation/templates/clln-service.yaml"})
-	assertConstellationService(t, constellationService, options)
-  }
- }
+	helmtest.Matrix{
+		Providers: []string{"k8s"},
+		Actions:   helmtest.SupportedDeployActions,
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"constellation.enabled": "true",
+		})
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"charts/constellation/templates/clln-deployment.yaml"})
+		assertConstellationDeployment(t, yamlContent, options)
+		constellationService := RenderTemplate(t, options, helmChartPath, []string{"charts/constellation/templates/clln-service.yaml"})
+		assertConstellationService(t, constellationService, options)
+	})
 }
 
 func assertConstellationDeployment(t *testing.T, deploymentYaml string, options *helm.Options) {
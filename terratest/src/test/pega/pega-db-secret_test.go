@@ -1,8 +1,7 @@
 package pega
 
 import (
-	"fmt"
-	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	k8score "k8s.io/api/core/v1"
 	"path/filepath"
@@ -10,53 +9,31 @@ import (
 )
 
 func TestPegaDBSecret(t *testing.T) {
-	var supportedVendors = []string{"k8s", "openshift", "eks", "gke", "aks", "pks"}
-	var supportedOperations = []string{"install", "deploy", "install-deploy", "upgrade-deploy"}
-	var deploymentNames = []string{"pega", "myapp-dev"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-
-		for _, operation := range supportedOperations {
-
-			for _, depName := range deploymentNames {
-				fmt.Println(vendor + "-" + operation)
-
-				var options = &helm.Options{
-					SetValues: map[string]string{
-						"global.deployment.name":        depName,
-						"global.provider":               vendor,
-						"global.actions.execute":        operation,
-						"installer.upgrade.upgradeType": getUpgradeTypeForUpgradeAction(operation),
-					},
-				}
-
-				yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-db-secret.yaml"})
-				VerifyDBSecret(t, yamlContent)
-			}
-		}
-	}
-
-	for _, vendor := range supportedVendors {
-
-		for _, operation := range supportedOperations {
-
-			fmt.Println(vendor + "-" + operation)
-
-			var options = &helm.Options{
-				SetValues: map[string]string{
-					"global.provider":               vendor,
-					"global.actions.execute":        operation,
-					"installer.upgrade.upgradeType": "zero-downtime",
-				},
-			}
-
-			yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-db-secret.yaml"})
-			VerifyDBSecret(t, yamlContent)
-		}
-	}
+	helmtest.Matrix{
+		Providers:       helmtest.SupportedProviders,
+		Actions:         []string{"install", "deploy", "install-deploy", "upgrade-deploy"},
+		DeploymentNames: []string{"pega", "myapp-dev"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"installer.upgrade.upgradeType": getUpgradeTypeForUpgradeAction(combination.Action),
+		})
+
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-db-secret.yaml"})
+		VerifyDBSecret(t, yamlContent)
+	})
+
+	helmtest.Matrix{
+		Providers: helmtest.SupportedProviders,
+		Actions:   []string{"install", "deploy", "install-deploy", "upgrade-deploy"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(nil)
+
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-db-secret.yaml"})
+		VerifyDBSecret(t, yamlContent)
+	})
 
 }
 
@@ -1,7 +1,7 @@
 package pega
 
 import (
-	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	k8score "k8s.io/api/core/v1"
 	"path/filepath"
@@ -10,26 +10,21 @@ import (
 )
 
 func TestPegaDeployCustomConfig(t *testing.T) {
-	var supportedVendors = []string{"k8s", "openshift", "eks", "gke", "aks", "pks"}
-	var supportedOperations = []string{"deploy"}
 	var custom_config = "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<pegarules>\n    <env name=\"custom/Prconfig\" value=\"prconfig.xml\" />\n</pegarules>"
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-		for _, operation := range supportedOperations {
-			var options = &helm.Options{
-				SetValues: map[string]string{
-					"global.provider":        vendor,
-					"global.actions.execute": operation,
-					"global.configurations.web.prconfig": custom_config,
-				},
-			}
+	helmtest.Matrix{
+		Providers: helmtest.SupportedProviders,
+		Actions:   []string{"deploy"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"global.configurations.web.prconfig": custom_config,
+		})
 
-			yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-config.yaml"})
-			assertPegaDeployCustomConfig(t, yamlContent)
-		}
-	}
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-config.yaml"})
+		assertPegaDeployCustomConfig(t, yamlContent)
+	})
 }
 
 func assertPegaDeployCustomConfig(t *testing.T, configYaml string) {
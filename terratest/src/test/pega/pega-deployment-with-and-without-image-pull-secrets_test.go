@@ -1,7 +1,7 @@
 package pega
 
 import (
-	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
 	"path/filepath"
@@ -11,261 +11,177 @@ import (
 
 func TestPegaDeploymentWithoutImagePullSecrets(t *testing.T) {
 
-	var supportedVendors = []string{"k8s", "eks", "gke", "aks"}
-	var supportedOperations = []string{"deploy", "install-deploy"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-		for _, operation := range supportedOperations {
-
-			var options = &helm.Options{
-				SetValues: map[string]string{
-					"global.provider":        vendor,
-					"global.actions.execute": operation,
-				},
-			}
-			deploymentYaml := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-deployment.yaml"})
-			yamlSplit := strings.Split(deploymentYaml, "---")
-			assertWithoutImagePullSecrets(t, yamlSplit[1])
-		}
-	}
+	helmtest.Matrix{
+		Providers: []string{"k8s", "eks", "gke", "aks"},
+		Actions:   []string{"deploy", "install-deploy"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(nil)
+		deploymentYaml := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-deployment.yaml"})
+		yamlSplit := strings.Split(deploymentYaml, "---")
+		assertWithoutImagePullSecrets(t, yamlSplit[1])
+	})
 }
 
 func TestPegaDeploymentWithImagePullSecrets(t *testing.T) {
 
-	var supportedVendors = []string{"k8s", "eks", "gke", "aks"}
-	var supportedOperations = []string{"deploy", "install-deploy"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-		for _, operation := range supportedOperations {
-
-			var options = &helm.Options{
-				SetValues: map[string]string{
-					"global.provider":                    vendor,
-					"global.actions.execute":             operation,
-					"global.docker.imagePullSecretNames": "{\"secret1\",\"secret2\"}",
-				},
-			}
-			deploymentYaml := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-deployment.yaml"})
-			yamlSplit := strings.Split(deploymentYaml, "---")
-			assertWithImagePullSecrets(t, yamlSplit[1])
-		}
-	}
+	helmtest.Matrix{
+		Providers: []string{"k8s", "eks", "gke", "aks"},
+		Actions:   []string{"deploy", "install-deploy"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"global.docker.imagePullSecretNames": "{\"secret1\",\"secret2\"}",
+		})
+		deploymentYaml := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-deployment.yaml"})
+		yamlSplit := strings.Split(deploymentYaml, "---")
+		assertWithImagePullSecrets(t, yamlSplit[1])
+	})
 }
 
 func TestHazelcastDeploymentWithoutImagePullSecrets(t *testing.T) {
-	var supportedVendors = []string{"k8s", "openshift", "eks", "gke", "aks", "pks"}
-	var supportedOperations = []string{"deploy", "install-deploy"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-
-		for _, operation := range supportedOperations {
-
-			var options = &helm.Options{
-				SetValues: map[string]string{
-					"global.provider":        vendor,
-					"global.actions.execute": operation,
-					"hazelcast.enabled":      "true",
-				},
-			}
-
-			yamlContent := RenderTemplate(t, options, helmChartPath, []string{"charts/hazelcast/templates/pega-hz-deployment.yaml"})
-			assertWithoutImagePullSecrets(t, yamlContent)
-
-		}
-	}
+	helmtest.Matrix{
+		Providers: helmtest.SupportedProviders,
+		Actions:   []string{"deploy", "install-deploy"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"hazelcast.enabled": "true",
+		})
+
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"charts/hazelcast/templates/pega-hz-deployment.yaml"})
+		assertWithoutImagePullSecrets(t, yamlContent)
+	})
 }
 
 func TestHazelcastDeploymentWithImagePullSecrets(t *testing.T) {
-	var supportedVendors = []string{"k8s", "openshift", "eks", "gke", "aks", "pks"}
-	var supportedOperations = []string{"deploy", "install-deploy"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-
-		for _, operation := range supportedOperations {
-
-			var options = &helm.Options{
-				SetValues: map[string]string{
-					"global.provider":                    vendor,
-					"global.actions.execute":             operation,
-					"hazelcast.enabled":                  "true",
-					"global.docker.imagePullSecretNames": "{\"secret1\",\"secret2\"}",
-				},
-			}
-
-			yamlContent := RenderTemplate(t, options, helmChartPath, []string{"charts/hazelcast/templates/pega-hz-deployment.yaml"})
-			assertWithImagePullSecrets(t, yamlContent)
-
-		}
-	}
+	helmtest.Matrix{
+		Providers: helmtest.SupportedProviders,
+		Actions:   []string{"deploy", "install-deploy"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"hazelcast.enabled":                  "true",
+			"global.docker.imagePullSecretNames": "{\"secret1\",\"secret2\"}",
+		})
+
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"charts/hazelcast/templates/pega-hz-deployment.yaml"})
+		assertWithImagePullSecrets(t, yamlContent)
+	})
 }
 
 func TestPegaSearchDeploymentWithoutImagePullSecrets(t *testing.T) {
-	var supportedVendors = []string{"k8s", "openshift", "eks", "gke", "aks", "pks"}
-	var supportedOperations = []string{"deploy", "install-deploy"}
-	var deploymentNames = []string{"pega"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
+	helmtest.Matrix{
+		Providers:       helmtest.SupportedProviders,
+		Actions:         []string{"deploy", "install-deploy"},
+		DeploymentNames: []string{"pega"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(nil)
 
-		for _, operation := range supportedOperations {
-
-			for _, depName := range deploymentNames {
-
-				var options = &helm.Options{
-					SetValues: map[string]string{
-						"global.deployment.name": depName,
-						"global.provider":        vendor,
-						"global.actions.execute": operation,
-					},
-				}
-
-				yamlContent := RenderTemplate(t, options, helmChartPath, []string{"charts/pegasearch/templates/pega-search-deployment.yaml"})
-				assertWithoutImagePullSecrets(t, yamlContent)
-			}
-		}
-	}
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"charts/pegasearch/templates/pega-search-deployment.yaml"})
+		assertWithoutImagePullSecrets(t, yamlContent)
+	})
 }
 
 func TestPegaSearchDeploymentWithImagePullSecrets(t *testing.T) {
-	var supportedVendors = []string{"k8s", "openshift", "eks", "gke", "aks", "pks"}
-	var supportedOperations = []string{"deploy", "install-deploy"}
-	var deploymentNames = []string{"pega"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-
-		for _, operation := range supportedOperations {
-
-			for _, depName := range deploymentNames {
-
-				var options = &helm.Options{
-					SetValues: map[string]string{
-						"global.deployment.name":             depName,
-						"global.provider":                    vendor,
-						"global.actions.execute":             operation,
-						"global.docker.imagePullSecretNames": "{\"secret1\",\"secret2\"}",
-					},
-				}
-
-				yamlContent := RenderTemplate(t, options, helmChartPath, []string{"charts/pegasearch/templates/pega-search-deployment.yaml"})
-				assertWithImagePullSecrets(t, yamlContent)
-			}
-		}
-	}
+	helmtest.Matrix{
+		Providers:       helmtest.SupportedProviders,
+		Actions:         []string{"deploy", "install-deploy"},
+		DeploymentNames: []string{"pega"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"global.docker.imagePullSecretNames": "{\"secret1\",\"secret2\"}",
+		})
+
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"charts/pegasearch/templates/pega-search-deployment.yaml"})
+		assertWithImagePullSecrets(t, yamlContent)
+	})
 }
 
 func TestConstellationDeploymentWithoutImagePullSecrets(t *testing.T) {
 
-	var supportedVendors = []string{"k8s"}
-	var supportedOperations = []string{"deploy", "install-deploy"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-		for _, operation := range supportedOperations {
-			var options = &helm.Options{
-				SetValues: map[string]string{
-					"global.provider":        vendor,
-					"global.actions.execute": operation,
-					"constellation.enabled":  "true",
-				},
-			}
-			yamlContent := RenderTemplate(t, options, helmChartPath, []string{"charts/constellation/templates/clln-deployment.yaml"})
-			assertWithoutImagePullSecrets(t, yamlContent)
-		}
-	}
+	helmtest.Matrix{
+		Providers: []string{"k8s"},
+		Actions:   []string{"deploy", "install-deploy"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"constellation.enabled": "true",
+		})
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"charts/constellation/templates/clln-deployment.yaml"})
+		assertWithoutImagePullSecrets(t, yamlContent)
+	})
 }
 
 func TestConstellationDeploymentWithImagePullSecrets(t *testing.T) {
 
-	var supportedVendors = []string{"k8s"}
-	var supportedOperations = []string{"deploy", "install-deploy"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-		for _, operation := range supportedOperations {
-			var options = &helm.Options{
-				SetValues: map[string]string{
-					"global.provider":                    vendor,
-					"global.actions.execute":             operation,
-					"constellation.enabled":              "true",
-					"global.docker.imagePullSecretNames": "{\"secret1\",\"secret2\"}",
-				},
-			}
-			yamlContent := RenderTemplate(t, options, helmChartPath, []string{"charts/constellation/templates/clln-deployment.yaml"})
-			assertWithImagePullSecrets(t, yamlContent)
-		}
-	}
+	helmtest.Matrix{
+		Providers: []string{"k8s"},
+		Actions:   []string{"deploy", "install-deploy"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"constellation.enabled":              "true",
+			"global.docker.imagePullSecretNames": "{\"secret1\",\"secret2\"}",
+		})
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"charts/constellation/templates/clln-deployment.yaml"})
+		assertWithImagePullSecrets(t, yamlContent)
+	})
 }
 
 func TestPegaDeploymentWithoutRegistryBlock(t *testing.T) {
 
-	var supportedVendors = []string{"k8s", "eks", "gke", "aks"}
-	var supportedOperations = []string{"deploy", "install-deploy"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-		for _, operation := range supportedOperations {
-
-			var options = &helm.Options{
-				SetValues: map[string]string{
-					"global.provider":        vendor,
-					"global.actions.execute": operation,
-					"global.docker.registry": "",
-				},
-			}
-			deploymentYaml := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-deployment.yaml"})
-			yamlSplit := strings.Split(deploymentYaml, "---")
-			assertWithoutRegistryBlock(t, yamlSplit[1])
-		}
-	}
+	helmtest.Matrix{
+		Providers: []string{"k8s", "eks", "gke", "aks"},
+		Actions:   []string{"deploy", "install-deploy"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"global.docker.registry": "",
+		})
+		deploymentYaml := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-deployment.yaml"})
+		yamlSplit := strings.Split(deploymentYaml, "---")
+		assertWithoutRegistryBlock(t, yamlSplit[1])
+	})
 }
 
 func TestPegaDeploymentWithoutRegistryBlockWithExternalSecrets(t *testing.T) {
 
-	var supportedVendors = []string{"k8s", "eks", "gke", "aks"}
-	var supportedOperations = []string{"deploy", "install-deploy"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-		for _, operation := range supportedOperations {
-
-			var options = &helm.Options{
-				SetValues: map[string]string{
-					"global.provider":                    vendor,
-					"global.actions.execute":             operation,
-					"global.docker.registry":             "",
-					"global.docker.imagePullSecretNames": "{\"secret1\",\"secret2\"}",
-				},
-			}
-			deploymentYaml := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-deployment.yaml"})
-			yamlSplit := strings.Split(deploymentYaml, "---")
-			assertWithoutRegistryBlockWithExternalSecrets(t, yamlSplit[1])
-		}
-	}
+	helmtest.Matrix{
+		Providers: []string{"k8s", "eks", "gke", "aks"},
+		Actions:   []string{"deploy", "install-deploy"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"global.docker.registry":             "",
+			"global.docker.imagePullSecretNames": "{\"secret1\",\"secret2\"}",
+		})
+		deploymentYaml := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-deployment.yaml"})
+		yamlSplit := strings.Split(deploymentYaml, "---")
+		assertWithoutRegistryBlockWithExternalSecrets(t, yamlSplit[1])
+	})
 }
 
 func assertWithoutImagePullSecrets(t *testing.T, webYaml string) {
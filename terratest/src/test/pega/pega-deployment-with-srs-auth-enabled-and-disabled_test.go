@@ -1,7 +1,7 @@
 package pega
 
 import (
-	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
 	"path/filepath"
@@ -10,89 +10,69 @@ import (
 )
 
 func TestPegaDeploymentWithSRSDisabled(t *testing.T) {
-	var supportedVendors = []string{"k8s", "eks", "gke", "aks", "pks", "openshift"}
-	var supportedOperations = []string{"deploy", "install-deploy"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-		for _, operation := range supportedOperations {
-
-			var options = &helm.Options{
-				SetValues: map[string]string{
-					"global.provider":        vendor,
-					"global.actions.execute": operation,
-				},
-			}
-			deploymentYaml := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-deployment.yaml"})
-			deployments := strings.Split(deploymentYaml, "---")
-			for _, deployment := range deployments {
-				assertNoSRSAuthSettings(t, deployment)
-			}
+	helmtest.Matrix{
+		Providers: []string{"k8s", "eks", "gke", "aks", "pks", "openshift"},
+		Actions:   []string{"deploy", "install-deploy"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(nil)
+		deploymentYaml := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-deployment.yaml"})
+		deployments := strings.Split(deploymentYaml, "---")
+		for _, deployment := range deployments {
+			assertNoSRSAuthSettings(t, deployment)
 		}
-	}
+	})
 }
 
 func TestPegaDeploymentWithSRSAuthDisabled(t *testing.T) {
-	var supportedVendors = []string{"k8s", "eks", "gke", "aks", "pks", "openshift"}
-	var supportedOperations = []string{"deploy", "install-deploy"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-		for _, operation := range supportedOperations {
-
-			var options = &helm.Options{
-				SetValues: map[string]string{
-					"global.provider":                  vendor,
-					"global.actions.execute":           operation,
-					"pegasearch.externalSearchService": "true",
-				},
-			}
-			deploymentYaml := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-deployment.yaml"})
-			deployments := strings.Split(deploymentYaml, "---")
-			for _, deployment := range deployments {
-				assertNoSRSAuthSettings(t, deployment)
-			}
+	helmtest.Matrix{
+		Providers: []string{"k8s", "eks", "gke", "aks", "pks", "openshift"},
+		Actions:   []string{"deploy", "install-deploy"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"pegasearch.externalSearchService": "true",
+		})
+		deploymentYaml := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-deployment.yaml"})
+		deployments := strings.Split(deploymentYaml, "---")
+		for _, deployment := range deployments {
+			assertNoSRSAuthSettings(t, deployment)
 		}
-	}
+	})
 }
 
 func TestPegaDeploymentWithSRSAuthEnabled(t *testing.T) {
-	var supportedVendors = []string{"k8s", "eks", "gke", "aks", "pks", "openshift"}
-	var supportedOperations = []string{"deploy", "install-deploy"}
 	var supportedSrsAuthTypes = []string{"", "private_key_jwt", "client_secret_basic"}
 	var supportedExternalSecrets = []string{"", "test-external-secret"}
 
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-		for _, operation := range supportedOperations {
-			for _, authType := range supportedSrsAuthTypes {
-				for _, externalSecret := range supportedExternalSecrets {
-					var options = &helm.Options{
-						SetValues: map[string]string{
-							"global.provider":                          vendor,
-							"global.actions.execute":                   operation,
-							"pegasearch.externalSearchService":         "true",
-							"pegasearch.srsAuth.enabled":               "true",
-							"pegasearch.srsAuth.privateKey":            SRSAuthPrivateKeyExample,
-							"pegasearch.srsAuth.authType":              authType,
-							"pegasearch.srsAuth.external_secret_name":  externalSecret,
-						},
-					}
-					deploymentYaml := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-deployment.yaml"})
-					deployments := strings.Split(deploymentYaml, "---")
-					for _, deployment := range deployments {
-						assertHasSRSAuthSettings(t, deployment, authType, externalSecret)
-					}
+	helmtest.Matrix{
+		Providers: helmtest.SupportedProviders,
+		Actions:   []string{"deploy", "install-deploy"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		for _, authType := range supportedSrsAuthTypes {
+			for _, externalSecret := range supportedExternalSecrets {
+				options := combination.HelmOptions(map[string]string{
+					"pegasearch.externalSearchService":        "true",
+					"pegasearch.srsAuth.enabled":              "true",
+					"pegasearch.srsAuth.privateKey":           SRSAuthPrivateKeyExample,
+					"pegasearch.srsAuth.authType":             authType,
+					"pegasearch.srsAuth.external_secret_name": externalSecret,
+				})
+				deploymentYaml := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-deployment.yaml"})
+				deployments := strings.Split(deploymentYaml, "---")
+				for _, deployment := range deployments {
+					assertHasSRSAuthSettings(t, deployment, authType, externalSecret)
 				}
 			}
 		}
-	}
+	})
 }
 
 func assertNoSRSAuthSettings(t *testing.T, pegaTierDeployment string) {
@@ -142,7 +122,7 @@ func assertHasSRSAuthSettings(t *testing.T, pegaTierDeployment string, authType
 			require.True(t, hasPrivateKey, "container '"+container.Name+"' should have 'SERV_AUTH_PRIVATE_KEY' environment variable")
 			require.False(t, hasClientPrivateKey, "container '"+container.Name+"' should not have 'SERV_AUTH_CLIENT_SECRET' environment variable")
 		}
-		
+
 		if authType == "client_secret_basic" {
 			require.True(t, hasClientPrivateKey, "container '"+container.Name+"' should have 'SERV_AUTH_CLIENT_SECRET' environment variable")
 			require.False(t, hasPrivateKey, "container '"+container.Name+"' should not have 'SERV_AUTH_PRIVATE_KEY' environment variable")
@@ -0,0 +1,25 @@
+package pega
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+)
+
+var (
+	renderCount = flag.Int("renders", 3, "number of times the determinism tests render every scenario")
+	renderSeed  = flag.Int64("render-seed", 0, "seed shuffling the values files of the determinism tests, random when 0")
+)
+
+// TestPegaDeterministicRendering - renders every golden scenario several times with shuffled values files, which must
+// give byte-identical output; set the number of renders with -renders
+func TestPegaDeterministicRendering(t *testing.T) {
+	for _, scenario := range pegaGoldenScenarios() {
+		scenario := scenario
+		t.Run(scenario.ScenarioName(), func(t *testing.T) {
+			t.Parallel()
+			helmtest.AssertDeterministicRendering(t, scenario, *renderCount, *renderSeed)
+		})
+	}
+}
@@ -1,11 +1,10 @@
 package pega
 
 import (
-	"fmt"
 	"path/filepath"
 	"testing"
 
-	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	k8score "k8s.io/api/core/v1"
 )
@@ -16,103 +15,64 @@ const diagnosticGlobalUser = "globaluser"
 const diagnosticGlobalPassword = "globalpass"
 
 func TestWebTierPegaDiagnosticSecret(t *testing.T) {
-	var supportedVendors = []string{"k8s", "openshift", "eks", "gke", "aks", "pks"}
-	var supportedOperations = []string{"install-deploy", "deploy", "upgrade-deploy"}
-	var deploymentNames = []string{"pega", "myapp-dev"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-
-		for _, operation := range supportedOperations {
-
-			for _, depName := range deploymentNames {
-				fmt.Println(vendor + "-" + operation)
-
-				var options = &helm.Options{
-					ValuesFiles: []string{"data/values_with_tier_diagnostic_user.yaml"},
-					SetValues: map[string]string{
-						"global.deployment.name":        depName,
-						"global.provider":               vendor,
-						"global.actions.execute":        operation,
-						"installer.upgrade.upgradeType": getUpgradeTypeForUpgradeAction(operation),
-					},
-				}
-
-				yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-diagnostic-secret.yaml"})
-				verifyDiagnosticSecret(t, yamlContent, diagnosticWebUser, diagnosticWebPassword)
-			}
-		}
-	}
+	helmtest.Matrix{
+		Providers:       helmtest.SupportedProviders,
+		Actions:         []string{"install-deploy", "deploy", "upgrade-deploy"},
+		DeploymentNames: []string{"pega", "myapp-dev"},
+		ValuesFiles:     []string{"data/values_with_tier_diagnostic_user.yaml"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"installer.upgrade.upgradeType": getUpgradeTypeForUpgradeAction(combination.Action),
+		})
+
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-diagnostic-secret.yaml"})
+		verifyDiagnosticSecret(t, yamlContent, diagnosticWebUser, diagnosticWebPassword)
+	})
 }
 
 func TestGlobalPegaDiagnosticSecret(t *testing.T) {
-	var supportedVendors = []string{"k8s", "openshift", "eks", "gke", "aks", "pks"}
-	var supportedOperations = []string{"install-deploy", "deploy", "upgrade-deploy"}
-	var deploymentNames = []string{"pega", "myapp-dev"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-
-		for _, operation := range supportedOperations {
-
-			for _, depName := range deploymentNames {
-				fmt.Println(vendor + "-" + operation)
-
-				var options = &helm.Options{
-					ValuesFiles: []string{"data/values_with_global_diagnostic_user.yaml"},
-					SetValues: map[string]string{
-						"global.deployment.name":        depName,
-						"global.provider":               vendor,
-						"global.actions.execute":        operation,
-						"installer.upgrade.upgradeType": getUpgradeTypeForUpgradeAction(operation),
-					},
-				}
-
-				yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-diagnostic-secret.yaml"})
-				verifyDiagnosticSecret(t, yamlContent, diagnosticGlobalUser, diagnosticGlobalPassword)
-			}
-		}
-	}
+	helmtest.Matrix{
+		Providers:       helmtest.SupportedProviders,
+		Actions:         []string{"install-deploy", "deploy", "upgrade-deploy"},
+		DeploymentNames: []string{"pega", "myapp-dev"},
+		ValuesFiles:     []string{"data/values_with_global_diagnostic_user.yaml"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"installer.upgrade.upgradeType": getUpgradeTypeForUpgradeAction(combination.Action),
+		})
+
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-diagnostic-secret.yaml"})
+		verifyDiagnosticSecret(t, yamlContent, diagnosticGlobalUser, diagnosticGlobalPassword)
+	})
 }
 
 func TestNoPegaDiagnosticSecret(t *testing.T) {
-	var supportedVendors = []string{"k8s", "openshift", "eks", "gke", "aks", "pks"}
-	var supportedOperations = []string{"install-deploy", "deploy", "upgrade-deploy"}
-	var deploymentNames = []string{"pega", "myapp-dev"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-
-		for _, operation := range supportedOperations {
-
-			for _, depName := range deploymentNames {
-				fmt.Println(vendor + "-" + operation)
-
-				var options = &helm.Options{
-					ValuesFiles: []string{"data/values_with_no_diagnostic_user.yaml"},
-					SetValues: map[string]string{
-						"global.deployment.name":        depName,
-						"global.provider":               vendor,
-						"global.actions.execute":        operation,
-						"installer.upgrade.upgradeType": getUpgradeTypeForUpgradeAction(operation),
-					},
-				}
-
-				yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-diagnostic-secret.yaml"})
-				var secretobj k8score.Secret
-				UnmarshalK8SYaml(t, yamlContent, &secretobj)
-				secretData := secretobj.Data
-				require.Nil(t, secretData["PEGA_DIAGNOSTIC_USER"])
-				require.Nil(t, secretData["PEGA_DIAGNOSTIC_PASSWORD"])
-			}
-		}
-	}
+	helmtest.Matrix{
+		Providers:       helmtest.SupportedProviders,
+		Actions:         []string{"install-deploy", "deploy", "upgrade-deploy"},
+		DeploymentNames: []string{"pega", "myapp-dev"},
+		ValuesFiles:     []string{"data/values_with_no_diagnostic_user.yaml"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"installer.upgrade.upgradeType": getUpgradeTypeForUpgradeAction(combination.Action),
+		})
+
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-diagnostic-secret.yaml"})
+		var secretobj k8score.Secret
+		UnmarshalK8SYaml(t, yamlContent, &secretobj)
+		secretData := secretobj.Data
+		require.Nil(t, secretData["PEGA_DIAGNOSTIC_USER"])
+		require.Nil(t, secretData["PEGA_DIAGNOSTIC_PASSWORD"])
+	})
 }
 
 func verifyDiagnosticSecret(t *testing.T, yamlContent string, user string, password string) {
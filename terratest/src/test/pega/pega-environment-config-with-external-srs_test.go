@@ -1,8 +1,7 @@
 package pega
 
 import (
-	"fmt"
-	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	k8score "k8s.io/api/core/v1"
 	"path/filepath"
@@ -14,149 +13,109 @@ const DefaultSRSAuthScope = "pega.search:full"
 const DefaultPrivateKeyAlgorithm = "RS256"
 
 func TestPegaConfigWithoutSRS(t *testing.T) {
-	var supportedVendors = []string{"k8s", "openshift", "eks", "gke", "aks", "pks"}
-	var supportedOperations = []string{"deploy", "install-deploy"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-		for _, operation := range supportedOperations {
-			fmt.Println(vendor + "-" + operation)
-
-			var options = &helm.Options{
-				SetValues: map[string]string{
-					"global.provider":                  vendor,
-					"global.actions.execute":           operation,
-					"pegasearch.externalSearchService": "false",
-					"pegasearch.externalURL":           "https://srs:9200",
-				},
-			}
-
-			yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-environment-config.yaml"})
-			VerifyPegaWithoutExternalSRSEnvironmentConfig(t, yamlContent)
-		}
-	}
+	helmtest.Matrix{
+		Providers: helmtest.SupportedProviders,
+		Actions:   []string{"deploy", "install-deploy"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"pegasearch.externalSearchService": "false",
+			"pegasearch.externalURL":           "https://srs:9200",
+		})
+
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-environment-config.yaml"})
+		VerifyPegaWithoutExternalSRSEnvironmentConfig(t, yamlContent)
+	})
 }
 
 func TestPegaConfigWithSRSAndAuthDisabled(t *testing.T) {
-	var supportedVendors = []string{"k8s", "openshift", "eks", "gke", "aks", "pks"}
-	var supportedOperations = []string{"deploy", "install-deploy"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-		for _, operation := range supportedOperations {
-			fmt.Println(vendor + "-" + operation)
-
-			var options = &helm.Options{
-				SetValues: map[string]string{
-					"global.provider":                  vendor,
-					"global.actions.execute":           operation,
-					"pegasearch.externalSearchService": "true",
-					"pegasearch.externalURL":           "https://srs:9200",
-				},
-			}
-
-			yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-environment-config.yaml"})
-			VerifyPegaWithExternalSRSEnvironmentConfig(t, yamlContent, false, "", "")
-		}
-	}
+	helmtest.Matrix{
+		Providers: helmtest.SupportedProviders,
+		Actions:   []string{"deploy", "install-deploy"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"pegasearch.externalSearchService": "true",
+			"pegasearch.externalURL":           "https://srs:9200",
+		})
+
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-environment-config.yaml"})
+		VerifyPegaWithExternalSRSEnvironmentConfig(t, yamlContent, false, "", "")
+	})
 }
 
 func TestPegaConfigWithSRSAndAuthEnabledAndAllAuthParametersProvided(t *testing.T) {
-	var supportedVendors = []string{"k8s", "openshift", "eks", "gke", "aks", "pks"}
-	var supportedOperations = []string{"deploy", "install-deploy"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-		for _, operation := range supportedOperations {
-			fmt.Println(vendor + "-" + operation)
-
-			var options = &helm.Options{
-				SetValues: map[string]string{
-					"global.provider":                        vendor,
-					"global.actions.execute":                 operation,
-					"pegasearch.externalSearchService":       "true",
-					"pegasearch.externalURL":                 "https://srs:9200",
-					"pegasearch.srsAuth.enabled":             "true",
-					"pegasearch.srsAuth.url":                 "https://auth-service",
-					"pegasearch.srsAuth.clientId":            "client-id",
-					"pegasearch.srsAuth.scopes":              "srs-scope",
-					"pegasearch.srsAuth.privateKey":          SRSAuthPrivateKeyExample,
-					"pegasearch.srsAuth.privateKeyAlgorithm": "RS512",
-				},
-			}
-
-			yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-environment-config.yaml"})
-			VerifyPegaWithExternalSRSEnvironmentConfig(t, yamlContent, true, "RS512", "srs-scope")
-		}
-	}
+	helmtest.Matrix{
+		Providers: helmtest.SupportedProviders,
+		Actions:   []string{"deploy", "install-deploy"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"pegasearch.externalSearchService":       "true",
+			"pegasearch.externalURL":                 "https://srs:9200",
+			"pegasearch.srsAuth.enabled":             "true",
+			"pegasearch.srsAuth.url":                 "https://auth-service",
+			"pegasearch.srsAuth.clientId":            "client-id",
+			"pegasearch.srsAuth.scopes":              "srs-scope",
+			"pegasearch.srsAuth.privateKey":          SRSAuthPrivateKeyExample,
+			"pegasearch.srsAuth.privateKeyAlgorithm": "RS512",
+		})
+
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-environment-config.yaml"})
+		VerifyPegaWithExternalSRSEnvironmentConfig(t, yamlContent, true, "RS512", "srs-scope")
+	})
 }
 
 func TestPegaConfigWithSRSAndAuthEnabledAndAlgorithmNotProvided(t *testing.T) {
-	var supportedVendors = []string{"k8s", "openshift", "eks", "gke", "aks", "pks"}
-	var supportedOperations = []string{"deploy", "install-deploy"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-		for _, operation := range supportedOperations {
-			fmt.Println(vendor + "-" + operation)
-
-			var options = &helm.Options{
-				SetValues: map[string]string{
-					"global.provider":                  vendor,
-					"global.actions.execute":           operation,
-					"pegasearch.externalSearchService": "true",
-					"pegasearch.externalURL":           "https://srs:9200",
-					"pegasearch.srsAuth.enabled":       "true",
-					"pegasearch.srsAuth.url":           "https://auth-service",
-					"pegasearch.srsAuth.clientId":      "client-id",
-					"pegasearch.srsAuth.scopes":        "srs-scope",
-					"pegasearch.srsAuth.privateKey":    SRSAuthPrivateKeyExample,
-				},
-			}
-
-			yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-environment-config.yaml"})
-			VerifyPegaWithExternalSRSEnvironmentConfig(t, yamlContent, true, DefaultPrivateKeyAlgorithm, "srs-scope")
-		}
-	}
+	helmtest.Matrix{
+		Providers: helmtest.SupportedProviders,
+		Actions:   []string{"deploy", "install-deploy"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"pegasearch.externalSearchService": "true",
+			"pegasearch.externalURL":           "https://srs:9200",
+			"pegasearch.srsAuth.enabled":       "true",
+			"pegasearch.srsAuth.url":           "https://auth-service",
+			"pegasearch.srsAuth.clientId":      "client-id",
+			"pegasearch.srsAuth.scopes":        "srs-scope",
+			"pegasearch.srsAuth.privateKey":    SRSAuthPrivateKeyExample,
+		})
+
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-environment-config.yaml"})
+		VerifyPegaWithExternalSRSEnvironmentConfig(t, yamlContent, true, DefaultPrivateKeyAlgorithm, "srs-scope")
+	})
 }
 
 func TestPegaConfigWithSRSAndAuthEnabledAndScopeNotProvided(t *testing.T) {
-	var supportedVendors = []string{"k8s", "openshift", "eks", "gke", "aks", "pks"}
-	var supportedOperations = []string{"deploy", "install-deploy"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-		for _, operation := range supportedOperations {
-			fmt.Println(vendor + "-" + operation)
-
-			var options = &helm.Options{
-				SetValues: map[string]string{
-					"global.provider":                        vendor,
-					"global.actions.execute":                 operation,
-					"pegasearch.externalSearchService":       "true",
-					"pegasearch.externalURL":                 "https://srs:9200",
-					"pegasearch.srsAuth.enabled":             "true",
-					"pegasearch.srsAuth.url":                 "https://auth-service",
-					"pegasearch.srsAuth.clientId":            "client-id",
-					"pegasearch.srsAuth.privateKeyAlgorithm": "RS384",
-					"pegasearch.srsAuth.privateKey":          SRSAuthPrivateKeyExample,
-				},
-			}
-
-			yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-environment-config.yaml"})
-			VerifyPegaWithExternalSRSEnvironmentConfig(t, yamlContent, true, "RS384", DefaultSRSAuthScope)
-		}
-	}
+	helmtest.Matrix{
+		Providers: helmtest.SupportedProviders,
+		Actions:   []string{"deploy", "install-deploy"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"pegasearch.externalSearchService":       "true",
+			"pegasearch.externalURL":                 "https://srs:9200",
+			"pegasearch.srsAuth.enabled":             "true",
+			"pegasearch.srsAuth.url":                 "https://auth-service",
+			"pegasearch.srsAuth.clientId":            "client-id",
+			"pegasearch.srsAuth.privateKeyAlgorithm": "RS384",
+			"pegasearch.srsAuth.privateKey":          SRSAuthPrivateKeyExample,
+		})
+
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-environment-config.yaml"})
+		VerifyPegaWithExternalSRSEnvironmentConfig(t, yamlContent, true, "RS384", DefaultSRSAuthScope)
+	})
 }
 
 func VerifyPegaWithExternalSRSEnvironmentConfig(t *testing.T, yamlContent string, isAuthEnabled bool, expectedAlgorithm string, expectedScope string) {
@@ -1,8 +1,8 @@
 package pega
 
 import (
-	"fmt"
 	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	k8score "k8s.io/api/core/v1"
 	"path/filepath"
@@ -10,78 +10,50 @@ import (
 	"testing"
 )
 
-func TestPegaHazelcastEnvironmentConfigForClient(t *testing.T){
-	var supportedVendors = []string{"k8s","openshift","eks","gke","aks","pks"}
-	var supportedOperations =  []string{"deploy","install-deploy"}
-
+func TestPegaHazelcastEnvironmentConfigForClient(t *testing.T) {
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-
-	for _,vendor := range supportedVendors{
-
-		for _,operation := range supportedOperations{
-
-			fmt.Println(vendor + "-" + operation)
-
-			var options = &helm.Options{
-				SetValues: map[string]string{
-					"global.provider":        vendor,
-					"global.actions.execute": operation,
-					"hazelcast.enabled": "true",
-					"hazelcast.migration.embeddedToCSMigration": "false",
-				},
-			}
-
-			yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-environment-config.yaml"})
-			VerifyPegaHazelcastEnvironmentConfigForClient(t,yamlContent, options)
-
-		}
-	}
-
-    for _,vendor := range supportedVendors{
-
-        for _,operation := range supportedOperations{
-
-            fmt.Println(vendor + "-" + operation)
-
-            var options = &helm.Options{
-                SetValues: map[string]string{
-                    "global.provider":        vendor,
-                    "global.actions.execute": operation,
-                    "hazelcast.enabled": "false",
-                    "hazelcast.clusteringServiceEnabled": "true",
-                    "hazelcast.migration.embeddedToCSMigration": "false",
-                },
-            }
-
-            yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-environment-config.yaml"})
-            VerifyClusteringServiceEnvironmentConfigForClient(t,yamlContent, options)
-
-        }
-    }
-
-    for _,vendor := range supportedVendors{
-
-        for _,operation := range supportedOperations{
-
-            fmt.Println(vendor + "-" + operation)
-
-            var options = &helm.Options{
-                SetValues: map[string]string{
-                    "global.provider":        vendor,
-                    "global.actions.execute": operation,
-                    "hazelcast.enabled": "false",
-                    "hazelcast.clusteringServiceEnabled": "true",
-                    "hazelcast.migration.embeddedToCSMigration": "false",
-                },
-            }
-
-            yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-environment-config.yaml"})
-            VerifyClusteringServiceEnvironmentConfigForClient(t,yamlContent, options)
-
-        }
-    }
+	helmtest.Matrix{
+		Providers: helmtest.SupportedProviders,
+		Actions:   []string{"deploy", "install-deploy"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"hazelcast.enabled":                         "true",
+			"hazelcast.migration.embeddedToCSMigration": "false",
+		})
+
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-environment-config.yaml"})
+		VerifyPegaHazelcastEnvironmentConfigForClient(t, yamlContent, options)
+	})
+
+	helmtest.Matrix{
+		Providers: helmtest.SupportedProviders,
+		Actions:   []string{"deploy", "install-deploy"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"hazelcast.enabled":                         "false",
+			"hazelcast.clusteringServiceEnabled":        "true",
+			"hazelcast.migration.embeddedToCSMigration": "false",
+		})
+
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-environment-config.yaml"})
+		VerifyClusteringServiceEnvironmentConfigForClient(t, yamlContent, options)
+	})
+
+	helmtest.Matrix{
+		Providers: helmtest.SupportedProviders,
+		Actions:   []string{"deploy", "install-deploy"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"hazelcast.enabled":                         "false",
+			"hazelcast.clusteringServiceEnabled":        "true",
+			"hazelcast.migration.embeddedToCSMigration": "false",
+		})
+
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-environment-config.yaml"})
+		VerifyClusteringServiceEnvironmentConfigForClient(t, yamlContent, options)
+	})
 
 }
 
@@ -115,4 +87,4 @@ func VerifyClusteringServiceEnvironmentConfigForClient(t *testing.T, yamlContent
 			require.Equal(t, envConfigData["HZ_SERVER_HOSTNAME"], "clusteringservice-service.default.svc.cluster.local")
 		}
 	}
-}
\ No newline at end of file
+}
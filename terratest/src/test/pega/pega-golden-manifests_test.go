@@ -0,0 +1,57 @@
+package pega
+
+import (
+	"flag"
+	"path/filepath"
+	"testing"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "regenerate golden manifests instead of comparing against them")
+
+// cassandra is fetched from a remote repository at build time, so it is left out of the snapshots
+func goldenScenarioValues(action string) map[string]string {
+	return map[string]string{
+		"cassandra.enabled":             "false",
+		"installer.upgrade.upgradeType": getUpgradeTypeForUpgradeAction(action),
+	}
+}
+
+var goldenScenarios = []helmtest.Scenario{
+	{Provider: "k8s", Action: "deploy"},
+	{Provider: "openshift", Action: "install-deploy"},
+	{Provider: "eks", Action: "upgrade-deploy"},
+	{Provider: "gke", Action: "deploy"},
+	{Provider: "aks", Action: "install"},
+	{Provider: "pks", Action: "upgrade"},
+	{Provider: "k8s", Action: "deploy", ValuesFiles: []string{helmtest.ChartPath(helmtest.PegaChart) + "/values-large.yaml"}},
+	{Provider: "k8s", Action: "deploy", ValuesFiles: []string{helmtest.ChartPath(helmtest.PegaChart) + "/values-minimal.yaml"}},
+}
+
+// pegaGoldenScenarios returns goldenScenarios ready to render
+func pegaGoldenScenarios() []helmtest.Scenario {
+	var scenarios []helmtest.Scenario
+	for _, scenario := range goldenScenarios {
+		scenario.Chart = helmtest.PegaChart
+		scenario.KubeVersion = "1.27.0"
+		scenario.SetValues = goldenScenarioValues(scenario.Action)
+		scenarios = append(scenarios, scenario)
+	}
+	return scenarios
+}
+
+// TestPegaGoldenManifests - compares the complete rendered chart with pega/data/golden; run with -update to regenerate
+func TestPegaGoldenManifests(t *testing.T) {
+	goldenPath, err := filepath.Abs(PegaHelmChartTestsPath + "/data/golden")
+	require.NoError(t, err)
+
+	for _, scenario := range pegaGoldenScenarios() {
+		scenario := scenario
+		t.Run(scenario.ScenarioName(), func(t *testing.T) {
+			t.Parallel()
+			helmtest.AssertGoldenManifests(t, goldenPath, scenario, *updateGolden)
+		})
+	}
+}
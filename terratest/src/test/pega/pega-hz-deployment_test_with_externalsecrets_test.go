@@ -1,7 +1,7 @@
 package pega
 
 import (
-	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	appsv1beta2 "k8s.io/api/apps/v1beta2"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -11,30 +11,21 @@ import (
 )
 
 func TestHazelcastDeploymentWithExternalsecrets(t *testing.T) {
-	var supportedVendors = []string{"k8s", "openshift", "eks", "gke", "aks", "pks"}
-	var supportedOperations = []string{"deploy", "install-deploy"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-
-		for _, operation := range supportedOperations {
-
-			var options = &helm.Options{
-				ValuesFiles: []string{"data/values_with_externalsecrets.yaml"},
-				SetValues: map[string]string{
-					"global.provider":        vendor,
-					"global.actions.execute": operation,
-					"hazelcast.enabled":      "true",
-				},
-			}
-
-			yamlContent := RenderTemplate(t, options, helmChartPath, []string{"charts/hazelcast/templates/pega-hz-deployment.yaml"})
-			VerifyHazelcastDeploymentWithExternalsecrets(t, yamlContent)
-
-		}
-	}
+	helmtest.Matrix{
+		Providers:   helmtest.SupportedProviders,
+		Actions:     []string{"deploy", "install-deploy"},
+		ValuesFiles: []string{"data/values_with_externalsecrets.yaml"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"hazelcast.enabled": "true",
+		})
+
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"charts/hazelcast/templates/pega-hz-deployment.yaml"})
+		VerifyHazelcastDeploymentWithExternalsecrets(t, yamlContent)
+	})
 }
 
 func VerifyHazelcastDeploymentWithExternalsecrets(t *testing.T, yamlContent string) {
@@ -1,8 +1,7 @@
 package pega
 
 import (
-	"fmt"
-	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	k8score "k8s.io/api/core/v1"
 	"path/filepath"
@@ -10,64 +9,39 @@ import (
 )
 
 func TestPegaHazelcastSecretWhenHazelcastIsEnabled(t *testing.T) {
-	var supportedVendors = []string{"k8s", "openshift", "eks", "gke", "aks", "pks"}
 	const HzCsAuthUsername = "HZClusterUser"
 	const HzCsAuthPassword = "HZclusterPassword"
-	var supportedOperations = []string{"deploy", "install-deploy", "upgrade-deploy"}
-	var deploymentNames = []string{"pega", "myapp-dev"}
 
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-
-		for _, operation := range supportedOperations {
-
-			for _, depName := range deploymentNames {
-				fmt.Println(vendor + "-" + operation)
-
-				var options = &helm.Options{
-					SetValues: map[string]string{
-						"global.deployment.name":                    depName,
-						"global.provider":                           vendor,
-						"global.actions.execute":                    operation,
-						"installer.upgrade.upgradeType":             "zero-downtime",
-						"hazelcast.enabled":                         "true",
-						"hazelcast.migration.embeddedToCSMigration": "false",
-						"hazelcast.username":                        HzCsAuthUsername,
-						"hazelcast.password":                        HzCsAuthPassword,
-					},
-				}
-
-				yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-hz-secret.yaml"})
-				VerifyHazelcastSecretWhenHazelcastIsEnabled(t, yamlContent, HzCsAuthUsername, HzCsAuthPassword)
-			}
-		}
-	}
-
-	for _, vendor := range supportedVendors {
-
-		for _, operation := range supportedOperations {
-
-			fmt.Println(vendor + "-" + operation)
-
-			var options = &helm.Options{
-				SetValues: map[string]string{
-					"global.provider":                           vendor,
-					"global.actions.execute":                    operation,
-					"installer.upgrade.upgradeType":             "zero-downtime",
-					"hazelcast.enabled":                         "false",
-					"hazelcast.clusteringServiceEnabled":        "true",
-					"hazelcast.migration.embeddedToCSMigration": "false",
-					"hazelcast.username":                        HzCsAuthUsername,
-					"hazelcast.password":                        HzCsAuthPassword,
-				},
-			}
-
-			yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-hz-secret.yaml"})
-			VerifyHazelcastSecretWhenHazelcastIsEnabled(t, yamlContent, HzCsAuthUsername, HzCsAuthPassword)
-		}
-	}
+	pegaDeploymentMatrix.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"hazelcast.enabled":                         "true",
+			"hazelcast.migration.embeddedToCSMigration": "false",
+			"hazelcast.username":                        HzCsAuthUsername,
+			"hazelcast.password":                        HzCsAuthPassword,
+		})
+
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-hz-secret.yaml"})
+		VerifyHazelcastSecretWhenHazelcastIsEnabled(t, yamlContent, HzCsAuthUsername, HzCsAuthPassword)
+	})
+
+	helmtest.Matrix{
+		Providers: helmtest.SupportedProviders,
+		Actions:   helmtest.SupportedDeployActions,
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"hazelcast.enabled":                         "false",
+			"hazelcast.clusteringServiceEnabled":        "true",
+			"hazelcast.migration.embeddedToCSMigration": "false",
+			"hazelcast.username":                        HzCsAuthUsername,
+			"hazelcast.password":                        HzCsAuthPassword,
+		})
+
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-hz-secret.yaml"})
+		VerifyHazelcastSecretWhenHazelcastIsEnabled(t, yamlContent, HzCsAuthUsername, HzCsAuthPassword)
+	})
 
 }
 
@@ -1,35 +1,29 @@
 package pega
 
 import (
-	"path/filepath"
-	"testing"
-    "strings"
 	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	k8score "k8s.io/api/core/v1"
+	"path/filepath"
+	"strings"
+	"testing"
 )
 
 func TestPegaInstallEnvironmentConfig(t *testing.T) {
 
-	var supportedVendors = []string{"k8s", "openshift", "eks", "gke", "aks", "pks"}
-	var supportedOperations = []string{"install", "install-deploy"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-		for _, operation := range supportedOperations {
-			var options = &helm.Options{
-				SetValues: map[string]string{
-					"global.provider":        vendor,
-					"global.actions.execute": operation,
-				},
-			}
+	helmtest.Matrix{
+		Providers: helmtest.SupportedProviders,
+		Actions:   []string{"install", "install-deploy"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(nil)
 
-			yamlContent := RenderTemplate(t, options, helmChartPath, []string{"charts/installer/templates/pega-install-environment-config.yaml"})
-			assertInstallerEnvironmentConfig(t, yamlContent, options)
-		}
-	}
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"charts/installer/templates/pega-install-environment-config.yaml"})
+		assertInstallerEnvironmentConfig(t, yamlContent, options)
+	})
 }
 
 func assertInstallerEnvironmentConfig(t *testing.T, configYaml string, options *helm.Options) {
@@ -58,13 +52,13 @@ func assertInstallerEnvironmentConfig(t *testing.T, configYaml string, options *
 	require.Equal(t, installEnvConfigData["DB2ZOS_UDF_WLM"], "")
 	require.Equal(t, installEnvConfigData["DISTRIBUTION_KIT_URL"], "")
 	require.Equal(t, "", installEnvConfigData["DISTRIBUTION_KIT_URL"])
-    require.Equal(t, installEnvConfigData["ENABLE_CUSTOM_ARTIFACTORY_SSL_VERIFICATION"], "true")
+	require.Equal(t, installEnvConfigData["ENABLE_CUSTOM_ARTIFACTORY_SSL_VERIFICATION"], "true")
 
-    assertNoDupesInConfigMap(t, configYaml, &installEnvConfigMap)
+	assertNoDupesInConfigMap(t, configYaml, &installEnvConfigMap)
 }
 
 func assertNoDupesInConfigMap(t *testing.T, configYaml string, cm *k8score.ConfigMap) {
-    for key := range cm.Data {
-       require.Equal(t, 1, strings.Count(configYaml, " " + key + ": "))
-    }
-}
\ No newline at end of file
+	for key := range cm.Data {
+		require.Equal(t, 1, strings.Count(configYaml, " "+key+": "))
+	}
+}
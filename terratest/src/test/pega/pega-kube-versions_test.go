@@ -0,0 +1,102 @@
+package pega
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	k8score "k8s.io/api/core/v1"
+)
+
+// TestPegaKubeVersionAPIChoices - renders the chart for every supported Kubernetes version and verifies the API
+// versions and fields chosen through .Capabilities.KubeVersion
+func TestPegaKubeVersionAPIChoices(t *testing.T) {
+	testsPath, err := filepath.Abs(PegaHelmChartTestsPath)
+	require.NoError(t, err)
+
+	matrix := helmtest.Matrix{
+		Providers:    helmtest.SupportedProviders,
+		Actions:      []string{"deploy"},
+		KubeVersions: helmtest.SupportedKubeVersions,
+	}
+	matrix.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := pegaOptions(combination, nil)
+		helmTest := newPegaChartTest(t, options, testsPath+"/data/values_kube_versions.yaml").WithKubeVersion(combination.KubeVersion)
+		parser := helmtest.NewHelmConfigParser(helmTest)
+		atLeast := func(minor int) bool { return helmtest.KubeVersionAtLeast(combination.KubeVersion, 1, minor) }
+
+		expectedPDBVersion := "policy/v1beta1"
+		if atLeast(21) {
+			expectedPDBVersion = "policy/v1"
+		}
+		for _, pdb := range parser.Query(helmtest.ResourceQuery{Kind: "PodDisruptionBudget"}) {
+			require.Equal(t, expectedPDBVersion, pdb.APIVersion, pdb.Name)
+		}
+
+		expectedHPAVersion := "autoscaling/v2beta2"
+		if atLeast(23) {
+			expectedHPAVersion = "autoscaling/v2"
+		}
+		require.Equal(t, expectedHPAVersion, parser.QueryOne(helmtest.ResourceQuery{Kind: "HorizontalPodAutoscaler"}).APIVersion)
+
+		web := helmtest.QueryAs[appsv1.Deployment](parser, helmtest.ResourceQuery{Kind: "Deployment", Name: "pega-web"})
+		require.Len(t, web, 1)
+		pegaContainer := web[0].Spec.Template.Spec.Containers[0]
+		if atLeast(18) {
+			require.NotNil(t, pegaContainer.StartupProbe)
+			require.Equal(t, int32(0), pegaContainer.LivenessProbe.InitialDelaySeconds)
+		} else {
+			require.Nil(t, pegaContainer.StartupProbe)
+			require.Equal(t, int32(200), pegaContainer.LivenessProbe.InitialDelaySeconds)
+		}
+
+		if combination.Provider != "openshift" {
+			expectedIngressVersion := "extensions/v1beta1"
+			if atLeast(19) {
+				expectedIngressVersion = "networking.k8s.io/v1"
+			}
+			for _, ingress := range parser.Query(helmtest.ResourceQuery{Kind: "Ingress"}) {
+				require.Equal(t, expectedIngressVersion, ingress.APIVersion, ingress.Name)
+			}
+		}
+
+		if combination.Provider == "gke" {
+			expectedCertificateVersion := "networking.gke.io/v1beta1"
+			if atLeast(19) {
+				expectedCertificateVersion = "networking.gke.io/v1"
+			}
+			require.Equal(t, expectedCertificateVersion, parser.QueryOne(helmtest.ResourceQuery{Kind: "ManagedCertificate"}).APIVersion)
+
+			var service k8score.Service
+			parser.Find(helmtest.SearchResourceOption{Name: "pega-web", Kind: "Service"}, &service)
+			backendConfigAnnotation := "cloud.google.com/backend-config"
+			if !atLeast(16) {
+				backendConfigAnnotation = "beta." + backendConfigAnnotation
+			}
+			require.Contains(t, service.Annotations, backendConfigAnnotation)
+		}
+	})
+}
+
+// TestPegaInstallerPDBKubeVersions - the installer PodDisruptionBudget uses policy/v1beta1 on Kubernetes versions that
+// do not serve policy/v1
+func TestPegaInstallerPDBKubeVersions(t *testing.T) {
+	matrix := helmtest.Matrix{
+		Providers:    []string{"k8s"},
+		Actions:      []string{"install", "upgrade-deploy"},
+		KubeVersions: helmtest.SupportedKubeVersions,
+	}
+	matrix.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := pegaOptions(combination, nil)
+		helmTest := newPegaChartTest(t, options).WithKubeVersion(combination.KubeVersion)
+		pdb := helmtest.NewHelmConfigParser(helmTest).QueryOne(helmtest.ResourceQuery{Kind: "PodDisruptionBudget", Name: "installer-job-pdb"})
+
+		expectedPDBVersion := "policy/v1beta1"
+		if helmtest.KubeVersionAtLeast(combination.KubeVersion, 1, 21) {
+			expectedPDBVersion = "policy/v1"
+		}
+		require.Equal(t, expectedPDBVersion, pdb.APIVersion)
+	})
+}
@@ -0,0 +1,231 @@
+package pega
+
+import (
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+	"github.com/stretchr/testify/require"
+	k8score "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// renderNetworkPolicies - renders the whole pega chart and returns its parser and NetworkPolicies
+func renderNetworkPolicies(t *testing.T, options *helm.Options, valuesFiles ...string) (*helmtest.HelmChartParser, []networkingv1.NetworkPolicy) {
+	helmTest := newPegaChartTest(t, options, valuesFiles...)
+	parser := helmtest.NewHelmConfigParser(helmTest)
+	return parser, helmtest.QueryAs[networkingv1.NetworkPolicy](parser, helmtest.ResourceQuery{Kind: "NetworkPolicy"})
+}
+
+// podWorkloads - the Deployments and StatefulSets of the release
+func podWorkloads(parser *helmtest.HelmChartParser) []tierWorkload {
+	var workloads []tierWorkload
+	for _, kind := range []string{"Deployment", "StatefulSet"} {
+		workloads = append(workloads, helmtest.QueryAs[tierWorkload](parser, helmtest.ResourceQuery{Kind: kind})...)
+	}
+	return workloads
+}
+
+// selectedWorkloads - the workloads whose pods the selector matches
+func selectedWorkloads(t *testing.T, workloads []tierWorkload, selector *metav1.LabelSelector) []tierWorkload {
+	podSelector, err := metav1.LabelSelectorAsSelector(selector)
+	require.NoError(t, err)
+	var selected []tierWorkload
+	for _, workload := range workloads {
+		if podSelector.Matches(labels.Set(workload.Spec.Template.Labels)) {
+			selected = append(selected, workload)
+		}
+	}
+	return selected
+}
+
+// servesPort - a container of the workload declares the port, or a Service selecting its pods targets it
+func servesPort(workload tierWorkload, services []k8score.Service, port intstr.IntOrString) bool {
+	for _, container := range workload.Spec.Template.Spec.Containers {
+		for _, containerPort := range container.Ports {
+			if port == intstr.FromInt(int(containerPort.ContainerPort)) || port == intstr.FromString(containerPort.Name) {
+				return true
+			}
+		}
+	}
+	for _, service := range services {
+		if len(service.Spec.Selector) == 0 || !labels.SelectorFromSet(service.Spec.Selector).Matches(labels.Set(workload.Spec.Template.Labels)) {
+			continue
+		}
+		for _, servicePort := range service.Spec.Ports {
+			if port == servicePort.TargetPort {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// requirePolicySelectorsMatchPods - every policy selects exactly the pods of the workload it is named after, admits
+// traffic only to ports those pods serve, and every pod peer in the release namespace matches rendered pods that
+// serve the ports of the rule
+func requirePolicySelectorsMatchPods(t *testing.T, parser *helmtest.HelmChartParser, policies []networkingv1.NetworkPolicy) {
+	workloads := podWorkloads(parser)
+	services := helmtest.QueryAs[k8score.Service](parser, helmtest.ResourceQuery{Kind: "Service"})
+
+	requirePeers := func(policy string, peers []networkingv1.NetworkPolicyPeer, ports []networkingv1.NetworkPolicyPort) {
+		for _, peer := range peers {
+			if peer.PodSelector == nil || peer.NamespaceSelector != nil {
+				continue
+			}
+			peerWorkloads := selectedWorkloads(t, workloads, peer.PodSelector)
+			require.NotEmpty(t, peerWorkloads, "NetworkPolicy %s peer %v matches no rendered pods", policy, peer.PodSelector)
+			for _, port := range ports {
+				for _, workload := range peerWorkloads {
+					require.True(t, servesPort(workload, services, *port.Port), "NetworkPolicy %s allows port %s of %s, which does not serve it", policy, port.Port.String(), workload.Metadata.Name)
+				}
+			}
+		}
+	}
+
+	for _, policy := range policies {
+		selected := selectedWorkloads(t, workloads, &policy.Spec.PodSelector)
+		require.Len(t, selected, 1, "NetworkPolicy %s selects %d workloads", policy.Name, len(selected))
+		require.Equal(t, policy.Name, selected[0].Metadata.Name)
+		require.ElementsMatch(t, []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress}, policy.Spec.PolicyTypes)
+
+		for _, rule := range policy.Spec.Ingress {
+			for _, port := range rule.Ports {
+				require.True(t, servesPort(selected[0], services, *port.Port), "NetworkPolicy %s admits port %s, which %s does not serve", policy.Name, port.Port.String(), policy.Name)
+			}
+			requirePeers(policy.Name, rule.From, nil)
+		}
+		for _, rule := range policy.Spec.Egress {
+			require.False(t, len(rule.To) == 0 && len(rule.Ports) == 0, "NetworkPolicy %s has an egress rule open to everything", policy.Name)
+			requirePeers(policy.Name, rule.To, rule.Ports)
+		}
+	}
+}
+
+// egressPorts - the ports of the egress rules of the policy that have no destination, i.e. are open to any address
+func egressPorts(policy networkingv1.NetworkPolicy) []string {
+	var ports []string
+	for _, rule := range policy.Spec.Egress {
+		if len(rule.To) > 0 {
+			continue
+		}
+		for _, port := range rule.Ports {
+			ports = append(ports, port.Port.String())
+		}
+	}
+	sort.Strings(ports)
+	return ports
+}
+
+// TestPegaNetworkPolicySelectors - the policies of the tiers, search and Hazelcast select their own pods, and their
+// peers select rendered pods serving the allowed ports
+func TestPegaNetworkPolicySelectors(t *testing.T) {
+	testsPath, err := filepath.Abs(PegaHelmChartTestsPath)
+	require.NoError(t, err)
+
+	matrix := helmtest.Matrix{
+		Providers:       helmtest.SupportedProviders,
+		Actions:         helmtest.SupportedDeployActions,
+		DeploymentNames: []string{"pega", "myapp-dev"},
+	}
+	matrix.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := pegaOptions(combination, nil)
+		parser, policies := renderNetworkPolicies(t, options, testsPath+"/data/values_network_policy.yaml")
+
+		var names []string
+		for _, policy := range policies {
+			names = append(names, policy.Name)
+		}
+		require.ElementsMatch(t, []string{
+			getObjName(options, "-web"),
+			getObjName(options, "-batch"),
+			getObjName(options, "-stream"),
+			getObjName(options, "-search"),
+			"pega-hazelcast",
+			"clusteringservice",
+		}, names)
+		requirePolicySelectorsMatchPods(t, parser, policies)
+	})
+}
+
+// TestPegaNetworkPolicyTierRules - the tier policy derives its ports and destinations from the configured services
+func TestPegaNetworkPolicyTierRules(t *testing.T) {
+	testsPath, err := filepath.Abs(PegaHelmChartTestsPath)
+	require.NoError(t, err)
+	valuesFile := testsPath + "/data/values_network_policy.yaml"
+
+	t.Run("internal services", func(t *testing.T) {
+		options := pegaOptions(k8sDeploy, nil)
+		parser, _ := renderNetworkPolicies(t, options, valuesFile)
+		var web networkingv1.NetworkPolicy
+		parser.Find(helmtest.SearchResourceOption{Name: "pega-web", Kind: "NetworkPolicy"}, &web)
+
+		require.Equal(t, []networkingv1.NetworkPolicyPeer{{NamespaceSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"kubernetes.io/metadata.name": "ingress-nginx"},
+		}}}, web.Spec.Ingress[1].From)
+		require.Equal(t, []string{"53", "53"}, []string{web.Spec.Egress[0].Ports[0].Port.String(), web.Spec.Egress[0].Ports[1].Port.String()})
+		require.Equal(t, []networkingv1.NetworkPolicyPeer{{IPBlock: &networkingv1.IPBlock{CIDR: "10.20.0.5/32"}}}, web.Spec.Egress[len(web.Spec.Egress)-1].To)
+		require.Equal(t, "5432", web.Spec.Egress[len(web.Spec.Egress)-1].Ports[0].Port.String())
+		require.Empty(t, egressPorts(web))
+	})
+
+	t.Run("external services", func(t *testing.T) {
+		options := pegaOptions(helmtest.Combination{Provider: "k8s", Action: "install-deploy"}, map[string]string{
+			"dds.externalNodes":                       "cassandra.example.com",
+			"dds.port":                                "9142",
+			"pegasearch.externalSearchService":        "true",
+			"pegasearch.externalURL":                  "http://srs-service.srs:8080",
+			"pegasearch.srsAuth.enabled":              "true",
+			"pegasearch.srsAuth.url":                  "https://idp.example.com/oauth2/token",
+			"pegasearch.srsAuth.clientId":             "pega",
+			"pegasearch.srsAuth.authType":             "client_secret_basic",
+			"pegasearch.srsAuth.external_secret_name": "srs-client-secret",
+			"stream.bootstrapServer":                  "kafka-0.example.com:9093\\,kafka-1.example.com:9093",
+			"global.jdbc.url":                         "jdbc:sqlserver://sql.example.com;databaseName=pega",
+			"global.jdbc.dbType":                      "mssql",
+			"global.jdbc.driverUri":                   "https://repo.example.com/mssql-jdbc.jar",
+			"global.networkPolicy.databaseCIDRs[0]":   "10.30.0.0/24",
+			"hazelcast.clusteringServiceEnabled":      "false",
+		})
+		parser, policies := renderNetworkPolicies(t, options, valuesFile)
+		var web networkingv1.NetworkPolicy
+		parser.Find(helmtest.SearchResourceOption{Name: "pega-web", Kind: "NetworkPolicy"}, &web)
+
+		// search and reporting service, OAuth, Cassandra, Kafka, driver download and the Kubernetes API server
+		require.Equal(t, []string{"443", "443", "443", "6443", "8080", "9093", "9142"}, egressPorts(web))
+		database := web.Spec.Egress[len(web.Spec.Egress)-2]
+		require.Equal(t, "1433", database.Ports[0].Port.String())
+		require.Equal(t, []networkingv1.NetworkPolicyPeer{{IPBlock: &networkingv1.IPBlock{CIDR: "10.30.0.0/24"}}}, database.To)
+
+		require.False(t, parser.Contains(helmtest.SearchResourceOption{Name: "pega-search", Kind: "NetworkPolicy"}))
+		requirePolicySelectorsMatchPods(t, parser, policies)
+	})
+
+	t.Run("oracle connect descriptor", func(t *testing.T) {
+		options := pegaOptions(helmtest.Combination{Provider: "openshift", Action: "deploy"}, map[string]string{
+			"global.jdbc.url":    "jdbc:oracle:thin:@(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=10.40.0.7)(PORT=1522))(CONNECT_DATA=(SERVICE_NAME=pega)))",
+			"global.jdbc.dbType": "oracledate",
+		})
+		parser, _ := renderNetworkPolicies(t, options, valuesFile)
+		var batch networkingv1.NetworkPolicy
+		parser.Find(helmtest.SearchResourceOption{Name: "pega-batch", Kind: "NetworkPolicy"}, &batch)
+
+		require.Equal(t, []networkingv1.NetworkPolicyPeer{{NamespaceSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"kubernetes.io/metadata.name": "openshift-dns"},
+		}}}, batch.Spec.Egress[0].To)
+		database := batch.Spec.Egress[len(batch.Spec.Egress)-1]
+		require.Equal(t, "1522", database.Ports[0].Port.String())
+		require.Equal(t, []networkingv1.NetworkPolicyPeer{{IPBlock: &networkingv1.IPBlock{CIDR: "10.40.0.7/32"}}}, database.To)
+	})
+}
+
+// TestPegaNetworkPolicyDisabled - the example values.yaml renders no NetworkPolicy
+func TestPegaNetworkPolicyDisabled(t *testing.T) {
+	_, policies := renderNetworkPolicies(t, pegaOptions(k8sDeploy, nil))
+	require.Empty(t, policies)
+}
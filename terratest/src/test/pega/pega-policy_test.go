@@ -0,0 +1,97 @@
+package pega
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/policy"
+	"github.com/stretchr/testify/require"
+)
+
+// renderPolicyViolations - renders the pega chart and runs the default rule pack over it, logging the report
+func renderPolicyViolations(t *testing.T, options *helm.Options) []policy.Violation {
+	helmTest := newPegaChartTest(t, options)
+	parser := helmtest.NewHelmConfigParser(helmTest)
+	reports := policy.NewEngine().Evaluate(parser.Query(helmtest.ResourceQuery{}))
+	t.Log("\n" + policy.Describe(reports))
+	return policy.Violations(reports)
+}
+
+// TestPegaPolicyDefaultUtilityImages - the default busybox and k8s-wait-for images are not pinned, and the installer
+// keeps the administrator password in a ConfigMap
+func TestPegaPolicyDefaultUtilityImages(t *testing.T) {
+	t.Parallel()
+	violations := renderPolicyViolations(t, &helm.Options{SetValues: map[string]string{
+		"global.provider":        "k8s",
+		"global.actions.execute": "install-deploy",
+	}})
+
+	webDeployment := "Deployment/pega-web (pega/templates/pega-tier-deployment.yaml)"
+	require.Contains(t, violations, policy.Violation{
+		Rule:     "pinned-image-tags",
+		Resource: webDeployment,
+		Path:     "spec.template.spec.initContainers[0].image",
+		Message:  "image pegasystems/k8s-wait-for of container wait-for-pegainstall has no tag and is not pinned to a digest",
+	})
+	require.Contains(t, violations, policy.Violation{
+		Rule:     "pinned-image-tags",
+		Resource: webDeployment,
+		Path:     "spec.template.spec.initContainers[1].image",
+		Message:  "image busybox:1.31.0 of container wait-for-pegasearch is not pinned to a digest",
+	})
+	require.Contains(t, violations, policy.Violation{
+		Rule:     "no-plaintext-credentials",
+		Resource: "ConfigMap/pega-install-environment-config (pega/charts/installer/templates/pega-install-environment-config.yaml)",
+		Path:     "data.ADMIN_PASSWORD",
+		Message:  "ADMIN_PASSWORD holds a credential in plain text",
+	})
+}
+
+// TestPegaPolicySidecarContainers - sidecars added through custom.sidecarContainers are held to the same rules as the
+// Pega containers
+func TestPegaPolicySidecarContainers(t *testing.T) {
+	t.Parallel()
+	testsPath, err := filepath.Abs(PegaHelmChartTestsPath)
+	require.NoError(t, err)
+	violations := renderPolicyViolations(t, &helm.Options{
+		ValuesFiles: []string{testsPath + "/data/values_sidecar_containers.yaml"},
+		SetValues: map[string]string{
+			"global.provider":        "k8s",
+			"global.actions.execute": "deploy",
+		},
+	})
+
+	for _, tier := range []string{"Deployment/pega-web", "Deployment/pega-batch", "StatefulSet/pega-stream"} {
+		require.Contains(t, violations, policy.Violation{
+			Rule:     "resource-limits",
+			Resource: tier + " (pega/templates/pega-tier-deployment.yaml)",
+			Path:     "spec.template.spec.containers[1].resources.limits",
+			Message:  "container test-sidecar has no cpu or memory limit",
+		})
+		require.Contains(t, violations, policy.Violation{
+			Rule:     "pinned-image-tags",
+			Resource: tier + " (pega/templates/pega-tier-deployment.yaml)",
+			Path:     "spec.template.spec.containers[1].image",
+			Message:  "image test/sidecar of container test-sidecar has no tag and is not pinned to a digest",
+		})
+	}
+}
+
+// TestPegaPolicyBaselineValues - a deployment with pinned images, the clustering service run as a non-root user, an
+// external search service and sized sidecars meets every rule of the default pack on every provider
+func TestPegaPolicyBaselineValues(t *testing.T) {
+	testsPath, err := filepath.Abs(PegaHelmChartTestsPath)
+	require.NoError(t, err)
+
+	matrix := helmtest.Matrix{
+		Providers:   helmtest.SupportedProviders,
+		Actions:     []string{"deploy"},
+		ValuesFiles: []string{testsPath + "/data/values_policy_baseline.yaml"},
+	}
+	matrix.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		helmTest := newPegaChartTest(t, combination.HelmOptions(nil))
+		policy.AssertCompliant(t, helmtest.NewHelmConfigParser(helmTest), policy.NewEngine())
+	})
+}
@@ -2,51 +2,42 @@ package pega
 
 import (
 	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	"path/filepath"
 	"testing"
 )
 
-
-
-func TestPegaProviderValidate_WithValidProvider(t *testing.T){
-	
-	var supportedVendors = []string{"k8s","openshift","eks","gke","aks","pks"}
+func TestPegaProviderValidate_WithValidProvider(t *testing.T) {
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _,provider := range supportedVendors{
-
-		var options = &helm.Options{			
-			SetValues: map[string]string{
-				"global.provider":        provider,
-				"global.actions.execute": "deploy",
-		 	},
-	    }
+	helmtest.Matrix{
+		Providers: helmtest.SupportedProviders,
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"global.actions.execute": "deploy",
+		})
 
 		yamlContent, err := RenderTemplateE(t, options, helmChartPath, []string{"templates/pega-provider-validate.yaml"})
-		require.Contains(t,yamlContent,"could not find template templates/pega-provider-validate.yaml")
-		require.Contains(t,err.Error(),"could not find template templates/pega-provider-validate.yaml")
-		
-	}
+		require.Contains(t, yamlContent, "could not find template templates/pega-provider-validate.yaml")
+		require.Contains(t, err.Error(), "could not find template templates/pega-provider-validate.yaml")
+	})
 
 }
 
-func TestPegaProviderValidate_WithInvalidProvider(t *testing.T){
+func TestPegaProviderValidate_WithInvalidProvider(t *testing.T) {
 
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-
-	var options = &helm.Options{			
+	var options = &helm.Options{
 		SetValues: map[string]string{
-			"global.provider":        "invalidProvider",
-	 	},
+			"global.provider": "invalidProvider",
+		},
 	}
 
-	yamlContent, err := RenderTemplateE(t, options, helmChartPath, []string{"templates/pega-provider-validate.yaml"})
-	require.Contains(t,yamlContent,"global.provider must be one of")
-	require.Contains(t,err.Error(),"global.provider must be one of")
-		
-}
+	_, err = RenderTemplateE(t, options, helmChartPath, []string{"templates/pega-provider-validate.yaml"})
+	requireValuesSchemaError(t, err, "global.provider")
 
+}
@@ -0,0 +1,34 @@
+package pega
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/validation"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPegaManifestsMatchKubernetesSchemas - validates every rendered manifest against the OpenAPI schemas of the
+// representative Kubernetes versions, for every provider and action. TestPegaKubeVersionAPIChoices renders the other
+// supported versions.
+func TestPegaManifestsMatchKubernetesSchemas(t *testing.T) {
+	testsPath, err := filepath.Abs(PegaHelmChartTestsPath)
+	require.NoError(t, err)
+
+	matrix := helmtest.Matrix{
+		Providers: helmtest.SupportedProviders,
+		Actions:   helmtest.SupportedActions,
+		ValuesFiles: []string{
+			"",
+			testsPath + "/data/values_schema_validation.yaml",
+			testsPath + "/data/values_schema_validation_custom_resources.yaml",
+		},
+		KubeVersions: helmtest.RepresentativeKubeVersions,
+	}
+	matrix.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := pegaOptions(combination, nil)
+		helmTest := newPegaChartTest(t, options).WithKubeVersion(combination.KubeVersion)
+		validation.AssertValid(t, helmtest.NewHelmConfigParser(helmTest), combination.KubeVersion)
+	})
+}
@@ -2,6 +2,7 @@ package pega
 
 import (
 	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	appsv1beta2 "k8s.io/api/apps/v1beta2"
 	k8score "k8s.io/api/core/v1"
@@ -9,39 +10,18 @@ import (
 	"testing"
 )
 
-
-
-func TestPegaSearchDeployment(t *testing.T){
-	var supportedVendors = []string{"k8s","openshift","eks","gke","aks","pks"}
-	var supportedOperations =  []string{"deploy","install-deploy","upgrade-deploy"}
-    var deploymentNames = []string{"pega","myapp-dev"}
-
+func TestPegaSearchDeployment(t *testing.T) {
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
+	pegaDeploymentMatrix.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"global.storageClassName": "storage-class",
+		})
 
-	for _,vendor := range supportedVendors{
-
-		for _,operation := range supportedOperations{
-
-            for _, depName := range deploymentNames {
-
-                var options = &helm.Options{
-                    SetValues: map[string]string{
-                        "global.deployment.name": depName,
-                        "global.provider":        vendor,
-                        "global.actions.execute": operation,
-						"installer.upgrade.upgradeType": "zero-downtime",
-						"global.storageClassName": "storage-class",
-                    },
-                }
-
-                yamlContent := RenderTemplate(t, options, helmChartPath, []string{"charts/pegasearch/templates/pega-search-deployment.yaml"})
-                VerifySearchDeployment(t, yamlContent, options)
-            }
-		}
-	}
-
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"charts/pegasearch/templates/pega-search-deployment.yaml"})
+		VerifySearchDeployment(t, yamlContent, options)
+	})
 
 }
 
@@ -58,4 +38,4 @@ func VerifySearchDeployment(t *testing.T, yamlContent string, options *helm.Opti
 	statefulsetSpec := statefulsetObj.Spec.Template.Spec
 	require.Equal(t, statefulsetSpec.Containers[0].VolumeMounts[0].Name, "esstorage")
 	require.Equal(t, statefulsetSpec.Containers[0].VolumeMounts[0].MountPath, "/usr/share/elasticsearch/data")
-}
\ No newline at end of file
+}
@@ -2,48 +2,24 @@ package pega
 
 import (
 	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	k8score "k8s.io/api/core/v1"
 	intstr "k8s.io/apimachinery/pkg/util/intstr"
 	"path/filepath"
 	"testing"
-	"fmt"
 )
 
-
-
-func TestPegaSearchService(t *testing.T){
-	var supportedVendors = []string{"k8s","openshift","eks","gke","aks","pks"}
-	var supportedOperations =  []string{"deploy","install-deploy","upgrade-deploy"}
-    var deploymentNames = []string{"pega","myapp-dev"}
-
+func TestPegaSearchService(t *testing.T) {
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
+	pegaDeploymentMatrix.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(nil)
 
-	for _,vendor := range supportedVendors{
-
-		for _,operation := range supportedOperations{
-
-            for _, depName := range deploymentNames {
-
-                fmt.Println(vendor + "-" + operation)
-
-                var options = &helm.Options{
-                    SetValues: map[string]string{
-                        "global.deployment.name": depName,
-                        "global.provider":        vendor,
-                        "global.actions.execute": operation,
-						"installer.upgrade.upgradeType": "zero-downtime",
-                    },
-                }
-
-                yamlContent := RenderTemplate(t, options, helmChartPath, []string{"charts/pegasearch/templates/pega-search-service.yaml"})
-                VerifySearchService(t, yamlContent, options)
-            }
-		}
-	}
-
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"charts/pegasearch/templates/pega-search-service.yaml"})
+		VerifySearchService(t, yamlContent, options)
+	})
 
 }
 
@@ -56,4 +32,4 @@ func VerifySearchService(t *testing.T, yamlContent string, options *helm.Options
 	require.Equal(t, searchServiceObj.Spec.Ports[0].Name, "http")
 	require.Equal(t, searchServiceObj.Spec.Ports[0].Port, int32(80))
 	require.Equal(t, searchServiceObj.Spec.Ports[0].TargetPort, intstr.FromInt(9200))
-}
\ No newline at end of file
+}
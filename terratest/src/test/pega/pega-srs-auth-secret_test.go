@@ -1,8 +1,7 @@
 package pega
 
 import (
-	"fmt"
-	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	k8score "k8s.io/api/core/v1"
 	"path/filepath"
@@ -10,150 +9,95 @@ import (
 )
 
 func TestPegaSRSAuthSecretNotCreatedForDeploymentWithoutSRS(t *testing.T) {
-	var supportedVendors = []string{"k8s", "openshift", "eks", "gke", "aks", "pks"}
-	var supportedOperations = []string{"install", "install-deploy"}
-	var deploymentNames = []string{"pega", "myapp-dev"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-		for _, operation := range supportedOperations {
-			for _, depName := range deploymentNames {
-				fmt.Println(vendor + "-" + operation)
-
-				var options = &helm.Options{
-					SetValues: map[string]string{
-						"global.deployment.name":           depName,
-						"global.provider":                  vendor,
-						"pegasearch.externalSearchService": "false",
-					},
-				}
-
-				yamlContent, err := RenderTemplateE(t, options, helmChartPath, []string{"templates/pega-srs-auth-secret.yaml"})
-				VerifySRSAuthSecretIsNotCreated(t, yamlContent, err)
-			}
-		}
-	}
+	helmtest.Matrix{
+		Providers:       helmtest.SupportedProviders,
+		DeploymentNames: []string{"pega", "myapp-dev"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"pegasearch.externalSearchService": "false",
+		})
+
+		yamlContent, err := RenderTemplateE(t, options, helmChartPath, []string{"templates/pega-srs-auth-secret.yaml"})
+		VerifySRSAuthSecretIsNotCreated(t, yamlContent, err)
+	})
 }
 
 func TestPegaSRSAuthSecretNotCreatedForDeploymentWithDisabledSRSAuth(t *testing.T) {
-	var supportedVendors = []string{"k8s", "openshift", "eks", "gke", "aks", "pks"}
-	var supportedOperations = []string{"install", "install-deploy"}
-	var deploymentNames = []string{"pega", "myapp-dev"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-		for _, operation := range supportedOperations {
-			for _, depName := range deploymentNames {
-				fmt.Println(vendor + "-" + operation)
-
-				var options = &helm.Options{
-					SetValues: map[string]string{
-						"global.deployment.name":           depName,
-						"global.provider":                  vendor,
-						"pegasearch.externalSearchService": "true",
-						"pegasearch.srsAuth.enabled":       "false",
-					},
-				}
-
-				yamlContent, err := RenderTemplateE(t, options, helmChartPath, []string{"templates/pega-srs-auth-secret.yaml"})
-				VerifySRSAuthSecretIsNotCreated(t, yamlContent, err)
-			}
-		}
-	}
+	helmtest.Matrix{
+		Providers:       helmtest.SupportedProviders,
+		DeploymentNames: []string{"pega", "myapp-dev"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"pegasearch.externalSearchService": "true",
+			"pegasearch.srsAuth.enabled":       "false",
+		})
+
+		yamlContent, err := RenderTemplateE(t, options, helmChartPath, []string{"templates/pega-srs-auth-secret.yaml"})
+		VerifySRSAuthSecretIsNotCreated(t, yamlContent, err)
+	})
 }
 
 func TestPegaSRSAuthSecretNotCreatedForMissingPrivateKey(t *testing.T) {
-	var supportedVendors = []string{"k8s", "openshift", "eks", "gke", "aks", "pks"}
-	var supportedOperations = []string{"install", "install-deploy"}
-	var deploymentNames = []string{"pega", "myapp-dev"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-		for _, operation := range supportedOperations {
-			for _, depName := range deploymentNames {
-				fmt.Println(vendor + "-" + operation)
-
-				var options = &helm.Options{
-					SetValues: map[string]string{
-						"global.deployment.name":           depName,
-						"global.provider":                  vendor,
-						"pegasearch.externalSearchService": "true",
-						"pegasearch.srsAuth.enabled":       "true",
-					},
-				}
-
-				yamlContent, err := RenderTemplateE(t, options, helmChartPath, []string{"templates/pega-srs-auth-secret.yaml"})
-				require.Contains(t, yamlContent, "A valid entry is required for pegasearch.srsAuth.privateKey or pegasearch.srsAuth.external_secret_name, when request authentication mechanism(IDP) is enabled between SRS and Pega Infinity i.e. pegasearch.srsAuth.enabled is true.")
-				require.Contains(t, err.Error(), "A valid entry is required for pegasearch.srsAuth.privateKey or pegasearch.srsAuth.external_secret_name, when request authentication mechanism(IDP) is enabled between SRS and Pega Infinity i.e. pegasearch.srsAuth.enabled is true.")
-			}
-		}
-	}
+	helmtest.Matrix{
+		Providers:       helmtest.SupportedProviders,
+		DeploymentNames: []string{"pega", "myapp-dev"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"pegasearch.externalSearchService": "true",
+			"pegasearch.srsAuth.enabled":       "true",
+		})
+
+		yamlContent, err := RenderTemplateE(t, options, helmChartPath, []string{"templates/pega-srs-auth-secret.yaml"})
+		require.Contains(t, yamlContent, "A valid entry is required for pegasearch.srsAuth.privateKey or pegasearch.srsAuth.external_secret_name, when request authentication mechanism(IDP) is enabled between SRS and Pega Infinity i.e. pegasearch.srsAuth.enabled is true.")
+		require.Contains(t, err.Error(), "A valid entry is required for pegasearch.srsAuth.privateKey or pegasearch.srsAuth.external_secret_name, when request authentication mechanism(IDP) is enabled between SRS and Pega Infinity i.e. pegasearch.srsAuth.enabled is true.")
+	})
 }
 
 func TestPegaSRSAuthSecretNotCreatedForDeploymentWithEnabledSRSAuthAndExternalSecret(t *testing.T) {
-	var supportedVendors = []string{"k8s", "openshift", "eks", "gke", "aks", "pks"}
-	var supportedOperations = []string{"install", "install-deploy"}
-	var deploymentNames = []string{"pega", "myapp-dev"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-		for _, operation := range supportedOperations {
-			for _, depName := range deploymentNames {
-				fmt.Println(vendor + "-" + operation + "-" + depName)
-
-				var options = &helm.Options{
-					SetValues: map[string]string{
-						"global.deployment.name":                     depName,
-						"global.provider":                            vendor,
-						"pegasearch.externalSearchService":           "true",
-						"pegasearch.srsAuth.enabled":                 "true",
-						"pegasearch.srsAuth.external_secret_name":    "test-external-secret",
-					},
-				}
-
-				yamlContent, err := RenderTemplateE(t, options, helmChartPath, []string{"templates/pega-srs-auth-secret.yaml"})
-				VerifySRSAuthSecretIsNotCreated(t, yamlContent, err)
-			}
-		}
-	}
+	helmtest.Matrix{
+		Providers:       helmtest.SupportedProviders,
+		DeploymentNames: []string{"pega", "myapp-dev"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"pegasearch.externalSearchService":        "true",
+			"pegasearch.srsAuth.enabled":              "true",
+			"pegasearch.srsAuth.external_secret_name": "test-external-secret",
+		})
+
+		yamlContent, err := RenderTemplateE(t, options, helmChartPath, []string{"templates/pega-srs-auth-secret.yaml"})
+		VerifySRSAuthSecretIsNotCreated(t, yamlContent, err)
+	})
 }
 
 func TestPegaSRSAuthSecretCreatedForDeploymentWithEnabledSRSAuth(t *testing.T) {
-	var supportedVendors = []string{"k8s", "openshift", "eks", "gke", "aks", "pks"}
-	var supportedOperations = []string{"install", "install-deploy"}
-	var deploymentNames = []string{"pega", "myapp-dev"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-		for _, operation := range supportedOperations {
-			for _, depName := range deploymentNames {
-				fmt.Println(vendor + "-" + operation)
-
-				var options = &helm.Options{
-					SetValues: map[string]string{
-						"global.deployment.name":           depName,
-						"global.provider":                  vendor,
-						"pegasearch.externalSearchService": "true",
-						"pegasearch.srsAuth.enabled":       "true",
-						"pegasearch.srsAuth.privateKey":    SRSAuthPrivateKeyExample,
-					},
-				}
-
-				yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-srs-auth-secret.yaml"})
-				VerifySRSAuthSecretIsCreated(t, yamlContent)
-			}
-		}
-	}
+	helmtest.Matrix{
+		Providers:       helmtest.SupportedProviders,
+		DeploymentNames: []string{"pega", "myapp-dev"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"pegasearch.externalSearchService": "true",
+			"pegasearch.srsAuth.enabled":       "true",
+			"pegasearch.srsAuth.privateKey":    SRSAuthPrivateKeyExample,
+		})
+
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-srs-auth-secret.yaml"})
+		VerifySRSAuthSecretIsCreated(t, yamlContent)
+	})
 }
 
 func VerifySRSAuthSecretIsNotCreated(t *testing.T, yamlContent string, err error) {
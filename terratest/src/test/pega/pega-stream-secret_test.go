@@ -1,11 +1,10 @@
 package pega
 
 import (
-	"fmt"
 	"path/filepath"
 	"testing"
 
-	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	k8score "k8s.io/api/core/v1"
 )
@@ -15,37 +14,24 @@ const streamKeyStorePassword = "keyStore"
 const jaasConfig = "jaasConfig"
 
 func TestPegaCredentialsSecretWithExternalStreamArePresent(t *testing.T) {
-	var supportedVendors = []string{"k8s", "openshift", "eks", "gke", "aks", "pks"}
-	var supportedOperations = []string{"install-deploy", "deploy", "upgrade-deploy"}
-	var deploymentNames = []string{"pega", "myapp-dev"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-
-		for _, operation := range supportedOperations {
-
-			for _, depName := range deploymentNames {
-				fmt.Println(vendor + "-" + operation)
-
-				var options = &helm.Options{
-					SetValues: map[string]string{
-						"global.deployment.name":        depName,
-						"global.provider":               vendor,
-						"global.actions.execute":        operation,
-						"installer.upgrade.upgradeType": getUpgradeTypeForUpgradeAction(operation),
-						"stream.trustStorePassword":     streamTrustStorePassword,
-						"stream.keyStorePassword":       streamKeyStorePassword,
-						"stream.jaasConfig":             jaasConfig,
-					},
-				}
-
-				yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-stream-secret.yaml"})
-				verifyStreamCredentialsSecret(t, yamlContent, operation)
-			}
-		}
-	}
+	helmtest.Matrix{
+		Providers:       helmtest.SupportedProviders,
+		Actions:         []string{"install-deploy", "deploy", "upgrade-deploy"},
+		DeploymentNames: []string{"pega", "myapp-dev"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"installer.upgrade.upgradeType": getUpgradeTypeForUpgradeAction(combination.Action),
+			"stream.trustStorePassword":     streamTrustStorePassword,
+			"stream.keyStorePassword":       streamKeyStorePassword,
+			"stream.jaasConfig":             jaasConfig,
+		})
+
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-stream-secret.yaml"})
+		verifyStreamCredentialsSecret(t, yamlContent, combination.Action)
+	})
 
 }
 
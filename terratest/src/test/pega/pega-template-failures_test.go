@@ -0,0 +1,191 @@
+package pega
+
+import (
+	"testing"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+)
+
+// TestPegaTemplateFailures - every fail and required guard in the pega templates stops rendering with its message.
+// The guards on global.provider, global.actions.execute, podAntiAffinityPreset and pegasearch.srsAuth.authType repeat
+// a values.schema.json enum, so helm stops on the schema before reaching them; the pega-values-schema tests cover those
+// values.
+func TestPegaTemplateFailures(t *testing.T) {
+	helmtest.FailureSuite{
+		Chart:       helmtest.PegaChart,
+		ReleaseName: "pega",
+		BaseValues: map[string]string{
+			"global.provider":        "k8s",
+			"global.actions.execute": "deploy",
+		},
+		Cases: []helmtest.FailureCase{
+			{
+				Name: "deployment strategy on a StatefulSet tier",
+				SetValues: map[string]string{
+					"global.tier[0].name":     "stream",
+					"global.tier[0].nodeType": "Stream",
+					"global.tier[0].volumeClaimTemplate.resources.requests.storage": "5Gi",
+					"global.tier[0].deploymentStrategy.type":                        "Recreate",
+				},
+				Templates:     []string{"templates/pega-tier-deployment.yaml"},
+				ExpectedError: helmtest.FailMessage("tier[pega-stream] may not specify a deploymentStrategy because it uses a volumeClaimTemplate which requires it be a StatefulSet"),
+			},
+			{
+				Name: "Stream nodeType with an external stream url",
+				SetValues: map[string]string{
+					"global.tier[0].name":     "web",
+					"global.tier[0].nodeType": `WebUser\,Stream`,
+					"stream.url":              "kafka:9092",
+				},
+				Templates:     []string{"templates/pega-tier-deployment.yaml"},
+				ExpectedError: helmtest.FailMessage("Cannot have 'Stream' nodeType when Stream url is provided"),
+			},
+			{
+				Name: "network policy without the database port",
+				SetValues: map[string]string{
+					"global.tier[0].name":                  "web",
+					"global.tier[0].nodeType":              "WebUser",
+					"global.tier[0].networkPolicy.enabled": "true",
+					"global.jdbc.url":                      "jdbc:db2://db2.example.com/PEGA",
+					"global.jdbc.dbType":                   "db2zos",
+				},
+				Templates:     []string{"templates/pega-tier-network-policy.yaml"},
+				ExpectedError: helmtest.FailMessage("The NetworkPolicy needs the database port: add it to the JDBC URL jdbc:db2://db2.example.com/PEGA"),
+			},
+			{
+				Name: "anti-affinity preset next to a pod anti-affinity",
+				SetValues: map[string]string{
+					"global.tier[0].name":                  "web",
+					"global.tier[0].nodeType":              "WebUser",
+					"global.tier[0].podAntiAffinityPreset": "soft",
+					"global.tier[0].affinity.podAntiAffinity.preferredDuringSchedulingIgnoredDuringExecution[0].weight": "1",
+				},
+				Templates:     []string{"templates/pega-tier-deployment.yaml"},
+				ExpectedError: helmtest.FailMessage("Set either podAntiAffinityPreset or affinity.podAntiAffinity for pega-web, not both"),
+			},
+			{
+				Name: "HPA and KEDA autoscaling on the same tier",
+				SetValues: map[string]string{
+					"global.tier[0].name":                                   "batch",
+					"global.tier[0].nodeType":                               "BackgroundProcessing",
+					"global.tier[0].hpa.enabled":                            "true",
+					"global.tier[0].autoscaling.mode":                       "keda",
+					"global.tier[0].autoscaling.triggers[0].type":           "cpu",
+					"global.tier[0].autoscaling.triggers[0].metadata.value": "60",
+				},
+				Templates:     []string{"templates/pega-tier-hpa.yaml"},
+				ExpectedError: helmtest.FailMessage("tier[pega-batch] enables both the HPA and KEDA autoscaling: set hpa.enabled to false to use autoscaling.mode keda"),
+			},
+			{
+				Name: "KEDA autoscaling without triggers",
+				SetValues: map[string]string{
+					"global.tier[0].name":             "batch",
+					"global.tier[0].nodeType":         "BackgroundProcessing",
+					"global.tier[0].autoscaling.mode": "keda",
+				},
+				Templates:     []string{"templates/pega-tier-hpa.yaml"},
+				ExpectedError: helmtest.FailMessage("tier[pega-batch] autoscaling.mode keda requires at least one entry in autoscaling.triggers"),
+			},
+			{
+				Name: "VPA in Auto mode on the CPU the HPA scales on",
+				SetValues: map[string]string{
+					"global.tier[0].name":           "web",
+					"global.tier[0].nodeType":       "WebUser",
+					"global.tier[0].hpa.enabled":    "true",
+					"global.tier[0].vpa.enabled":    "true",
+					"global.tier[0].vpa.updateMode": "Auto",
+				},
+				Templates:     []string{"templates/pega-tier-vpa.yaml"},
+				ExpectedError: helmtest.FailMessage("tier[pega-web] may not use vpa.updateMode Auto on cpu because its replicas already scale on cpu: set vpa.updateMode to Off or Initial, or leave cpu out of vpa.controlledResources"),
+			},
+			{
+				Name: "VPA in Recreate mode on the memory a KEDA trigger scales on",
+				SetValues: map[string]string{
+					"global.tier[0].name":                                   "batch",
+					"global.tier[0].nodeType":                               "BackgroundProcessing",
+					"global.tier[0].autoscaling.mode":                       "keda",
+					"global.tier[0].autoscaling.triggers[0].type":           "memory",
+					"global.tier[0].autoscaling.triggers[0].metadata.value": "80",
+					"global.tier[0].vpa.enabled":                            "true",
+					"global.tier[0].vpa.updateMode":                         "Recreate",
+					"global.tier[0].vpa.controlledResources[0]":             "memory",
+				},
+				Templates:     []string{"templates/pega-tier-vpa.yaml"},
+				ExpectedError: helmtest.FailMessage("tier[pega-batch] may not use vpa.updateMode Recreate on memory because its replicas already scale on memory: set vpa.updateMode to Off or Initial, or leave memory out of vpa.controlledResources"),
+			},
+			{
+				Name: "gateway ingress without a parent Gateway",
+				SetValues: map[string]string{
+					"global.tier[0].name":            "web",
+					"global.tier[0].nodeType":        "WebUser",
+					"global.tier[0].ingress.enabled": "true",
+					"global.tier[0].ingress.type":    "gateway",
+				},
+				Templates:     []string{"templates/pega-tier-ingress.yaml"},
+				ExpectedError: helmtest.FailMessage("tier[pega-web] ingress.type gateway requires ingress.gateway.name, the name of the parent Gateway"),
+			},
+			{
+				Name: "gateway ingress without the http port of the service",
+				SetValues: map[string]string{
+					"global.tier[0].name":                 "web",
+					"global.tier[0].nodeType":             "WebUser",
+					"global.tier[0].service.port":         "80",
+					"global.tier[0].service.httpEnabled":  "false",
+					"global.tier[0].ingress.enabled":      "true",
+					"global.tier[0].ingress.type":         "gateway",
+					"global.tier[0].ingress.gateway.name": "pega-gateway",
+				},
+				Templates:     []string{"templates/pega-tier-ingress.yaml"},
+				ExpectedError: helmtest.FailMessage("tier[pega-web] ingress.type gateway routes to the http port of the service and may not set service.httpEnabled to false"),
+			},
+			{
+				Name: "srs authentication without a private key",
+				SetValues: map[string]string{
+					"pegasearch.externalSearchService": "true",
+					"pegasearch.externalURL":           "https://srs.example.com",
+					"pegasearch.srsAuth.enabled":       "true",
+				},
+				Templates:     []string{"templates/pega-srs-auth-secret.yaml"},
+				ExpectedError: helmtest.FailMessage("A valid entry is required for pegasearch.srsAuth.privateKey or pegasearch.srsAuth.external_secret_name, when request authentication mechanism(IDP) is enabled between SRS and Pega Infinity i.e. pegasearch.srsAuth.enabled is true."),
+			},
+		},
+	}.Run(t)
+}
+
+// TestPegaInstallerTemplateFailures - the installer refuses upgrade types that do not fit the upgrade action
+func TestPegaInstallerTemplateFailures(t *testing.T) {
+	helmtest.FailureSuite{
+		Chart:       helmtest.PegaChart,
+		ReleaseName: "pega",
+		BaseValues:  map[string]string{"global.provider": "k8s"},
+		Cases: []helmtest.FailureCase{
+			{
+				Name: "unknown upgrade type for upgrade",
+				SetValues: map[string]string{
+					"global.actions.execute":        "upgrade",
+					"installer.upgrade.upgradeType": "invalid",
+				},
+				Templates:     []string{"charts/installer/templates/pega-installer-action-validate.yaml"},
+				ExpectedError: helmtest.FailMessage("Upgrade Type value is not correct for upgrade action."),
+			},
+			{
+				Name: "zero-downtime upgrade type for upgrade",
+				SetValues: map[string]string{
+					"global.actions.execute":        "upgrade",
+					"installer.upgrade.upgradeType": "zero-downtime",
+				},
+				Templates:     []string{"charts/installer/templates/pega-installer-action-validate.yaml"},
+				ExpectedError: helmtest.FailMessage("Upgrade Type value is not correct for upgrade action."),
+			},
+			{
+				Name: "in-place upgrade type for upgrade-deploy",
+				SetValues: map[string]string{
+					"global.actions.execute":        "upgrade-deploy",
+					"installer.upgrade.upgradeType": "in-place",
+				},
+				Templates:     []string{"charts/installer/templates/pega-installer-action-validate.yaml"},
+				ExpectedError: helmtest.FailMessage("Upgrade Type value is not correct for upgrade-deploy action."),
+			},
+		},
+	}.Run(t)
+}
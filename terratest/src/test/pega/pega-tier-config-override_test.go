@@ -1,8 +1,8 @@
 package pega
 
 import (
-	"fmt"
 	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	k8score "k8s.io/api/core/v1"
 	"path/filepath"
@@ -11,32 +11,19 @@ import (
 )
 
 func TestPegaTierConfigOverride(t *testing.T) {
-	var supportedVendors = []string{"k8s", "openshift", "eks", "gke", "aks", "pks"}
-	var supportedOperations = []string{"deploy", "install-deploy", "upgrade-deploy"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-
-		for _, operation := range supportedOperations {
-
-			fmt.Println(vendor + "-" + operation)
-
-			var options = &helm.Options{
-				ValuesFiles: []string{"data/pega-tier-config-override_values.yaml"},
-				SetValues: map[string]string{
-					"global.provider":               vendor,
-					"global.actions.execute":        operation,
-					"installer.upgrade.upgradeType": "zero-downtime",
-				},
-			}
+	helmtest.Matrix{
+		Providers:   helmtest.SupportedProviders,
+		Actions:     helmtest.SupportedDeployActions,
+		ValuesFiles: []string{"data/pega-tier-config-override_values.yaml"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(nil)
 
-			yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-config.yaml"})
-			VerifyTierConfgOverrides(t, yamlContent, options)
-
-		}
-	}
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-config.yaml"})
+		VerifyTierConfgOverrides(t, yamlContent, options)
+	})
 
 }
 
@@ -1,86 +1,73 @@
 package pega
 
 import (
-    "fmt"
-    "github.com/gruntwork-io/terratest/modules/helm"
-    "github.com/stretchr/testify/require"
-    "io"
-    k8score "k8s.io/api/core/v1"
-    "os"
-    "path/filepath"
-    "strings"
-    "testing"
+	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+	"github.com/stretchr/testify/require"
+	"io"
+	k8score "k8s.io/api/core/v1"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
 )
 
 func TestPegaTierConfigWithWeb(t *testing.T) {
-    var supportedVendors = []string{"k8s", "openshift", "eks", "gke", "aks", "pks"}
-    var supportedOperations = []string{"deploy", "install-deploy", "upgrade-deploy"}
+	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
+	require.NoError(t, err)
 
-    helmChartPath, err := filepath.Abs(PegaHelmChartPath)
-    require.NoError(t, err)
+	webPath := filepath.Join(helmChartPath, "config", "deploy", "web.xml")
 
-    webPath := filepath.Join(helmChartPath, "config", "deploy", "web.xml")
+	err = CopyFile("data/expectedInstallDeployWeb.xml", webPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { os.Remove(webPath) })
 
-    err = CopyFile("data/expectedInstallDeployWeb.xml", webPath)
-    require.NoError(t, err)
-    defer os.Remove(webPath)
+	helmtest.Matrix{
+		Providers: helmtest.SupportedProviders,
+		Actions:   helmtest.SupportedDeployActions,
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(nil)
 
-    for _, vendor := range supportedVendors {
-
-        for _, operation := range supportedOperations {
-
-            fmt.Println(vendor + "-" + operation)
-
-            var options = &helm.Options{
-                SetValues: map[string]string{
-                    "global.provider":               vendor,
-                    "global.actions.execute":        operation,
-                    "installer.upgrade.upgradeType": "zero-downtime",
-                },
-            }
-
-            yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-config.yaml"})
-            VerifyTierConfigWithWeb(t, yamlContent, options)
-
-        }
-    }
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-config.yaml"})
+		VerifyTierConfigWithWeb(t, yamlContent, options)
+	})
 
 }
 
 func CopyFile(src, dest string) (err error) {
-    in, err := os.Open(src)
-    if err != nil {
-        return err
-    }
-    defer in.Close()
-
-    out, err := os.Create(dest)
-    if err != nil {
-        return err
-    }
-    defer out.Close()
-
-    _, err = io.Copy(out, in)
-    if err != nil {
-        return err
-    }
-
-    return out.Close()
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, in)
+	if err != nil {
+		return err
+	}
+
+	return out.Close()
 }
 
 // VerifyTierConfig - Performs the tier specific configuration assertions with the values as provided in default values.yaml
 func VerifyTierConfigWithWeb(t *testing.T, yamlContent string, options *helm.Options) {
-    var pegaConfigMap k8score.ConfigMap
-    configSlice := strings.Split(yamlContent, "---")
-    for index, configData := range configSlice {
-        if index >= 1 && index <= 3 {
-            UnmarshalK8SYaml(t, configData, &pegaConfigMap)
-            pegaConfigMapData := pegaConfigMap.Data
-            compareConfigMapData(t, pegaConfigMapData["prconfig.xml"], "data/expectedInstallDeployPrconfig.xml")
-            compareConfigMapData(t, pegaConfigMapData["context.xml.tmpl"], "data/expectedInstallDeployContext.xml.tmpl")
-            compareConfigMapData(t, pegaConfigMapData["prlog4j2.xml"], "data/expectedInstallDeployPRlog4j2.xml")
-            compareConfigMapData(t, pegaConfigMapData["server.xml.tmpl"], "data/expectedInstallDeployServer.xml.tmpl")
-            compareConfigMapData(t, pegaConfigMapData["web.xml"], "data/expectedInstallDeployWeb.xml")
-        }
-    }
+	var pegaConfigMap k8score.ConfigMap
+	configSlice := strings.Split(yamlContent, "---")
+	for index, configData := range configSlice {
+		if index >= 1 && index <= 3 {
+			UnmarshalK8SYaml(t, configData, &pegaConfigMap)
+			pegaConfigMapData := pegaConfigMap.Data
+			compareConfigMapData(t, pegaConfigMapData["prconfig.xml"], "data/expectedInstallDeployPrconfig.xml")
+			compareConfigMapData(t, pegaConfigMapData["context.xml.tmpl"], "data/expectedInstallDeployContext.xml.tmpl")
+			compareConfigMapData(t, pegaConfigMapData["prlog4j2.xml"], "data/expectedInstallDeployPRlog4j2.xml")
+			compareConfigMapData(t, pegaConfigMapData["server.xml.tmpl"], "data/expectedInstallDeployServer.xml.tmpl")
+			compareConfigMapData(t, pegaConfigMapData["web.xml"], "data/expectedInstallDeployWeb.xml")
+		}
+	}
 }
@@ -1,59 +1,52 @@
 package pega
 
 import (
-	"path/filepath"
-	"strings"
-	"testing"
 	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
+	"path/filepath"
+	"strings"
+	"testing"
 )
 
 func TestPegaDeploymentWithArtifactoryCerts(t *testing.T) {
 
-	var supportedVendors = []string{"k8s"}
-	var supportedOperations = []string{"deploy", "install-deploy", "upgrade-deploy"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-		for _, operation := range supportedOperations {
-
-			var options = &helm.Options{
-				ValuesFiles: []string{"data/values_with_artifactory_cert.yaml"},
-				SetValues: map[string]string{
-					"global.deployment.name":        "pega",
-					"global.provider":               vendor,
-					"global.actions.execute":        operation,
-					"installer.upgrade.upgradeType": "zero-downtime",
-				},
-			}
-			deploymentYaml := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-deployment.yaml"})
-			yamlSplit := strings.Split(deploymentYaml, "---")
-			assertWeb(t, yamlSplit[1], options)
-			assertArtifactoryCertificatesVolumeAndMount(t, yamlSplit[1], options, true)
-
-			assertBatch(t, yamlSplit[2], options)
-			assertArtifactoryCertificatesVolumeAndMount(t, yamlSplit[2], options, true)
-
-			assertStream(t, yamlSplit[3], options)
-			assertArtifactoryCertificatesVolumeAndMount(t, yamlSplit[3], options, true)
-
-			options.ValuesFiles = []string{"data/values_with_artifactory_sslverification_disabled.yaml"}
-
-			deploymentYaml = RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-deployment.yaml"})
-			yamlSplit = strings.Split(deploymentYaml, "---")
-			assertWeb(t, yamlSplit[1], options)
-			assertArtifactoryCertificatesVolumeAndMount(t, yamlSplit[1], options, false)
-
-			assertBatch(t, yamlSplit[2], options)
-			assertArtifactoryCertificatesVolumeAndMount(t, yamlSplit[2], options, false)
-
-			assertStream(t, yamlSplit[3], options)
-			assertArtifactoryCertificatesVolumeAndMount(t, yamlSplit[3], options, false)
-		}
-	}
+	helmtest.Matrix{
+		Providers:   []string{"k8s"},
+		Actions:     helmtest.SupportedDeployActions,
+		ValuesFiles: []string{"data/values_with_artifactory_cert.yaml"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"global.deployment.name": "pega",
+		})
+		deploymentYaml := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-deployment.yaml"})
+		yamlSplit := strings.Split(deploymentYaml, "---")
+		assertWeb(t, yamlSplit[1], options)
+		assertArtifactoryCertificatesVolumeAndMount(t, yamlSplit[1], options, true)
+
+		assertBatch(t, yamlSplit[2], options)
+		assertArtifactoryCertificatesVolumeAndMount(t, yamlSplit[2], options, true)
+
+		assertStream(t, yamlSplit[3], options)
+		assertArtifactoryCertificatesVolumeAndMount(t, yamlSplit[3], options, true)
+
+		options.ValuesFiles = []string{"data/values_with_artifactory_sslverification_disabled.yaml"}
+
+		deploymentYaml = RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-deployment.yaml"})
+		yamlSplit = strings.Split(deploymentYaml, "---")
+		assertWeb(t, yamlSplit[1], options)
+		assertArtifactoryCertificatesVolumeAndMount(t, yamlSplit[1], options, false)
+
+		assertBatch(t, yamlSplit[2], options)
+		assertArtifactoryCertificatesVolumeAndMount(t, yamlSplit[2], options, false)
+
+		assertStream(t, yamlSplit[3], options)
+		assertArtifactoryCertificatesVolumeAndMount(t, yamlSplit[3], options, false)
+	})
 }
 
 func assertArtifactoryCertificatesVolumeAndMount(t *testing.T, tierYaml string, options *helm.Options, shouldHaveVol bool) {
@@ -85,4 +78,4 @@ func assertArtifactoryCertificatesVolumeAndMount(t *testing.T, tierYaml string,
 	}
 	require.Equal(t, shouldHaveVol, foundVolMount)
 
-}
\ No newline at end of file
+}
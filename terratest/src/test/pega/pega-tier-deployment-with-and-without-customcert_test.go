@@ -6,37 +6,30 @@ import (
 	"testing"
 
 	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
 )
 
 func TestPegaDeploymentWithAndWithoutCustomCerts(t *testing.T) {
 
-	var supportedVendors = []string{"k8s"}
-	var supportedOperations = []string{"deploy", "install-deploy", "upgrade-deploy"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-		for _, operation := range supportedOperations {
-
-			var options = &helm.Options{
-				ValuesFiles: []string{"data/values_with_customcerts.yaml"},
-				SetValues: map[string]string{
-					"global.deployment.name":        "pega",
-					"global.provider":               vendor,
-					"global.actions.execute":        operation,
-					"installer.upgrade.upgradeType": "zero-downtime",
-				},
-			}
-			renderTierForCertTest(t, options, helmChartPath, true)
+	helmtest.Matrix{
+		Providers:   []string{"k8s"},
+		Actions:     helmtest.SupportedDeployActions,
+		ValuesFiles: []string{"data/values_with_customcerts.yaml"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"global.deployment.name": "pega",
+		})
+		renderTierForCertTest(t, options, helmChartPath, true)
 
-			options.ValuesFiles = []string{"data/values_without_customcerts.yaml"}
+		options.ValuesFiles = []string{"data/values_without_customcerts.yaml"}
 
-			renderTierForCertTest(t, options, helmChartPath, false)
-		}
-	}
+		renderTierForCertTest(t, options, helmChartPath, false)
+	})
 }
 
 func renderTierForCertTest(t *testing.T, options *helm.Options, helmChartPath string, shouldHaveVol bool) {
@@ -1,7 +1,7 @@
 package pega
 
 import (
-	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
 	"path/filepath"
@@ -11,49 +11,41 @@ import (
 
 func TestPegaDeploymentWithAndWithoutKerberos(t *testing.T) {
 
-	var supportedVendors = []string{"k8s"}
-	var supportedOperations = []string{"deploy", "install-deploy", "upgrade-deploy"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-		for _, operation := range supportedOperations {
-
-			var options = &helm.Options{
-				ValuesFiles: []string{"data/values_with_kerberos.yaml"},
-				SetValues: map[string]string{
-					"global.deployment.name":        "pega",
-					"global.provider":               vendor,
-					"global.actions.execute":        operation,
-					"installer.upgrade.upgradeType": "zero-downtime",
-				},
-			}
-			deploymentYaml := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-deployment.yaml"})
-			yamlSplit := strings.Split(deploymentYaml, "---")
-			assertWeb(t, yamlSplit[1], options)
-			assertVolumeAndMountForKerberos(t, yamlSplit[1], true)
-
-			assertBatch(t, yamlSplit[2], options)
-			assertVolumeAndMountForKerberos(t, yamlSplit[2], true)
-
-			assertStream(t, yamlSplit[3], options)
-			assertVolumeAndMountForKerberos(t, yamlSplit[3], true)
-
-			options.ValuesFiles = []string{"data/values_without_kerberos.yaml"}
-
-			deploymentYaml = RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-deployment.yaml"})
-			yamlSplit = strings.Split(deploymentYaml, "---")
-			assertWeb(t, yamlSplit[1], options)
-			assertVolumeAndMountForKerberos(t, yamlSplit[1], false)
-
-			assertBatch(t, yamlSplit[2], options)
-			assertVolumeAndMountForKerberos(t, yamlSplit[2], false)
-
-			assertStream(t, yamlSplit[3], options)
-			assertVolumeAndMountForKerberos(t, yamlSplit[3], false)
-		}
-	}
+	helmtest.Matrix{
+		Providers:   []string{"k8s"},
+		Actions:     helmtest.SupportedDeployActions,
+		ValuesFiles: []string{"data/values_with_kerberos.yaml"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"global.deployment.name": "pega",
+		})
+		deploymentYaml := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-deployment.yaml"})
+		yamlSplit := strings.Split(deploymentYaml, "---")
+		assertWeb(t, yamlSplit[1], options)
+		assertVolumeAndMountForKerberos(t, yamlSplit[1], true)
+
+		assertBatch(t, yamlSplit[2], options)
+		assertVolumeAndMountForKerberos(t, yamlSplit[2], true)
+
+		assertStream(t, yamlSplit[3], options)
+		assertVolumeAndMountForKerberos(t, yamlSplit[3], true)
+
+		options.ValuesFiles = []string{"data/values_without_kerberos.yaml"}
+
+		deploymentYaml = RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-deployment.yaml"})
+		yamlSplit = strings.Split(deploymentYaml, "---")
+		assertWeb(t, yamlSplit[1], options)
+		assertVolumeAndMountForKerberos(t, yamlSplit[1], false)
+
+		assertBatch(t, yamlSplit[2], options)
+		assertVolumeAndMountForKerberos(t, yamlSplit[2], false)
+
+		assertStream(t, yamlSplit[3], options)
+		assertVolumeAndMountForKerberos(t, yamlSplit[3], false)
+	})
 }
 
 func assertVolumeAndMountForKerberos(t *testing.T, tierYaml string, shouldHaveVol bool) {
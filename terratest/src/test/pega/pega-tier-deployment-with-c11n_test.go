@@ -2,6 +2,7 @@ package pega
 
 import (
 	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/networking/v1"
 	"path/filepath"
@@ -11,35 +12,25 @@ import (
 
 func TestConstellation(t *testing.T) {
 
-	var supportedVendors = []string{"k8s"}
-	var supportedOperations = []string{"deploy", "install-deploy", "upgrade-deploy"}
-	var deploymentNames = []string{"pega", "myapp-dev"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-		for _, operation := range supportedOperations {
-			for _, depName := range deploymentNames {
-				var constellationOptions = &helm.Options{
-					SetValues: map[string]string{
-						"global.deployment.name":        depName,
-						"global.provider":               vendor,
-						"global.actions.execute":        operation,
-						"constellation.enabled":         "true",
-						"installer.upgrade.upgradeType": "zero-downtime",
-					},
-				}
-				deploymentYaml := RenderTemplate(t, constellationOptions, helmChartPath, []string{"templates/pega-tier-deployment.yaml"})
-				yamlSplit := strings.Split(deploymentYaml, "---")
-				assertWeb(t, yamlSplit[1], constellationOptions)
-				assertBatch(t, yamlSplit[2], constellationOptions)
-				assertStream(t, yamlSplit[3], constellationOptions)
-				ingressYaml := RenderTemplate(t, constellationOptions, helmChartPath, []string{"templates/pega-tier-ingress.yaml"})
-				assertPegaTierIngress(t, ingressYaml, constellationOptions)
-			}
-		}
-	}
+	helmtest.Matrix{
+		Providers:       []string{"k8s"},
+		Actions:         helmtest.SupportedDeployActions,
+		DeploymentNames: []string{"pega", "myapp-dev"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"constellation.enabled": "true",
+		})
+		deploymentYaml := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-deployment.yaml"})
+		yamlSplit := strings.Split(deploymentYaml, "---")
+		assertWeb(t, yamlSplit[1], options)
+		assertBatch(t, yamlSplit[2], options)
+		assertStream(t, yamlSplit[3], options)
+		ingressYaml := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-ingress.yaml"})
+		assertPegaTierIngress(t, ingressYaml, options)
+	})
 }
 
 func assertPegaTierIngress(t *testing.T, ingressYaml string, options *helm.Options) {
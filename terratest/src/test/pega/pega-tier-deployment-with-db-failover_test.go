@@ -1,13 +1,12 @@
 package pega
 
 import (
-	"fmt"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
-	"strconv"
 
-	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
 	appsv1beta2 "k8s.io/api/apps/v1beta2"
@@ -15,53 +14,41 @@ import (
 )
 
 func TestPegaTierDeploymentWithDBFailover(t *testing.T) {
-	var supportedVendors = []string{"k8s", "openshift", "eks", "gke", "aks", "pks"}
-	var supportedOperations = []string{"deploy", "install-deploy", "upgrade-deploy"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-
-		for _, operation := range supportedOperations {
-
-            fmt.Println(vendor + "-" + operation)
-
-            var options = &helm.Options{
-                ValuesFiles: []string{"data/values_with_overidden_liveness_probe_config.yaml"},
-                SetValues: map[string]string{
-                    "global.provider":               vendor,
-                    "global.actions.execute":        operation,
-                    "installer.upgrade.upgradeType": "zero-downtime",
-                },
-            }
+	helmtest.Matrix{
+		Providers:   helmtest.SupportedProviders,
+		Actions:     helmtest.SupportedDeployActions,
+		ValuesFiles: []string{"data/values_with_overidden_liveness_probe_config.yaml"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(nil)
 
-            yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-deployment.yaml"})
-            yamlSplit := strings.Split(yamlContent, "---")
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-deployment.yaml"})
+		yamlSplit := strings.Split(yamlContent, "---")
 
-            //web tier uses defaults not in values.yaml (failure threshold: 3, period seconds: 30)
-            assertLivenessProbeFailureThreshold(t, yamlSplit[1], 4, 30)
+		//web tier uses defaults not in values.yaml (failure threshold: 3, period seconds: 30)
+		assertLivenessProbeFailureThreshold(t, yamlSplit[1], 4, 30)
 
-            //batch tier uses (failure threshold: 5, period seconds: 100)
-            assertLivenessProbeFailureThreshold(t, yamlSplit[2], 6, 100)
+		//batch tier uses (failure threshold: 5, period seconds: 100)
+		assertLivenessProbeFailureThreshold(t, yamlSplit[2], 6, 100)
 
-            //stream tier uses (failure threshold: 7, period seconds: 300) -- retryTimeout has max of 180s
-            //so we get into more interesting math...
-            assertLivenessProbeFailureThreshold(t, yamlSplit[3], 12, 180)
-		}
-	}
+		//stream tier uses (failure threshold: 7, period seconds: 300) -- retryTimeout has max of 180s
+		//so we get into more interesting math...
+		assertLivenessProbeFailureThreshold(t, yamlSplit[3], 12, 180)
+	})
 }
 
 func assertLivenessProbeFailureThreshold(t *testing.T, yaml string, expectedMaxRetries int, expectedRetryTimeout int) {
-    if (strings.Contains(yaml, "kind: StatefulSet")) {
-        var statefulsetObj appsv1beta2.StatefulSet
-	    UnmarshalK8SYaml(t, yaml, &statefulsetObj)
-	    VerifyPodSpecClassloaderRetrySettings(t, &statefulsetObj.Spec.Template.Spec, expectedMaxRetries, expectedRetryTimeout)
-    } else {
-	    var deploymentObj appsv1.Deployment
-	    UnmarshalK8SYaml(t, yaml, &deploymentObj)
-	    VerifyPodSpecClassloaderRetrySettings(t, &deploymentObj.Spec.Template.Spec,  expectedMaxRetries, expectedRetryTimeout)
-    }
+	if strings.Contains(yaml, "kind: StatefulSet") {
+		var statefulsetObj appsv1beta2.StatefulSet
+		UnmarshalK8SYaml(t, yaml, &statefulsetObj)
+		VerifyPodSpecClassloaderRetrySettings(t, &statefulsetObj.Spec.Template.Spec, expectedMaxRetries, expectedRetryTimeout)
+	} else {
+		var deploymentObj appsv1.Deployment
+		UnmarshalK8SYaml(t, yaml, &deploymentObj)
+		VerifyPodSpecClassloaderRetrySettings(t, &deploymentObj.Spec.Template.Spec, expectedMaxRetries, expectedRetryTimeout)
+	}
 }
 
 func VerifyPodSpecClassloaderRetrySettings(t *testing.T, pod *k8score.PodSpec, expectedMaxRetries int, expectedRetryTimeout int) {
@@ -69,12 +56,12 @@ func VerifyPodSpecClassloaderRetrySettings(t *testing.T, pod *k8score.PodSpec, e
 	retryTimeout := ""
 
 	for _, envItem := range pod.Containers[0].Env {
-	    if (envItem.Name=="RETRY_TIMEOUT") {
-            retryTimeout = envItem.Value
-	    }
-        if (envItem.Name=="MAX_RETRIES") {
-            maxRetries = envItem.Value
-        }
+		if envItem.Name == "RETRY_TIMEOUT" {
+			retryTimeout = envItem.Value
+		}
+		if envItem.Name == "MAX_RETRIES" {
+			maxRetries = envItem.Value
+		}
 	}
 
 	require.NotEqual(t, "", retryTimeout)
@@ -83,4 +70,3 @@ func VerifyPodSpecClassloaderRetrySettings(t *testing.T, pod *k8score.PodSpec, e
 	require.Equal(t, strconv.Itoa(expectedRetryTimeout), retryTimeout)
 	require.Equal(t, strconv.Itoa(expectedMaxRetries), maxRetries)
 }
-
@@ -2,6 +2,7 @@ package pega
 
 import (
 	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
 	"path/filepath"
@@ -11,31 +12,23 @@ import (
 
 func TestPegaDeploymentWithExternalSecretCerts(t *testing.T) {
 
-	var supportedVendors = []string{"k8s"}
-	var supportedOperations = []string{"deploy", "install-deploy", "upgrade-deploy"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-		for _, operation := range supportedOperations {
-
-			var options = &helm.Options{
-				ValuesFiles: []string{"data/values_with_externalcerts.yaml"},
-				SetValues: map[string]string{
-					"global.deployment.name":        "pega",
-					"global.provider":               vendor,
-					"global.actions.execute":        operation,
-					"installer.upgrade.upgradeType": "zero-downtime",
-				},
-			}
-			deploymentYaml := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-deployment.yaml"})
-			yamlSplit := strings.Split(deploymentYaml, "---")
-			assertWeb(t, yamlSplit[1], options)
-			assertVolumeAndMount(t, yamlSplit[1], options, true)
-			assertMountedCertificateSecrets(t, yamlSplit[1], options)
-		}
-	}
+	helmtest.Matrix{
+		Providers:   []string{"k8s"},
+		Actions:     helmtest.SupportedDeployActions,
+		ValuesFiles: []string{"data/values_with_externalcerts.yaml"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"global.deployment.name": "pega",
+		})
+		deploymentYaml := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-deployment.yaml"})
+		yamlSplit := strings.Split(deploymentYaml, "---")
+		assertWeb(t, yamlSplit[1], options)
+		assertVolumeAndMount(t, yamlSplit[1], options, true)
+		assertMountedCertificateSecrets(t, yamlSplit[1], options)
+	})
 }
 func assertMountedCertificateSecrets(t *testing.T, tierYaml string, options *helm.Options) {
 	var deploymentObj appsv1.Deployment
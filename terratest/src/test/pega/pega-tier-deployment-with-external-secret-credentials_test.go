@@ -1,8 +1,8 @@
 package pega
 
 import (
-	"fmt"
 	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
 	"path/filepath"
@@ -12,30 +12,22 @@ import (
 
 func TestPegaDeploymentWithExternalSecretCredentials(t *testing.T) {
 
-	var supportedVendors = []string{"k8s"}
-	var supportedOperations = []string{"deploy", "install-deploy", "upgrade-deploy"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-		for _, operation := range supportedOperations {
-
-			var options = &helm.Options{
-				ValuesFiles: []string{"data/values_with_externalsecrets.yaml"},
-				SetValues: map[string]string{
-					"global.deployment.name":        "pega",
-					"global.provider":               vendor,
-					"global.actions.execute":        operation,
-					"installer.upgrade.upgradeType": "zero-downtime",
-				},
-			}
-			deploymentYaml := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-deployment.yaml"})
-			yamlSplit := strings.Split(deploymentYaml, "---")
-			assertWeb(t, yamlSplit[1], options)
-			assertCredentialVolumeAndMount(t, yamlSplit[1], options, true)
-		}
-	}
+	helmtest.Matrix{
+		Providers:   []string{"k8s"},
+		Actions:     helmtest.SupportedDeployActions,
+		ValuesFiles: []string{"data/values_with_externalsecrets.yaml"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"global.deployment.name": "pega",
+		})
+		deploymentYaml := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-deployment.yaml"})
+		yamlSplit := strings.Split(deploymentYaml, "---")
+		assertWeb(t, yamlSplit[1], options)
+		assertCredentialVolumeAndMount(t, yamlSplit[1], options, true)
+	})
 }
 func assertCredentialVolumeAndMount(t *testing.T, tierYaml string, options *helm.Options, shouldHaveVol bool) {
 	var deploymentObj appsv1.Deployment
@@ -46,7 +38,6 @@ func assertCredentialVolumeAndMount(t *testing.T, tierYaml string, options *helm
 	for _, vol := range pod.Volumes {
 		if vol.Name == "pega-volume-credentials" {
 			sources := vol.VolumeSource.Projected.Sources
-			fmt.Println(sources[0].Secret.LocalObjectReference.Name)
 			require.Equal(t, len(sources), 6)
 			require.Equal(t, sources[0].Secret.LocalObjectReference.Name, "db-secret")
 			require.Equal(t, sources[1].Secret.LocalObjectReference.Name, "hazelcast-secret")
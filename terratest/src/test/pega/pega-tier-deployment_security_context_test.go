@@ -1,147 +1,99 @@
 package pega
 
 import (
-	"fmt"
 	"path/filepath"
 	"strings"
 	"testing"
 
-	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
 )
 
 func TestPegaTierDeploymentSecurityContextDefaults(t *testing.T) {
-	var supportedVendors = []string{"k8s", "openshift", "eks", "gke", "aks", "pks"}
-	var supportedOperations = []string{"deploy"}
-	var deploymentNames = []string{"myapp-dev"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
+	helmtest.Matrix{
+		Providers:       helmtest.SupportedProviders,
+		Actions:         []string{"deploy"},
+		DeploymentNames: []string{"myapp-dev"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(nil)
 
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-deployment.yaml"})
+		yamlSplit := strings.Split(yamlContent, "---")
 		var depObj appsv1.Deployment
-
-		for _, operation := range supportedOperations {
-
-			for _, depName := range deploymentNames {
-
-				fmt.Println(vendor + "-" + operation)
-
-				var options = &helm.Options{
-					SetValues: map[string]string{
-						"global.provider":        vendor,
-						"global.actions.execute": operation,
-						"global.deployment.name": depName,
-					},
-				}
-
-				yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-deployment.yaml"})
-				yamlSplit := strings.Split(yamlContent, "---")
-				UnmarshalK8SYaml(t, yamlSplit[1], &depObj)
-
-				if vendor != "openshift" {
-					// for openshift and for others
-					require.Equal(t, int64(0), *depObj.Spec.Template.Spec.SecurityContext.FSGroup)
-					require.Equal(t, int64(9001), *depObj.Spec.Template.Spec.SecurityContext.RunAsUser)
-				} else {
-					require.Nil(t, depObj.Spec.Template.Spec.SecurityContext)
-				}
-
-			}
+		UnmarshalK8SYaml(t, yamlSplit[1], &depObj)
+
+		if combination.Provider != "openshift" {
+			// for openshift and for others
+			require.Equal(t, int64(0), *depObj.Spec.Template.Spec.SecurityContext.FSGroup)
+			require.Equal(t, int64(9001), *depObj.Spec.Template.Spec.SecurityContext.RunAsUser)
+		} else {
+			require.Nil(t, depObj.Spec.Template.Spec.SecurityContext)
 		}
-	}
+	})
 }
 
 func TestPegaTierDeploymentSecurityContextAdditional(t *testing.T) {
-	var supportedVendors = []string{"k8s", "openshift"}
-	var supportedOperations = []string{"deploy"}
-	var deploymentNames = []string{"myapp-dev"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-
+	helmtest.Matrix{
+		Providers:       []string{"k8s", "openshift"},
+		Actions:         []string{"deploy"},
+		DeploymentNames: []string{"myapp-dev"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"global.tier[0].securityContext.runAsNonRoot":          "true",
+			"global.tier[0].securityContext.supplementalGroups[0]": "2000",
+		})
+
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-deployment.yaml"})
+		yamlSplit := strings.Split(yamlContent, "---")
 		var depObj appsv1.Deployment
-
-		for _, operation := range supportedOperations {
-
-			for _, depName := range deploymentNames {
-
-				fmt.Println(vendor + "-" + operation)
-
-				var options = &helm.Options{
-					SetValues: map[string]string{
-						"global.provider":                                      vendor,
-						"global.actions.execute":                               operation,
-						"global.deployment.name":                               depName,
-						"global.tier[0].securityContext.runAsNonRoot":          "true",
-						"global.tier[0].securityContext.supplementalGroups[0]": "2000",
-					},
-				}
-
-				yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-deployment.yaml"})
-				yamlSplit := strings.Split(yamlContent, "---")
-				UnmarshalK8SYaml(t, yamlSplit[1], &depObj)
-
-				require.Equal(t, bool(true), *depObj.Spec.Template.Spec.SecurityContext.RunAsNonRoot)
-				require.Equal(t, int64(2000), *&depObj.Spec.Template.Spec.SecurityContext.SupplementalGroups[0])
-
-				if vendor != "openshift" {
-					// for openshift and for others
-					require.Equal(t, int64(0), *depObj.Spec.Template.Spec.SecurityContext.FSGroup)
-					require.Equal(t, int64(9001), *depObj.Spec.Template.Spec.SecurityContext.RunAsUser)
-				} else {
-					require.Nil(t, depObj.Spec.Template.Spec.SecurityContext.FSGroup)
-					require.Nil(t, depObj.Spec.Template.Spec.SecurityContext.RunAsUser)
-				}
-
-			}
+		UnmarshalK8SYaml(t, yamlSplit[1], &depObj)
+
+		require.Equal(t, bool(true), *depObj.Spec.Template.Spec.SecurityContext.RunAsNonRoot)
+		require.Equal(t, int64(2000), *&depObj.Spec.Template.Spec.SecurityContext.SupplementalGroups[0])
+
+		if combination.Provider != "openshift" {
+			// for openshift and for others
+			require.Equal(t, int64(0), *depObj.Spec.Template.Spec.SecurityContext.FSGroup)
+			require.Equal(t, int64(9001), *depObj.Spec.Template.Spec.SecurityContext.RunAsUser)
+		} else {
+			require.Nil(t, depObj.Spec.Template.Spec.SecurityContext.FSGroup)
+			require.Nil(t, depObj.Spec.Template.Spec.SecurityContext.RunAsUser)
 		}
-	}
+	})
 }
 
 func TestPegaTierDeploymentSecurityContextOverrideDefault(t *testing.T) {
-	var supportedVendors = []string{"k8s", "eks", "gke"}
-	var supportedOperations = []string{"deploy"}
-	var deploymentNames = []string{"myapp-dev"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
+	helmtest.Matrix{
+		Providers:       []string{"k8s", "eks", "gke"},
+		Actions:         []string{"deploy"},
+		DeploymentNames: []string{"myapp-dev"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"global.tier[0].securityContext.fsGroup": "2",
+		})
+
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-deployment.yaml"})
+		yamlSplit := strings.Split(yamlContent, "---")
 		var depObj appsv1.Deployment
-
-		for _, operation := range supportedOperations {
-
-			for _, depName := range deploymentNames {
-
-				fmt.Println(vendor + "-" + operation)
-
-				var options = &helm.Options{
-					SetValues: map[string]string{
-						"global.provider":                        vendor,
-						"global.actions.execute":                 operation,
-						"global.deployment.name":                 depName,
-						"global.tier[0].securityContext.fsGroup": "2",
-					},
-				}
-
-				yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-deployment.yaml"})
-				yamlSplit := strings.Split(yamlContent, "---")
-				UnmarshalK8SYaml(t, yamlSplit[1], &depObj)
-
-				if vendor != "openshift" {
-					// for openshift and for others
-					require.Equal(t, int64(2), *depObj.Spec.Template.Spec.SecurityContext.FSGroup)
-					require.Equal(t, int64(9001), *depObj.Spec.Template.Spec.SecurityContext.RunAsUser)
-				} else {
-					require.Equal(t, int64(2), *depObj.Spec.Template.Spec.SecurityContext.FSGroup)
-					require.Nil(t, depObj.Spec.Template.Spec.SecurityContext.RunAsUser)
-				}
-			}
+		UnmarshalK8SYaml(t, yamlSplit[1], &depObj)
+
+		if combination.Provider != "openshift" {
+			// for openshift and for others
+			require.Equal(t, int64(2), *depObj.Spec.Template.Spec.SecurityContext.FSGroup)
+			require.Equal(t, int64(9001), *depObj.Spec.Template.Spec.SecurityContext.RunAsUser)
+		} else {
+			require.Equal(t, int64(2), *depObj.Spec.Template.Spec.SecurityContext.FSGroup)
+			require.Nil(t, depObj.Spec.Template.Spec.SecurityContext.RunAsUser)
 		}
-	}
+	})
 }
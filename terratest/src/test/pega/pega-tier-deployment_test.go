@@ -1,12 +1,12 @@
 package pega
 
 import (
-	"fmt"
 	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
 	appsv1beta2 "k8s.io/api/apps/v1beta2"
@@ -17,41 +17,21 @@ import (
 var initContainers = []string{"wait-for-pegasearch", "wait-for-cassandra"}
 
 func TestPegaTierDeployment(t *testing.T) {
-	var supportedVendors = []string{"k8s", "openshift", "eks", "gke", "aks", "pks"}
-	var supportedOperations = []string{"deploy", "install-deploy", "upgrade-deploy"}
-	var deploymentNames = []string{"pega", "myapp-dev"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-
-		for _, operation := range supportedOperations {
-
-			for _, depName := range deploymentNames {
-
-				fmt.Println(vendor + "-" + operation)
-
-				var options = &helm.Options{
-					SetValues: map[string]string{
-						"global.provider":               vendor,
-						"global.actions.execute":        operation,
-						"global.deployment.name":        depName,
-						"installer.upgrade.upgradeType": "zero-downtime",
-						"global.storageClassName":       "storage-class",
-					},
-				}
-
-				yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-deployment.yaml"})
-				yamlSplit := strings.Split(yamlContent, "---")
-				assertWeb(t, yamlSplit[1], options)
-				assertBatch(t, yamlSplit[2], options)
-				assertStream(t, yamlSplit[3], options)
-				assertStreamWithSorageClass(t, yamlSplit[3], options)
-
-			}
-		}
-	}
+	pegaDeploymentMatrix.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"global.storageClassName": "storage-class",
+		})
+
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-deployment.yaml"})
+		yamlSplit := strings.Split(yamlContent, "---")
+		assertWeb(t, yamlSplit[1], options)
+		assertBatch(t, yamlSplit[2], options)
+		assertStream(t, yamlSplit[3], options)
+		assertStreamWithSorageClass(t, yamlSplit[3], options)
+	})
 }
 
 func assertStreamWithSorageClass(t *testing.T, streamYaml string, options *helm.Options) {
@@ -63,7 +43,6 @@ func assertStreamWithSorageClass(t *testing.T, streamYaml string, options *helm.
 }
 
 func TestPegaTierDeploymentWithFSGroup(t *testing.T) {
-	var supportedVendors = []string{"k8s", "eks", "gke", "aks", "pks"}
 	customFsGroups := map[string]int64{
 		"1000": 1000,
 		"2000": 2000,
@@ -72,32 +51,31 @@ func TestPegaTierDeploymentWithFSGroup(t *testing.T) {
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	var depObj appsv1.Deployment
-
-	for _, vendor := range supportedVendors {
+	helmtest.Matrix{
+		Providers:       helmtest.SupportedProviders,
+		Actions:         []string{"deploy"},
+		DeploymentNames: []string{"pega"},
+		// openshift assigns the fsGroup from the project's range instead of the chart's default
+		Exclude: []helmtest.Combination{{Provider: "openshift"}},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
 		for key, value := range customFsGroups {
-			var options = &helm.Options{
-				SetValues: map[string]string{
-					"global.provider":                        vendor,
-					"global.actions.execute":                 "deploy",
-					"global.deployment.name":                 "pega",
-					"installer.upgrade.upgradeType":          "zero-downtime",
-					"global.tier[0].name":                    "web",
-					"global.tier[1].name":                    "batch",
-					"global.tier[2].name":                    "stream",
-					"global.tier[0].securityContext.fsGroup": key, // web tier
-					"global.tier[1].securityContext.fsGroup": key, // batch tier
-					"global.tier[2].securityContext.fsGroup": key, // stream tier
-				},
-			}
+			options := combination.HelmOptions(map[string]string{
+				"global.tier[0].name":                    "web",
+				"global.tier[1].name":                    "batch",
+				"global.tier[2].name":                    "stream",
+				"global.tier[0].securityContext.fsGroup": key, // web tier
+				"global.tier[1].securityContext.fsGroup": key, // batch tier
+				"global.tier[2].securityContext.fsGroup": key, // stream tier
+			})
 
 			yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-deployment.yaml"})
 			yamlSplit := strings.Split(yamlContent, "---")
 
+			var depObj appsv1.Deployment
 			UnmarshalK8SYaml(t, yamlSplit[1], &depObj)
 			require.Equal(t, value, *depObj.Spec.Template.Spec.SecurityContext.FSGroup)
 		}
-	}
+	})
 }
 
 func assertStream(t *testing.T, streamYaml string, options *helm.Options) {
@@ -0,0 +1,148 @@
+package pega
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+	"github.com/stretchr/testify/require"
+	k8score "k8s.io/api/core/v1"
+)
+
+// httpRouteRuleFor - the rule of the route matching the given path
+func httpRouteRuleFor(t *testing.T, route helmtest.HTTPRoute, pathType string, path string) helmtest.HTTPRouteRule {
+	for _, rule := range route.Spec.Rules {
+		for _, match := range rule.Matches {
+			if match.Path.Type == pathType && match.Path.Value == path {
+				return rule
+			}
+		}
+	}
+	require.Failf(t, "missing HTTPRoute rule", "HTTPRoute %s has no rule matching %s %s", route.Metadata.Name, pathType, path)
+	return helmtest.HTTPRouteRule{}
+}
+
+// requireRoutesToService - the rule sends its requests to a port of the rendered Service of the tier
+func requireRoutesToService(t *testing.T, parser *helmtest.HelmChartParser, rule helmtest.HTTPRouteRule, name string, port int32) {
+	require.Len(t, rule.BackendRefs, 1)
+	require.Equal(t, name, rule.BackendRefs[0].Name)
+	require.Equal(t, port, rule.BackendRefs[0].Port)
+
+	services := helmtest.QueryAs[k8score.Service](parser, helmtest.ResourceQuery{Kind: "Service", Name: name})
+	require.Len(t, services, 1, "HTTPRoute backend %s is no rendered Service", name)
+	var ports []int32
+	for _, servicePort := range services[0].Spec.Ports {
+		ports = append(ports, servicePort.Port)
+	}
+	require.Contains(t, ports, port)
+}
+
+// TestPegaTierGateway - tiers with ingress.type gateway get HTTPRoutes attached to their parent Gateway in place of
+// the ingress of the provider, while the other tiers keep theirs
+func TestPegaTierGateway(t *testing.T) {
+	testsPath, err := filepath.Abs(PegaHelmChartTestsPath)
+	require.NoError(t, err)
+
+	pegaDeploymentMatrix.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := pegaOptions(combination, nil)
+		helmTest := newPegaChartTest(t, options, testsPath+"/data/values_gateway.yaml")
+		parser := helmtest.NewHelmConfigParser(helmTest)
+
+		require.Len(t, parser.Query(helmtest.ResourceQuery{Kind: "HTTPRoute"}), 3)
+		ingressKind := "Ingress"
+		if combination.Provider == "openshift" {
+			ingressKind = "Route"
+		}
+		ingresses := parser.Query(helmtest.ResourceQuery{Kind: ingressKind})
+		require.Len(t, ingresses, 1)
+		require.Equal(t, getObjName(options, "-stream"), ingresses[0].Name)
+
+		web := helmtest.QueryAs[helmtest.HTTPRoute](parser, helmtest.ResourceQuery{Kind: "HTTPRoute", Name: getObjName(options, "-web")})[0]
+		require.Equal(t, map[string]string{"team": "web"}, web.Metadata.Annotations)
+		require.Len(t, web.Spec.ParentRefs, 1)
+		require.Equal(t, "gateway.networking.k8s.io", web.Spec.ParentRefs[0].Group)
+		require.Equal(t, "Gateway", web.Spec.ParentRefs[0].Kind)
+		require.Equal(t, "pega-gateway", web.Spec.ParentRefs[0].Name)
+		require.Equal(t, "gateway-system", web.Spec.ParentRefs[0].Namespace)
+		require.Equal(t, "https", web.Spec.ParentRefs[0].SectionName)
+		require.Equal(t, []string{"web.example.com"}, web.Spec.Hostnames)
+		require.Len(t, web.Spec.Rules, 3)
+
+		c11n := httpRouteRuleFor(t, web, "PathPrefix", "/c11n")
+		require.Len(t, c11n.BackendRefs, 1)
+		require.Equal(t, "constellation", c11n.BackendRefs[0].Name)
+		require.Equal(t, int32(3000), c11n.BackendRefs[0].Port)
+		require.Nil(t, c11n.SessionPersistence)
+
+		root := httpRouteRuleFor(t, web, "Exact", "/")
+		require.Empty(t, root.BackendRefs)
+		require.Len(t, root.Filters, 1)
+		require.Equal(t, "RequestRedirect", root.Filters[0].Type)
+		require.Equal(t, "ReplaceFullPath", root.Filters[0].RequestRedirect.Path.Type)
+		require.Equal(t, "/pega/", root.Filters[0].RequestRedirect.Path.ReplaceFullPath)
+
+		webApp := httpRouteRuleFor(t, web, "PathPrefix", "/pega")
+		requireRoutesToService(t, parser, webApp, getObjName(options, "-web"), 80)
+		require.Contains(t, tierPodTemplate(t, parser, getObjName(options, "-web")).Spec.Containers[0].Env,
+			k8score.EnvVar{Name: "PEGA_APP_CONTEXT_PATH", Value: "pega"})
+		require.Equal(t, "2m", webApp.Timeouts.Request)
+		require.NotNil(t, webApp.SessionPersistence)
+		require.Equal(t, "Cookie", webApp.SessionPersistence.Type)
+		require.Equal(t, "PEGA-SESSION", webApp.SessionPersistence.SessionName)
+		require.Equal(t, "1020s", webApp.SessionPersistence.AbsoluteTimeout)
+		require.Equal(t, "30m", webApp.SessionPersistence.IdleTimeout)
+
+		redirect := helmtest.QueryAs[helmtest.HTTPRoute](parser, helmtest.ResourceQuery{Kind: "HTTPRoute", Name: getObjName(options, "-web-http-redirect")})[0]
+		require.Len(t, redirect.Spec.ParentRefs, 1)
+		require.Equal(t, "pega-gateway", redirect.Spec.ParentRefs[0].Name)
+		require.Equal(t, "http", redirect.Spec.ParentRefs[0].SectionName)
+		require.Equal(t, []string{"web.example.com"}, redirect.Spec.Hostnames)
+		require.Len(t, redirect.Spec.Rules, 1)
+		require.Empty(t, redirect.Spec.Rules[0].BackendRefs)
+		require.Equal(t, "RequestRedirect", redirect.Spec.Rules[0].Filters[0].Type)
+		require.Equal(t, "https", redirect.Spec.Rules[0].Filters[0].RequestRedirect.Scheme)
+		require.Equal(t, 301, redirect.Spec.Rules[0].Filters[0].RequestRedirect.StatusCode)
+
+		batch := helmtest.QueryAs[helmtest.HTTPRoute](parser, helmtest.ResourceQuery{Kind: "HTTPRoute", Name: getObjName(options, "-batch")})[0]
+		require.Empty(t, batch.Metadata.Annotations)
+		require.Equal(t, "pega-gateway", batch.Spec.ParentRefs[0].Name)
+		require.Empty(t, batch.Spec.ParentRefs[0].Namespace)
+		require.Empty(t, batch.Spec.ParentRefs[0].SectionName)
+		require.Empty(t, batch.Spec.Hostnames)
+		require.Len(t, batch.Spec.Rules, 2)
+		httpRouteRuleFor(t, batch, "PathPrefix", "/c11n")
+		batchApp := httpRouteRuleFor(t, batch, "PathPrefix", "/batch")
+		requireRoutesToService(t, parser, batchApp, getObjName(options, "-batch"), 80)
+		require.Empty(t, batchApp.Timeouts.Request)
+		require.Nil(t, batchApp.SessionPersistence)
+	})
+}
+
+// TestPegaTierGatewayDisabled - the example values.yaml renders no HTTPRoute
+func TestPegaTierGatewayDisabled(t *testing.T) {
+	helmTest := newPegaChartTest(t, pegaOptions(k8sDeploy, nil))
+	parser := helmtest.NewHelmConfigParser(helmTest)
+
+	require.Empty(t, parser.Query(helmtest.ResourceQuery{Kind: "HTTPRoute"}))
+	require.Len(t, parser.Query(helmtest.ResourceQuery{Kind: "Ingress"}), 2)
+}
+
+// TestPegaTierGatewaySessionPersistenceDisabled - the experimental sessionPersistence field is left out of the route
+// when it is disabled, so the route is accepted with the standard Gateway API definitions
+func TestPegaTierGatewaySessionPersistenceDisabled(t *testing.T) {
+	testsPath, err := filepath.Abs(PegaHelmChartTestsPath)
+	require.NoError(t, err)
+
+	options := pegaOptions(k8sDeploy, map[string]string{
+		"global.tier[0].ingress.gateway.sessionPersistence.enabled": "false",
+	})
+	helmTest := newPegaChartTest(t, options, testsPath+"/data/values_gateway.yaml")
+	parser := helmtest.NewHelmConfigParser(helmTest)
+
+	for _, route := range helmtest.QueryAs[helmtest.HTTPRoute](parser, helmtest.ResourceQuery{Kind: "HTTPRoute"}) {
+		for _, rule := range route.Spec.Rules {
+			require.Nil(t, rule.SessionPersistence, "HTTPRoute %s", route.Metadata.Name)
+		}
+	}
+	require.NotContains(t, helmTest.Render(), "sessionPersistence")
+}
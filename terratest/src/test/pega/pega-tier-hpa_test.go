@@ -1,12 +1,12 @@
 package pega
 
 import (
-	"fmt"
 	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	autoscaling "k8s.io/api/autoscaling/v2beta2"
 	v1 "k8s.io/api/core/v1"
@@ -14,59 +14,34 @@ import (
 )
 
 func TestPegaTierHPA(t *testing.T) {
-	var supportedVendors = []string{"k8s", "openshift", "eks", "gke", "aks", "pks"}
-	var supportedOperations = []string{"deploy", "install-deploy", "upgrade-deploy"}
-	var deploymentNames = []string{"pega", "myapp-dev"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-
-		for _, operation := range supportedOperations {
-
-			for _, depName := range deploymentNames {
-
-				fmt.Println(vendor + "-" + operation)
-
-				var options = &helm.Options{
-					SetValues: map[string]string{
-						"global.deployment.name":        depName,
-						"global.provider":               vendor,
-						"global.actions.execute":        operation,
-						"installer.upgrade.upgradeType": "zero-downtime",
-					},
-				}
-
-				yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-hpa.yaml"})
-				verifyPegaHPAs(t, yamlContent, options, []hpa{
-					{
-						name:          getObjName(options, "-web-hpa"),
-						targetRefName: getObjName(options, "-web"),
-						kind:          "Deployment",
-						apiversion:    "apps/v1",
-						cpu:           true,
-						cpuValue:      parseResourceValue(t, "2.55"),
-					},
-					{
-						name:          getObjName(options, "-batch-hpa"),
-						targetRefName: getObjName(options, "-batch"),
-						kind:          "Deployment",
-						apiversion:    "apps/v1",
-						cpu:           true,
-						cpuValue:      parseResourceValue(t, "2.55"),
-					},
-				})
-			}
-		}
-	}
+	pegaDeploymentMatrix.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(nil)
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-hpa.yaml"})
+		verifyPegaHPAs(t, yamlContent, options, []hpa{
+			{
+				name:          getObjName(options, "-web-hpa"),
+				targetRefName: getObjName(options, "-web"),
+				kind:          "Deployment",
+				apiversion:    "apps/v1",
+				cpu:           true,
+				cpuValue:      parseResourceValue(t, "2.55"),
+			},
+			{
+				name:          getObjName(options, "-batch-hpa"),
+				targetRefName: getObjName(options, "-batch"),
+				kind:          "Deployment",
+				apiversion:    "apps/v1",
+				cpu:           true,
+				cpuValue:      parseResourceValue(t, "2.55"),
+			},
+		})
+	})
 }
 
 func TestPegaTierHPAWithCustomLabel(t *testing.T) {
-	var supportedVendors = []string{"k8s", "openshift", "eks", "gke", "aks", "pks"}
-	var supportedOperations = []string{"deploy", "install-deploy", "upgrade-deploy"}
-	var deploymentNames = []string{"pega", "myapp-dev"}
-
 	expectedWebLabels := map[string]string{"web-label": "somevalue", "web-other-label": "someothervalue"}
 	expectedBatchLabels := map[string]string{"batch-label": "batchlabel", "batch-other-label": "anothervalue"}
 
@@ -76,205 +51,130 @@ func TestPegaTierHPAWithCustomLabel(t *testing.T) {
 	testsPath, err := filepath.Abs(PegaHelmChartTestsPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-
-		for _, operation := range supportedOperations {
-
-			for _, depName := range deploymentNames {
-
-				fmt.Println(vendor + "-" + operation)
-
-				var options = &helm.Options{
-					SetValues: map[string]string{
-						"global.deployment.name":        depName,
-						"global.provider":               vendor,
-						"global.actions.execute":        operation,
-						"installer.upgrade.upgradeType": "zero-downtime",
-					},
-				}
-
-				yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-hpa.yaml"}, "--values", testsPath+"/data/values_hpa_custom_label.yaml")
-				verifyPegaHPAs(t, yamlContent, options, []hpa{
-					{
-						name:          getObjName(options, "-web-hpa"),
-						targetRefName: getObjName(options, "-web"),
-						kind:          "Deployment",
-						apiversion:    "apps/v1",
-						labels:        expectedWebLabels,
-						cpu:           true,
-						cpuValue:      parseResourceValue(t, "2.55"),
-					},
-					{
-						name:          getObjName(options, "-batch-hpa"),
-						targetRefName: getObjName(options, "-batch"),
-						kind:          "Deployment",
-						apiversion:    "apps/v1",
-						labels:        expectedBatchLabels,
-						cpu:           true,
-						cpuValue:      parseResourceValue(t, "2.55"),
-					},
-				})
-			}
-		}
-	}
+	pegaDeploymentMatrix.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(nil)
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-hpa.yaml"}, "--values", testsPath+"/data/values_hpa_custom_label.yaml")
+		verifyPegaHPAs(t, yamlContent, options, []hpa{
+			{
+				name:          getObjName(options, "-web-hpa"),
+				targetRefName: getObjName(options, "-web"),
+				kind:          "Deployment",
+				apiversion:    "apps/v1",
+				labels:        expectedWebLabels,
+				cpu:           true,
+				cpuValue:      parseResourceValue(t, "2.55"),
+			},
+			{
+				name:          getObjName(options, "-batch-hpa"),
+				targetRefName: getObjName(options, "-batch"),
+				kind:          "Deployment",
+				apiversion:    "apps/v1",
+				labels:        expectedBatchLabels,
+				cpu:           true,
+				cpuValue:      parseResourceValue(t, "2.55"),
+			},
+		})
+	})
 }
 
 func TestPegaTierHPADisableTarget(t *testing.T) {
-	var supportedVendors = []string{"k8s", "openshift", "eks", "gke", "aks", "pks"}
-	var supportedOperations = []string{"deploy", "install-deploy", "upgrade-deploy"}
-	var deploymentNames = []string{"pega", "myapp-dev"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
 	testsPath, err := filepath.Abs(PegaHelmChartTestsPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-
-		for _, operation := range supportedOperations {
-
-			for _, depName := range deploymentNames {
-				fmt.Println(vendor + "-" + operation)
-
-				var options = &helm.Options{
-					SetValues: map[string]string{
-						"global.deployment.name":        depName,
-						"global.provider":               vendor,
-						"global.actions.execute":        operation,
-						"installer.upgrade.upgradeType": "zero-downtime",
-					},
-				}
-
-				yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-hpa.yaml"}, "--values", testsPath+"/data/values_hpa_disabletarget.yaml")
-				verifyPegaHPAs(t, yamlContent, options, []hpa{
-					{
-						name:          getObjName(options, "-web-hpa"),
-						targetRefName: getObjName(options, "-web"),
-						kind:          "Deployment",
-						apiversion:    "apps/v1",
-						mem:           true,
-						memPercent:    85,
-					},
-					{
-						name:          getObjName(options, "-batch-hpa"),
-						targetRefName: getObjName(options, "-batch"),
-						kind:          "Deployment",
-						apiversion:    "apps/v1",
-						cpu:           true,
-						cpuValue:      parseResourceValue(t, "2.55"),
-					},
-				})
-			}
-		}
-	}
+	pegaDeploymentMatrix.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(nil)
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-hpa.yaml"}, "--values", testsPath+"/data/values_hpa_disabletarget.yaml")
+		verifyPegaHPAs(t, yamlContent, options, []hpa{
+			{
+				name:          getObjName(options, "-web-hpa"),
+				targetRefName: getObjName(options, "-web"),
+				kind:          "Deployment",
+				apiversion:    "apps/v1",
+				mem:           true,
+				memPercent:    85,
+			},
+			{
+				name:          getObjName(options, "-batch-hpa"),
+				targetRefName: getObjName(options, "-batch"),
+				kind:          "Deployment",
+				apiversion:    "apps/v1",
+				cpu:           true,
+				cpuValue:      parseResourceValue(t, "2.55"),
+			},
+		})
+	})
 }
 
 func TestPegaTierOverrideValues(t *testing.T) {
-	var supportedVendors = []string{"k8s", "openshift", "eks", "gke", "aks", "pks"}
-	var supportedOperations = []string{"deploy", "install-deploy", "upgrade-deploy"}
-	var deploymentNames = []string{"pega", "myapp-dev"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
 	testsPath, err := filepath.Abs(PegaHelmChartTestsPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-
-		for _, operation := range supportedOperations {
-
-			for _, depName := range deploymentNames {
-				fmt.Println(vendor + "-" + operation + "-" + depName)
-
-				var options = &helm.Options{
-					SetValues: map[string]string{
-						"global.provider":               vendor,
-						"global.actions.execute":        operation,
-						"installer.upgrade.upgradeType": "zero-downtime",
-					},
-				}
-
-				yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-hpa.yaml"}, "--values", testsPath+"/data/values_hpa_overridevalues.yaml")
-				verifyPegaHPAs(t, yamlContent, options, []hpa{
-					{
-						name:          getObjName(options, "-web-hpa"),
-						targetRefName: getObjName(options, "-web"),
-						kind:          "Deployment",
-						apiversion:    "apps/v1",
-						cpu:           true,
-						cpuValue:      parseResourceValue(t, "4.13"),
-						mem:           true,
-						memPercent:    42,
-					},
-					{
-						name:          getObjName(options, "-batch-hpa"),
-						targetRefName: getObjName(options, "-batch"),
-						kind:          "Deployment",
-						apiversion:    "apps/v1",
-						cpu:           true,
-						cpuPercent:    24,
-					},
-				})
-			}
-		}
-	}
+	pegaDeploymentMatrix.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(nil)
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-hpa.yaml"}, "--values", testsPath+"/data/values_hpa_overridevalues.yaml")
+		verifyPegaHPAs(t, yamlContent, options, []hpa{
+			{
+				name:          getObjName(options, "-web-hpa"),
+				targetRefName: getObjName(options, "-web"),
+				kind:          "Deployment",
+				apiversion:    "apps/v1",
+				cpu:           true,
+				cpuValue:      parseResourceValue(t, "4.13"),
+				mem:           true,
+				memPercent:    42,
+			},
+			{
+				name:          getObjName(options, "-batch-hpa"),
+				targetRefName: getObjName(options, "-batch"),
+				kind:          "Deployment",
+				apiversion:    "apps/v1",
+				cpu:           true,
+				cpuPercent:    24,
+			},
+		})
+	})
 }
 
 func TestPegaTierHPAWithBehavior(t *testing.T) {
-	var supportedVendors = []string{"k8s", "openshift", "eks", "gke", "aks", "pks"}
-	var supportedOperations = []string{"deploy", "install-deploy", "upgrade-deploy"}
-	var deploymentNames = []string{"pega", "myapp-dev"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
 	testsPath, err := filepath.Abs(PegaHelmChartTestsPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-
-		for _, operation := range supportedOperations {
-
-			for _, depName := range deploymentNames {
-				fmt.Println(vendor + "-" + operation + "-" + depName)
-
-				var options = &helm.Options{
-					SetValues: map[string]string{
-						"global.provider":               vendor,
-						"global.actions.execute":        operation,
-						"installer.upgrade.upgradeType": "zero-downtime",
-					},
-				}
-
-				yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-hpa.yaml"}, "--values", testsPath+"/data/values_hpa_behavior.yaml")
-				verifyPegaHPAs(t, yamlContent, options, []hpa{
-					{
-						name:                         getObjName(options, "-web-hpa"),
-						targetRefName:                getObjName(options, "-web"),
-						kind:                         "Deployment",
-						apiversion:                   "apps/v1",
-						cpu:                          true,
-						cpuValue:                     parseResourceValue(t, "2.55"),
-						behavior:                     true,
-						scaleDownStabilizationWindow: 300,
-						scaleUpStabilizationWindow:   0,
-					},
-					{
-						name:                         getObjName(options, "-batch-hpa"),
-						targetRefName:                getObjName(options, "-batch"),
-						kind:                         "Deployment",
-						apiversion:                   "apps/v1",
-						cpu:                          true,
-						cpuValue:                     parseResourceValue(t, "2.55"),
-						behavior:                     true,
-						scaleDownStabilizationWindow: 200,
-					},
-				})
-			}
-		}
-	}
+	pegaDeploymentMatrix.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(nil)
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-hpa.yaml"}, "--values", testsPath+"/data/values_hpa_behavior.yaml")
+		verifyPegaHPAs(t, yamlContent, options, []hpa{
+			{
+				name:                         getObjName(options, "-web-hpa"),
+				targetRefName:                getObjName(options, "-web"),
+				kind:                         "Deployment",
+				apiversion:                   "apps/v1",
+				cpu:                          true,
+				cpuValue:                     parseResourceValue(t, "2.55"),
+				behavior:                     true,
+				scaleDownStabilizationWindow: 300,
+				scaleUpStabilizationWindow:   0,
+			},
+			{
+				name:                         getObjName(options, "-batch-hpa"),
+				targetRefName:                getObjName(options, "-batch"),
+				kind:                         "Deployment",
+				apiversion:                   "apps/v1",
+				cpu:                          true,
+				cpuValue:                     parseResourceValue(t, "2.55"),
+				behavior:                     true,
+				scaleDownStabilizationWindow: 200,
+			},
+		})
+	})
 }
 
 // verifyPegaHPAs - Splits the HPA object from the rendered template and asserts each HPA object
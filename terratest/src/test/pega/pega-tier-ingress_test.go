@@ -3,6 +3,7 @@ package pega
 import (
 	"fmt"
 	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/networking/v1"
 	"path/filepath"
@@ -11,35 +12,21 @@ import (
 )
 
 func TestPegaTierIngress(t *testing.T) {
-	var supportedOperations = []string{"deploy", "install-deploy", "upgrade-deploy"}
-	var supportedVendors = []string{"k8s", "eks", "gke", "aks", "pks"}
-	var deploymentNames = []string{"pega", "myapp-dev"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-
-		for _, operation := range supportedOperations {
-
-			for _, depName := range deploymentNames {
-
-				fmt.Println(vendor + "-" + operation)
-
-				var options = &helm.Options{
-					SetValues: map[string]string{
-						"global.deployment.name":        depName,
-						"global.provider":               vendor,
-						"global.actions.execute":        operation,
-						"installer.upgrade.upgradeType": "zero-downtime",
-					},
-				}
-
-				yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-ingress.yaml"})
-				VerifyPegaIngresses(t, yamlContent, options)
-			}
-		}
-	}
+	helmtest.Matrix{
+		Providers:       helmtest.SupportedProviders,
+		Actions:         helmtest.SupportedDeployActions,
+		DeploymentNames: []string{"pega", "myapp-dev"},
+		// openshift renders a Route instead of an Ingress
+		Exclude: []helmtest.Combination{{Provider: "openshift"}},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(nil)
+
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-ingress.yaml"})
+		VerifyPegaIngresses(t, yamlContent, options)
+	})
 
 }
 
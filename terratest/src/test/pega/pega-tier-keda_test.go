@@ -0,0 +1,76 @@
+package pega
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+	"github.com/stretchr/testify/require"
+	autoscaling "k8s.io/api/autoscaling/v2beta2"
+)
+
+// TestPegaTierKEDA - tiers with autoscaling.mode keda get a ScaledObject targeting their workload in place of the HPA,
+// while the other tiers keep theirs
+func TestPegaTierKEDA(t *testing.T) {
+	testsPath, err := filepath.Abs(PegaHelmChartTestsPath)
+	require.NoError(t, err)
+
+	pegaDeploymentMatrix.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := pegaOptions(combination, nil)
+		helmTest := newPegaChartTest(t, options, testsPath+"/data/values_keda.yaml")
+		parser := helmtest.NewHelmConfigParser(helmTest)
+
+		hpas := helmtest.QueryAs[autoscaling.HorizontalPodAutoscaler](parser, helmtest.ResourceQuery{Kind: "HorizontalPodAutoscaler"})
+		require.Len(t, hpas, 1)
+		require.Equal(t, getObjName(options, "-web-hpa"), hpas[0].Name)
+
+		scaledObjects := helmtest.QueryAs[helmtest.ScaledObject](parser, helmtest.ResourceQuery{Kind: "ScaledObject"})
+		require.Len(t, scaledObjects, 2)
+		for _, scaled := range scaledObjects {
+			target := scaled.Spec.ScaleTargetRef
+			workloads := helmtest.QueryAs[tierWorkload](parser, helmtest.ResourceQuery{Kind: target.Kind, Name: target.Name})
+			require.Len(t, workloads, 1, "ScaledObject %s targets no rendered %s %s", scaled.Metadata.Name, target.Kind, target.Name)
+			require.Equal(t, "apps/v1", target.APIVersion)
+		}
+
+		batch := helmtest.QueryAs[helmtest.ScaledObject](parser, helmtest.ResourceQuery{Kind: "ScaledObject", Name: getObjName(options, "-batch")})[0]
+		require.Equal(t, "Deployment", batch.Spec.ScaleTargetRef.Kind)
+		require.Equal(t, getObjName(options, "-batch"), batch.Spec.ScaleTargetRef.Name)
+		require.Equal(t, map[string]string{"team": "batch"}, batch.Metadata.Labels)
+		require.Equal(t, int32(0), *batch.Spec.MinReplicaCount)
+		require.Equal(t, int32(8), *batch.Spec.MaxReplicaCount)
+		require.Equal(t, int32(15), *batch.Spec.PollingInterval)
+		require.Equal(t, int32(600), *batch.Spec.CooldownPeriod)
+		require.Equal(t, int32(3), batch.Spec.Fallback.FailureThreshold)
+		require.Equal(t, int32(2), batch.Spec.Fallback.Replicas)
+		require.Equal(t, int32(600), *batch.Spec.Advanced.HorizontalPodAutoscalerConfig.Behavior.ScaleDown.StabilizationWindowSeconds)
+		require.Len(t, batch.Spec.Triggers, 1)
+		require.Equal(t, "prometheus", batch.Spec.Triggers[0].Type)
+		require.Equal(t, `sum(pega_queue_ready_items{queue="batch"})`, batch.Spec.Triggers[0].Metadata["query"])
+		require.Equal(t, "100", batch.Spec.Triggers[0].Metadata["threshold"])
+
+		stream := helmtest.QueryAs[helmtest.ScaledObject](parser, helmtest.ResourceQuery{Kind: "ScaledObject", Name: getObjName(options, "-stream")})[0]
+		require.Equal(t, "StatefulSet", stream.Spec.ScaleTargetRef.Kind)
+		require.Equal(t, getObjName(options, "-stream"), stream.Spec.ScaleTargetRef.Name)
+		require.Empty(t, stream.Metadata.Labels)
+		require.Equal(t, int32(1), *stream.Spec.MinReplicaCount)
+		require.Equal(t, int32(5), *stream.Spec.MaxReplicaCount)
+		require.Nil(t, stream.Spec.PollingInterval)
+		require.Nil(t, stream.Spec.CooldownPeriod)
+		require.Nil(t, stream.Spec.Fallback)
+		require.Nil(t, stream.Spec.Advanced)
+		require.Len(t, stream.Spec.Triggers, 1)
+		require.Equal(t, "kafka", stream.Spec.Triggers[0].Type)
+		require.Equal(t, "50", stream.Spec.Triggers[0].Metadata["lagThreshold"])
+		require.Equal(t, "kafka-credentials", stream.Spec.Triggers[0].AuthenticationRef.Name)
+	})
+}
+
+// TestPegaTierKEDADisabled - the example values.yaml scales with HPAs only
+func TestPegaTierKEDADisabled(t *testing.T) {
+	helmTest := newPegaChartTest(t, pegaOptions(k8sDeploy, nil))
+	parser := helmtest.NewHelmConfigParser(helmTest)
+
+	require.Empty(t, parser.Query(helmtest.ResourceQuery{Kind: "ScaledObject"}))
+	require.Len(t, parser.Query(helmtest.ResourceQuery{Kind: "HorizontalPodAutoscaler"}), 2)
+}
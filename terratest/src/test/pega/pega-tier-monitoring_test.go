@@ -0,0 +1,169 @@
+package pega
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+	"github.com/stretchr/testify/require"
+	k8score "k8s.io/api/core/v1"
+	"k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/yaml"
+)
+
+// monitoredTier - what data/values_monitoring.yaml configures for a tier
+type monitoredTier struct {
+	name           string
+	port           int32
+	path           string
+	interval       string
+	serviceMonitor bool
+}
+
+var monitoredTiers = []monitoredTier{
+	{name: "web", port: 9100, path: "/prometheus", interval: "15s", serviceMonitor: true},
+	{name: "batch", port: 9404, path: "/metrics", interval: "30s", serviceMonitor: false},
+	{name: "stream", port: 9404, path: "/metrics", interval: "30s", serviceMonitor: true},
+}
+
+// tierWorkload - the part of a Deployment or StatefulSet shared by both kinds
+type tierWorkload struct {
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Spec struct {
+		Template k8score.PodTemplateSpec `json:"template"`
+	} `json:"spec"`
+}
+
+// tierPodTemplate - the pod template of the Deployment or StatefulSet running the tier
+func tierPodTemplate(t *testing.T, parser *helmtest.HelmChartParser, name string) k8score.PodTemplateSpec {
+	var workloads []tierWorkload
+	for _, kind := range []string{"Deployment", "StatefulSet"} {
+		workloads = append(workloads, helmtest.QueryAs[tierWorkload](parser, helmtest.ResourceQuery{Kind: kind, Name: name})...)
+	}
+	require.Len(t, workloads, 1, "workload %s", name)
+	return workloads[0].Spec.Template
+}
+
+// requireSelectsTier - the selector picks the pods of the tier and of no other tier
+func requireSelectsTier(t *testing.T, pods map[string]labels.Set, tier string, selector map[string]string, what string) {
+	require.NotEmpty(t, selector, "%s has no selector", what)
+	for name, podLabels := range pods {
+		matches := labels.SelectorFromSet(selector).Matches(podLabels)
+		if name == tier {
+			require.True(t, matches, "%s selector %v does not match the %s pods %v", what, selector, tier, podLabels)
+		} else {
+			require.False(t, matches, "%s selector %v also matches the %s pods %v", what, selector, name, podLabels)
+		}
+	}
+}
+
+// TestPegaTierMonitoring - every monitored tier exposes a metrics port, a metrics Service selecting its pods and,
+// unless disabled, a ServiceMonitor selecting only that metrics Service
+func TestPegaTierMonitoring(t *testing.T) {
+	testsPath, err := filepath.Abs(PegaHelmChartTestsPath)
+	require.NoError(t, err)
+
+	pegaDeploymentMatrix.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := pegaOptions(combination, nil)
+		helmTest := newPegaChartTest(t, options, testsPath+"/data/values_monitoring.yaml")
+		parser := helmtest.NewHelmConfigParser(helmTest)
+
+		pods := map[string]labels.Set{}
+		for _, tier := range monitoredTiers {
+			pods[tier.name] = tierPodTemplate(t, parser, getObjName(options, "-"+tier.name)).Labels
+		}
+
+		for _, tier := range monitoredTiers {
+			name := getObjName(options, "-"+tier.name)
+			pod := tierPodTemplate(t, parser, name)
+
+			var metricsPort *k8score.ContainerPort
+			for i, port := range pod.Spec.Containers[0].Ports {
+				if port.Name == "pega-metrics" {
+					metricsPort = &pod.Spec.Containers[0].Ports[i]
+				}
+			}
+			require.NotNil(t, metricsPort, "%s has no pega-metrics container port", name)
+			require.Equal(t, tier.port, metricsPort.ContainerPort)
+
+			var metricsService k8score.Service
+			parser.Find(helmtest.SearchResourceOption{Name: name + "-metrics", Kind: "Service"}, &metricsService)
+			require.Equal(t, k8score.ServiceTypeClusterIP, metricsService.Spec.Type)
+			require.Len(t, metricsService.Spec.Ports, 1)
+			require.Equal(t, "metrics", metricsService.Spec.Ports[0].Name)
+			require.Equal(t, tier.port, metricsService.Spec.Ports[0].Port)
+			require.Equal(t, intstr.FromString("pega-metrics"), metricsService.Spec.Ports[0].TargetPort)
+			requireSelectsTier(t, pods, tier.name, metricsService.Spec.Selector, "Service "+metricsService.Name)
+
+			var tierServiceLabels labels.Set
+			for _, service := range helmtest.QueryAs[k8score.Service](parser, helmtest.ResourceQuery{Kind: "Service", Name: name}) {
+				requireSelectsTier(t, pods, tier.name, service.Spec.Selector, "Service "+service.Name)
+				tierServiceLabels = service.Labels
+			}
+			for _, pdb := range helmtest.QueryAs[v1beta1.PodDisruptionBudget](parser, helmtest.ResourceQuery{Kind: "PodDisruptionBudget", Name: name + "-pdb"}) {
+				requireSelectsTier(t, pods, tier.name, pdb.Spec.Selector.MatchLabels, "PodDisruptionBudget "+pdb.Name)
+			}
+
+			monitors := helmtest.QueryAs[helmtest.ServiceMonitor](parser, helmtest.ResourceQuery{Kind: "ServiceMonitor", Name: name})
+			if !tier.serviceMonitor {
+				require.Empty(t, monitors)
+				continue
+			}
+			require.Len(t, monitors, 1)
+			monitor := monitors[0]
+			selector := labels.SelectorFromSet(monitor.Spec.Selector.MatchLabels)
+			require.True(t, selector.Matches(labels.Set(metricsService.Labels)), "ServiceMonitor %s does not select Service %s", name, metricsService.Name)
+			require.False(t, selector.Matches(tierServiceLabels), "ServiceMonitor %s also selects the tier Service", name)
+			require.Equal(t, []string{"default"}, monitor.Spec.NamespaceSelector.MatchNames)
+			require.Len(t, monitor.Spec.Endpoints, 1)
+			require.Equal(t, metricsService.Spec.Ports[0].Name, monitor.Spec.Endpoints[0].Port)
+			require.Equal(t, tier.path, monitor.Spec.Endpoints[0].Path)
+			require.Equal(t, tier.interval, monitor.Spec.Endpoints[0].Interval)
+		}
+
+		web := helmtest.QueryAs[helmtest.ServiceMonitor](parser, helmtest.ResourceQuery{Kind: "ServiceMonitor", Name: getObjName(options, "-web")})[0]
+		require.Equal(t, "prometheus", web.Metadata.Labels["release"])
+		require.Equal(t, "10s", web.Spec.Endpoints[0].ScrapeTimeout)
+		require.Equal(t, []map[string]interface{}{{"sourceLabels": []interface{}{"__meta_kubernetes_pod_node_name"}, "targetLabel": "node"}}, web.Spec.Endpoints[0].Relabelings)
+		require.Equal(t, []map[string]interface{}{{"sourceLabels": []interface{}{"__name__"}, "regex": "jvm_gc_.*", "action": "drop"}}, web.Spec.Endpoints[0].MetricRelabelings)
+	})
+}
+
+// TestPegaTierServiceMonitorLabels - labels of the values that the chart also sets are rendered once, with the value
+// of the chart, which the metrics Service selection relies on
+func TestPegaTierServiceMonitorLabels(t *testing.T) {
+	testsPath, err := filepath.Abs(PegaHelmChartTestsPath)
+	require.NoError(t, err)
+
+	options := pegaOptions(k8sDeploy, map[string]string{
+		"global.tier[0].monitoring.serviceMonitor.labels.app":       "prometheus",
+		"global.tier[0].monitoring.serviceMonitor.labels.component": "metrics",
+	})
+	helmTest := newPegaChartTest(t, options, testsPath+"/data/values_monitoring.yaml")
+	parser := helmtest.NewHelmConfigParser(helmTest)
+
+	resource := parser.QueryOne(helmtest.ResourceQuery{Kind: "ServiceMonitor", Name: "pega-web"})
+	_, err = yaml.YAMLToJSONStrict([]byte(resource.YAML))
+	require.NoError(t, err, "ServiceMonitor has duplicate keys")
+	var monitor helmtest.ServiceMonitor
+	require.NoError(t, yaml.Unmarshal([]byte(resource.YAML), &monitor))
+	require.Equal(t, map[string]string{"release": "prometheus", "app": "pega-web", "component": "Pega"}, monitor.Metadata.Labels)
+}
+
+// TestPegaTierMonitoringDisabled - the example values.yaml leaves monitoring off, so no metrics port or object is rendered
+func TestPegaTierMonitoringDisabled(t *testing.T) {
+	helmTest := newPegaChartTest(t, pegaOptions(k8sDeploy, nil))
+	parser := helmtest.NewHelmConfigParser(helmTest)
+
+	require.Empty(t, parser.Query(helmtest.ResourceQuery{Kind: "ServiceMonitor"}))
+	require.Empty(t, parser.Query(helmtest.ResourceQuery{Kind: "Service", Name: "*-metrics"}))
+	for _, tier := range []string{"web", "batch", "stream"} {
+		for _, port := range tierPodTemplate(t, parser, "pega-"+tier).Spec.Containers[0].Ports {
+			require.NotEqual(t, "pega-metrics", port.Name)
+		}
+	}
+}
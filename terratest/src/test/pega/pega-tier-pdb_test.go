@@ -1,12 +1,12 @@
 package pega
 
 import (
-	"fmt"
 	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	"k8s.io/api/policy/v1beta1"
 )
@@ -17,63 +17,39 @@ import (
 
 // TestPegaTierPDBEnabled - verify that a PodDisruptionBudget is created when global.tier.pdb.enabled=true
 func TestPegaTierPDBEnabled(t *testing.T) {
-	var supportedVendors = []string{"k8s", "openshift", "eks", "gke", "aks", "pks"}
-	var supportedOperations = []string{"deploy", "install-deploy"}
-	var deploymentNames = []string{"pega", "myapp-dev"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
 	testsPath, err := filepath.Abs(PegaHelmChartTestsPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-
-		for _, operation := range supportedOperations {
-
-			for _, depName := range deploymentNames {
-
-				fmt.Println(vendor + "-" + operation)
-
-				var options = &helm.Options{
-					SetValues: map[string]string{
-						"global.deployment.name": depName,
-						"global.provider":        vendor,
-						"global.actions.execute": operation,
-					},
-				}
-
-				yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-pdb.yaml"}, "--values", testsPath+"/data/values_pdb_enabled.yaml")
-				verifyPegaPDBs(t, yamlContent, options, []pdb{
-					{
-						name:         getObjName(options, "-web-pdb"),
-						kind:         "PodDisruptionBudget",
-						apiversion:   "policy/v1",
-						minAvailable: 1,
-					},
-					{
-						name:         getObjName(options, "-batch-pdb"),
-						kind:         "PodDisruptionBudget",
-						apiversion:   "policy/v1",
-						minAvailable: 1,
-					},
-					{
-						name:         getObjName(options, "-stream-pdb"),
-						kind:         "PodDisruptionBudget",
-						apiversion:   "policy/v1",
-						minAvailable: 1,
-					},
-				})
-			}
-		}
-	}
+	pegaDeploymentMatrix.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(nil)
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-pdb.yaml"}, "--values", testsPath+"/data/values_pdb_enabled.yaml")
+		verifyPegaPDBs(t, yamlContent, options, []pdb{
+			{
+				name:         getObjName(options, "-web-pdb"),
+				kind:         "PodDisruptionBudget",
+				apiversion:   "policy/v1",
+				minAvailable: 1,
+			},
+			{
+				name:         getObjName(options, "-batch-pdb"),
+				kind:         "PodDisruptionBudget",
+				apiversion:   "policy/v1",
+				minAvailable: 1,
+			},
+			{
+				name:         getObjName(options, "-stream-pdb"),
+				kind:         "PodDisruptionBudget",
+				apiversion:   "policy/v1",
+				minAvailable: 1,
+			},
+		})
+	})
 }
 
 func TestPegaTierPDBWithCustomLabels(t *testing.T) {
-	var supportedVendors = []string{"k8s", "openshift", "eks", "gke", "aks", "pks"}
-	var supportedOperations = []string{"deploy", "install-deploy"}
-	var deploymentNames = []string{"pega", "myapp-dev"}
-
 	webPDBLabels := map[string]string{"weblabel": "somevalue", "anotherlabel": "anothervalue"}
 	batchPDBLabels := map[string]string{"batchlabel": "batchvalue", "anotherbatchlabel": "batchvalue2"}
 
@@ -83,83 +59,47 @@ func TestPegaTierPDBWithCustomLabels(t *testing.T) {
 	testsPath, err := filepath.Abs(PegaHelmChartTestsPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-
-		for _, operation := range supportedOperations {
-
-			for _, depName := range deploymentNames {
-
-				fmt.Println(vendor + "-" + operation)
-
-				var options = &helm.Options{
-					SetValues: map[string]string{
-						"global.deployment.name": depName,
-						"global.provider":        vendor,
-						"global.actions.execute": operation,
-					},
-				}
-
-				yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-pdb.yaml"}, "--values", testsPath+"/data/values_pdb_custom_labels.yaml")
-				verifyPegaPDBs(t, yamlContent, options, []pdb{
-					{
-						name:         getObjName(options, "-web-pdb"),
-						kind:         "PodDisruptionBudget",
-						apiversion:   "policy/v1",
-						labels:       webPDBLabels,
-						minAvailable: 1,
-					},
-					{
-						name:         getObjName(options, "-batch-pdb"),
-						kind:         "PodDisruptionBudget",
-						apiversion:   "policy/v1",
-						labels:       batchPDBLabels,
-						minAvailable: 1,
-					},
-					{
-						name:         getObjName(options, "-stream-pdb"),
-						kind:         "PodDisruptionBudget",
-						apiversion:   "policy/v1",
-						minAvailable: 1,
-					},
-				})
-			}
-		}
-	}
+	pegaDeploymentMatrix.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(nil)
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-pdb.yaml"}, "--values", testsPath+"/data/values_pdb_custom_labels.yaml")
+		verifyPegaPDBs(t, yamlContent, options, []pdb{
+			{
+				name:         getObjName(options, "-web-pdb"),
+				kind:         "PodDisruptionBudget",
+				apiversion:   "policy/v1",
+				labels:       webPDBLabels,
+				minAvailable: 1,
+			},
+			{
+				name:         getObjName(options, "-batch-pdb"),
+				kind:         "PodDisruptionBudget",
+				apiversion:   "policy/v1",
+				labels:       batchPDBLabels,
+				minAvailable: 1,
+			},
+			{
+				name:         getObjName(options, "-stream-pdb"),
+				kind:         "PodDisruptionBudget",
+				apiversion:   "policy/v1",
+				minAvailable: 1,
+			},
+		})
+	})
 }
 
 // TestPegaTierPDBDisabled - verify that a PodDisruptionBudget is not created when global.tier.pdb.enabled=false
 func TestPegaTierPDBDisabled(t *testing.T) {
-	var supportedVendors = []string{"k8s", "openshift", "eks", "gke", "aks", "pks"}
-	var supportedOperations = []string{"deploy", "install-deploy"}
-	var deploymentNames = []string{"pega", "myapp-dev"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
 	testsPath, err := filepath.Abs(PegaHelmChartTestsPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-
-		for _, operation := range supportedOperations {
-
-			for _, depName := range deploymentNames {
-
-				fmt.Println(vendor + "-" + operation)
-
-				var options = &helm.Options{
-					SetValues: map[string]string{
-						"global.deployment.name": depName,
-						"global.provider":        vendor,
-						"global.actions.execute": operation,
-					},
-				}
-
-				_, err := RenderTemplateWithErr(t, options, helmChartPath, []string{"templates/pega-tier-pdb.yaml"}, "--values", testsPath+"/data/values_pdb_disabled.yaml")
-				require.NotNil(t, err)
-			}
-		}
-	}
+	pegaDeploymentMatrix.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(nil)
+		_, err := RenderTemplateWithErr(t, options, helmChartPath, []string{"templates/pega-tier-pdb.yaml"}, "--values", testsPath+"/data/values_pdb_disabled.yaml")
+		require.NotNil(t, err)
+	})
 }
 
 // verifyPegaPDBs - Splits the PDB object from the rendered template and asserts each PDB object
@@ -0,0 +1,163 @@
+package pega
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+	"github.com/stretchr/testify/require"
+)
+
+// requireValuesSchemaError - asserts that helm rejected the values because of the values.schema.json rule on field,
+// given in dotted form such as global.tier.0.name
+func requireValuesSchemaError(t *testing.T, err error, field string) {
+	require.Error(t, err)
+	require.Regexp(t, helmtest.SchemaError(field), err.Error())
+}
+
+// TestPegaValuesSchemaAcceptsExampleValues - the example values files shipped with the chart and the values files
+// used by the other tests satisfy values.schema.json
+func TestPegaValuesSchemaAcceptsExampleValues(t *testing.T) {
+	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
+	require.NoError(t, err)
+	testsPath, err := filepath.Abs(PegaHelmChartTestsPath)
+	require.NoError(t, err)
+
+	valuesFiles := []string{
+		helmChartPath + "/values.yaml",
+		helmChartPath + "/values-large.yaml",
+		helmChartPath + "/values-minimal.yaml",
+		helmChartPath + "/Ephemeral-web-tier-values.yaml",
+		testsPath + "/data/values_affinity.yaml",
+		testsPath + "/data/values_gateway.yaml",
+		testsPath + "/data/values_keda.yaml",
+		testsPath + "/data/values_kube_versions.yaml",
+		testsPath + "/data/values_monitoring.yaml",
+		testsPath + "/data/values_schema_validation.yaml",
+		testsPath + "/data/values_vpa.yaml",
+		testsPath + "/data/values_with_overidden_liveness_probe_config.yaml",
+	}
+	for _, valuesFile := range valuesFiles {
+		valuesFile := valuesFile
+		t.Run(filepath.Base(valuesFile), func(t *testing.T) {
+			t.Parallel()
+			var options = &helm.Options{
+				ValuesFiles: []string{valuesFile},
+				SetValues: map[string]string{
+					"global.provider":   "k8s",
+					"cassandra.enabled": "false",
+				},
+			}
+			_, err := RenderTemplateE(t, options, helmChartPath, []string{"templates/pega-environment-config.yaml"})
+			require.NoError(t, err)
+		})
+	}
+}
+
+// TestPegaValuesSchemaAcceptsLooseTypes - values the chart rendered before it had a schema still pass: numbers and
+// booleans written as strings, text settings that helm parses as numbers or booleans, and tiers without a name
+func TestPegaValuesSchemaAcceptsLooseTypes(t *testing.T) {
+	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
+	require.NoError(t, err)
+
+	var options = &helm.Options{
+		SetValues: map[string]string{
+			"global.provider":                       "k8s",
+			"global.actions.execute":                "deploy",
+			"global.jdbc.url":                       "true",
+			"global.tier[0].nodeType":               "WebUser",
+			"global.tier[1].name":                   "batch",
+			"global.tier[1].nodeType":               "1234",
+			"installer.productionLevel":             "2",
+			"hazelcast.migration.initiateMigration": "false",
+		},
+		SetStrValues: map[string]string{
+			"global.tier[1].replicas":                    "2",
+			"global.tier[1].service.port":                "80",
+			"global.tier[1].hpa.enabled":                 "false",
+			"global.tier[1].hpa.maxReplicas":             "5",
+			"global.tier[1].livenessProbe.periodSeconds": "30",
+			"installer.upgrade.dbLoadCommitRate":         "1000",
+		},
+	}
+	_, err = RenderTemplateE(t, options, helmChartPath, []string{"templates/pega-environment-config.yaml"})
+	require.NoError(t, err)
+}
+
+// TestPegaValuesSchemaRejectsInvalidValuesFile - every mistake in data/values_schema_invalid.yaml is reported in a
+// single helm run
+func TestPegaValuesSchemaRejectsInvalidValuesFile(t *testing.T) {
+	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
+	require.NoError(t, err)
+
+	var options = &helm.Options{
+		ValuesFiles: []string{"data/values_schema_invalid.yaml"},
+	}
+	_, err = RenderTemplateE(t, options, helmChartPath, []string{"templates/pega-environment-config.yaml"})
+	for _, field := range []string{
+		"global.jdbc.url",
+		"global.tier.0.replicas",
+		"global.tier.0.service.targetPort",
+		"global.tier.0.deploymentStrategy.type",
+		"global.tier.0.hpa.enabled",
+		"global.tier.0.livenessProbe.periodSeconds",
+		"global.tier.0.monitoring.port",
+		"global.tier.0.monitoring.serviceMonitor.interval",
+		"global.tier.0.podAntiAffinityPreset",
+		"global.tier.0.autoscaling.mode",
+		"global.tier.0.vpa.updateMode",
+		"global.tier.0.ingress.type",
+		"dds.clientEncryption",
+		"stream.securityProtocol",
+		"pegasearch.srsAuth.authType",
+		"hazelcast.replicas",
+		"installer.upgrade.dbLoadCommitRate",
+	} {
+		requireValuesSchemaError(t, err, field)
+	}
+}
+
+// TestPegaValuesSchemaRejectsInvalidSettings - single invalid settings passed with --set are rejected before any
+// template is rendered
+func TestPegaValuesSchemaRejectsInvalidSettings(t *testing.T) {
+	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
+	require.NoError(t, err)
+
+	var cases = []struct {
+		field     string
+		setValues map[string]string
+	}{
+		{"global.provider", map[string]string{"global.provider": "azure"}},
+		{"global.actions.execute", map[string]string{"global.actions.execute": "redeploy"}},
+		{"global.tier.0.replicas", map[string]string{"global.tier[0].name": "web", "global.tier[0].replicas": "-1"}},
+		{"global.tier.0.nodeType", map[string]string{"global.tier[0].name": "web", "global.tier[0].nodeType": ""}},
+		{"global.tier.0.pdb.minAvailable", map[string]string{"global.tier[0].name": "web", "global.tier[0].pdb.minAvailable": "true"}},
+		{"global.tier.0.ingress.pathType", map[string]string{"global.tier[0].name": "web", "global.tier[0].ingress.pathType": "Regex"}},
+		{"global.tier.0.ingress.gateway.timeouts.request", map[string]string{"global.tier[0].name": "web", "global.tier[0].ingress.gateway.timeouts.request": "2 minutes"}},
+		{"global.tier.0.service.serviceType", map[string]string{"global.tier[0].name": "web", "global.tier[0].service.serviceType": "Ingress"}},
+		{"global.jdbc.driverClass", map[string]string{"global.jdbc.driverClass": ""}},
+		{"dds.port", map[string]string{"dds.port": "true"}},
+		{"stream.saslMechanism", map[string]string{"stream.saslMechanism": "OAUTHBEARER"}},
+		{"pegasearch.replicas", map[string]string{"pegasearch.replicas": "one"}},
+		{"pegasearch.srsAuth.privateKeyAlgorithm", map[string]string{"pegasearch.srsAuth.privateKeyAlgorithm": "HS256"}},
+		{"hazelcast.enabled", map[string]string{"hazelcast.enabled": "yes"}},
+		{"hazelcast.podAntiAffinityPreset", map[string]string{"hazelcast.podAntiAffinityPreset": "always"}},
+		{"installer.productionLevel", map[string]string{"installer.productionLevel": "6"}},
+	}
+	for _, testCase := range cases {
+		testCase := testCase
+		t.Run(testCase.field, func(t *testing.T) {
+			t.Parallel()
+			setValues := map[string]string{
+				"global.provider":        "k8s",
+				"global.actions.execute": "deploy",
+			}
+			for key, value := range testCase.setValues {
+				setValues[key] = value
+			}
+			_, err := RenderTemplateE(t, &helm.Options{SetValues: setValues}, helmChartPath, []string{"templates/pega-environment-config.yaml"})
+			requireValuesSchemaError(t, err, testCase.field)
+		})
+	}
+}
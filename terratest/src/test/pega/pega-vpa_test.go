@@ -0,0 +1,100 @@
+package pega
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+	"github.com/stretchr/testify/require"
+	k8score "k8s.io/api/core/v1"
+)
+
+// expectedVPA - what data/values_vpa.yaml configures for a workload
+type expectedVPA struct {
+	target              string
+	kind                string
+	container           string
+	updateMode          string
+	controlledResources []string
+	minAllowed          k8score.ResourceList
+	maxAllowed          k8score.ResourceList
+}
+
+// TestPegaVPA - tiers, search and Hazelcast get a VerticalPodAutoscaler targeting their workload and sizing only its
+// main container
+func TestPegaVPA(t *testing.T) {
+	testsPath, err := filepath.Abs(PegaHelmChartTestsPath)
+	require.NoError(t, err)
+
+	pegaDeploymentMatrix.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := pegaOptions(combination, nil)
+		helmTest := newPegaChartTest(t, options, testsPath+"/data/values_vpa.yaml")
+		parser := helmtest.NewHelmConfigParser(helmTest)
+
+		expected := []expectedVPA{
+			{
+				target: getObjName(options, "-web"), kind: "Deployment", container: "pega-web-tomcat",
+				updateMode: "Auto", controlledResources: []string{"memory"},
+				minAllowed: k8score.ResourceList{k8score.ResourceMemory: parseResourceValue(t, "6Gi")},
+				maxAllowed: k8score.ResourceList{k8score.ResourceMemory: parseResourceValue(t, "12Gi")},
+			},
+			{
+				target: getObjName(options, "-batch"), kind: "Deployment", container: "pega-web-tomcat",
+				updateMode: "Recreate", controlledResources: []string{"cpu", "memory"},
+				minAllowed: k8score.ResourceList{k8score.ResourceCPU: parseResourceValue(t, "1"), k8score.ResourceMemory: parseResourceValue(t, "4Gi")},
+				maxAllowed: k8score.ResourceList{k8score.ResourceCPU: parseResourceValue(t, "4"), k8score.ResourceMemory: parseResourceValue(t, "16Gi")},
+			},
+			{target: getObjName(options, "-stream"), kind: "StatefulSet", container: "pega-web-tomcat", updateMode: "Off", controlledResources: []string{"cpu", "memory"}},
+			{target: getObjName(options, "-search"), kind: "StatefulSet", container: "search", updateMode: "Initial", controlledResources: []string{"cpu", "memory"}},
+			{target: "pega-hazelcast", kind: "StatefulSet", container: "hazelcast", updateMode: "Off", controlledResources: []string{"cpu", "memory"}},
+			{target: "clusteringservice", kind: "StatefulSet", container: "hazelcast", updateMode: "Off", controlledResources: []string{"cpu", "memory"}},
+		}
+		require.Len(t, parser.Query(helmtest.ResourceQuery{Kind: "VerticalPodAutoscaler"}), len(expected))
+
+		for _, want := range expected {
+			vpas := helmtest.QueryAs[helmtest.VerticalPodAutoscaler](parser, helmtest.ResourceQuery{Kind: "VerticalPodAutoscaler", Name: want.target + "-vpa"})
+			require.Len(t, vpas, 1, "VerticalPodAutoscaler %s-vpa", want.target)
+			vpa := vpas[0]
+
+			target := vpa.Spec.TargetRef
+			require.Equal(t, "apps/v1", target.APIVersion)
+			require.Equal(t, want.kind, target.Kind)
+			require.Equal(t, want.target, target.Name)
+			workloads := helmtest.QueryAs[tierWorkload](parser, helmtest.ResourceQuery{Kind: target.Kind, Name: target.Name})
+			require.Len(t, workloads, 1, "VerticalPodAutoscaler %s targets no rendered %s %s", vpa.Metadata.Name, target.Kind, target.Name)
+			var containers []string
+			for _, container := range workloads[0].Spec.Template.Spec.Containers {
+				containers = append(containers, container.Name)
+			}
+			require.Contains(t, containers, want.container)
+
+			require.Equal(t, want.updateMode, vpa.Spec.UpdatePolicy.UpdateMode)
+			policies := vpa.Spec.ResourcePolicy.ContainerPolicies
+			require.Len(t, policies, 2)
+			require.Equal(t, want.container, policies[0].ContainerName)
+			require.Empty(t, policies[0].Mode)
+			require.Equal(t, want.controlledResources, policies[0].ControlledResources)
+			require.Equal(t, len(want.minAllowed), len(policies[0].MinAllowed))
+			for resource, quantity := range want.minAllowed {
+				require.True(t, quantity.Equal(policies[0].MinAllowed[resource]), "%s minAllowed %s", vpa.Metadata.Name, resource)
+			}
+			require.Equal(t, len(want.maxAllowed), len(policies[0].MaxAllowed))
+			for resource, quantity := range want.maxAllowed {
+				require.True(t, quantity.Equal(policies[0].MaxAllowed[resource]), "%s maxAllowed %s", vpa.Metadata.Name, resource)
+			}
+			require.Equal(t, "*", policies[1].ContainerName)
+			require.Equal(t, "Off", policies[1].Mode)
+		}
+	})
+}
+
+// TestPegaVPADisabled - the example values.yaml renders no VerticalPodAutoscaler
+func TestPegaVPADisabled(t *testing.T) {
+	options := pegaOptions(k8sDeploy, map[string]string{
+		"hazelcast.clusteringServiceEnabled": "true",
+	})
+	helmTest := newPegaChartTest(t, options)
+	parser := helmtest.NewHelmConfigParser(helmTest)
+
+	require.Empty(t, parser.Query(helmtest.ResourceQuery{Kind: "VerticalPodAutoscaler"}))
+}
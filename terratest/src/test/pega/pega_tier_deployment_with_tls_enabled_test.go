@@ -1,8 +1,7 @@
 package pega
 
 import (
-	"fmt"
-	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	"path/filepath"
 	"strings"
@@ -10,43 +9,27 @@ import (
 )
 
 func TestPegaTierDeploymentWithTlsEnabled(t *testing.T) {
-	var supportedVendors = []string{"k8s", "openshift", "eks", "gke", "aks", "pks"}
-	var supportedOperations = []string{"deploy", "install-deploy", "upgrade-deploy"}
-	var deploymentNames = []string{"pega", "myapp-dev"}
-
 	helmChartPath, err := filepath.Abs(PegaHelmChartPath)
 	require.NoError(t, err)
 
-	for _, vendor := range supportedVendors {
-
-		for _, operation := range supportedOperations {
-
-			for _, depName := range deploymentNames {
-
-				fmt.Println(vendor + "-" + operation)
-
-				var options = &helm.Options{
-					SetValues: map[string]string{
-						"global.provider":               vendor,
-						"global.actions.execute":        operation,
-						"global.deployment.name":        depName,
-						"installer.upgrade.upgradeType": "zero-downtime",
-						"global.storageClassName":       "storage-class",
-					},
-					ValuesFiles: []string{"data/pega-tier-service-override_values.yaml"},
-					SetStrValues: map[string]string{
-						"service.tls.enabled": "true",
-					},
-				}
-
-				yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-deployment.yaml"})
-				yamlSplit := strings.Split(yamlContent, "---")
-				assertWeb(t, yamlSplit[1], options)
-				assertBatch(t, yamlSplit[2], options)
-				assertStream(t, yamlSplit[3], options)
-				assertStreamWithSorageClass(t, yamlSplit[3], options)
-
-			}
+	helmtest.Matrix{
+		Providers:       helmtest.SupportedProviders,
+		Actions:         helmtest.SupportedDeployActions,
+		DeploymentNames: []string{"pega", "myapp-dev"},
+		ValuesFiles:     []string{"data/pega-tier-service-override_values.yaml"},
+	}.Run(t, func(t *testing.T, combination helmtest.Combination) {
+		options := combination.HelmOptions(map[string]string{
+			"global.storageClassName": "storage-class",
+		})
+		options.SetStrValues = map[string]string{
+			"service.tls.enabled": "true",
 		}
-	}
+
+		yamlContent := RenderTemplate(t, options, helmChartPath, []string{"templates/pega-tier-deployment.yaml"})
+		yamlSplit := strings.Split(yamlContent, "---")
+		assertWeb(t, yamlSplit[1], options)
+		assertBatch(t, yamlSplit[2], options)
+		assertStream(t, yamlSplit[3], options)
+		assertStreamWithSorageClass(t, yamlSplit[3], options)
+	})
 }
@@ -4,18 +4,52 @@ import (
 	"testing"
 
 	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+	"github.com/stretchr/testify/require"
 )
 
+// newPegaHelmTest describes rendering the pega chart as the PegaHelmRelease release
+func newPegaHelmTest(t *testing.T, options *helm.Options, helmChartPath string, templates []string, extraHelmArgs ...string) *helmtest.HelmTest {
+	return &helmtest.HelmTest{
+		T:             t,
+		ChartPath:     helmChartPath,
+		ReleaseName:   PegaHelmRelease,
+		HelmOptions:   options,
+		Templates:     templates,
+		ExtraHelmArgs: extraHelmArgs,
+	}
+}
+
+// k8sDeploy - the k8s provider and deploy action, for tests that do not depend on either
+var k8sDeploy = helmtest.Combination{Provider: "k8s", Action: "deploy"}
+
+// pegaOptions - helm options for combination with setValues and the Cassandra subchart disabled, for tests that render
+// the whole chart
+func pegaOptions(combination helmtest.Combination, setValues map[string]string) *helm.Options {
+	values := map[string]string{"cassandra.enabled": "false"}
+	for key, value := range setValues {
+		values[key] = value
+	}
+	return combination.HelmOptions(values)
+}
+
+// newPegaChartTest describes rendering the pega chart from the repository with options and valuesFiles
+func newPegaChartTest(t *testing.T, options *helm.Options, valuesFiles ...string) *helmtest.HelmTest {
+	return newPegaHelmTest(t, options, helmtest.ChartPath(helmtest.PegaChart), nil).WithValuesFiles(valuesFiles...)
+}
+
 func RenderTemplate(t *testing.T, options *helm.Options, helmChartPath string, templates []string, extraHelmArgs ...string) string {
-	return helm.RenderTemplate(t, options, helmChartPath, PegaHelmRelease, templates, extraHelmArgs...)
+	yamlContent, err := RenderTemplateWithErr(t, options, helmChartPath, templates, extraHelmArgs...)
+	require.NoError(t, err)
+	return yamlContent
 }
 
 func RenderTemplateWithErr(t *testing.T, options *helm.Options, helmChartPath string, templates []string, extraHelmArgs ...string) (string, error) {
-	return helm.RenderTemplateE(t, options, helmChartPath, PegaHelmRelease, templates, extraHelmArgs...)
+	return newPegaHelmTest(t, options, helmChartPath, templates, extraHelmArgs...).RenderE()
 }
 
 func RenderTemplateE(t *testing.T, options *helm.Options, helmChartPath string, templates []string) (string, error) {
-	return helm.RenderTemplateE(t, options, helmChartPath, PegaHelmRelease, templates)
+	return newPegaHelmTest(t, options, helmChartPath, templates).RenderE()
 }
 
 func UnmarshalK8SYaml(t *testing.T, yamlData string, destinationObj interface{}) {
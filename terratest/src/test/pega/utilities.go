@@ -7,10 +7,18 @@ import (
 	"testing"
 
 	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
 	"github.com/stretchr/testify/require"
 	k8score "k8s.io/api/core/v1"
 )
 
+// pegaDeploymentMatrix - every provider and deploy action, with the default and a custom deployment name
+var pegaDeploymentMatrix = helmtest.Matrix{
+	Providers:       helmtest.SupportedProviders,
+	Actions:         helmtest.SupportedDeployActions,
+	DeploymentNames: []string{"pega", "myapp-dev"},
+}
+
 var volumeDefaultMode int32 = 420
 var volumeDefaultModePtr = &volumeDefaultMode
 
@@ -0,0 +1,111 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+	"github.com/stretchr/testify/require"
+)
+
+// Violation is a rendered resource breaking a policy rule
+type Violation struct {
+	Rule string
+	// Resource identifies the object as Kind/name, followed by the template that rendered it
+	Resource string
+	// Path is the dot separated location of the offending field, e.g. spec.template.spec.initContainers[0].image
+	Path    string
+	Message string
+}
+
+func (v Violation) Error() string {
+	if v.Path == "" {
+		return fmt.Sprintf("%s: [%s] %s", v.Resource, v.Rule, v.Message)
+	}
+	return fmt.Sprintf("%s: %s: [%s] %s", v.Resource, v.Path, v.Rule, v.Message)
+}
+
+// Rule is a single policy check. Rules are run on every rendered resource and ignore the kinds they do not apply to.
+type Rule interface {
+	// Name identifies the rule in violations, e.g. pinned-image-tags
+	Name() string
+	// Check returns the violations of resource, leaving Rule and Resource for the engine to fill in
+	Check(resource helmtest.Resource) []Violation
+}
+
+// ResourceReport lists the violations of one rendered resource
+type ResourceReport struct {
+	Resource   string
+	Violations []Violation
+}
+
+// Engine runs a set of rules over rendered resources
+type Engine struct {
+	Rules []Rule
+}
+
+// NewEngine returns an engine running rules, or DefaultRules when none are given
+func NewEngine(rules ...Rule) *Engine {
+	if len(rules) == 0 {
+		rules = DefaultRules()
+	}
+	return &Engine{Rules: rules}
+}
+
+// Evaluate runs every rule on every resource and reports the resources with violations, in rendering order
+func (e *Engine) Evaluate(resources []helmtest.Resource) []ResourceReport {
+	var reports []ResourceReport
+	for _, resource := range resources {
+		report := ResourceReport{Resource: resourceName(resource)}
+		for _, rule := range e.Rules {
+			for _, violation := range rule.Check(resource) {
+				violation.Rule = rule.Name()
+				violation.Resource = report.Resource
+				report.Violations = append(report.Violations, violation)
+			}
+		}
+		if len(report.Violations) > 0 {
+			reports = append(reports, report)
+		}
+	}
+	return reports
+}
+
+// Violations flattens reports into the list of their violations
+func Violations(reports []ResourceReport) []Violation {
+	var violations []Violation
+	for _, report := range reports {
+		violations = append(violations, report.Violations...)
+	}
+	return violations
+}
+
+// AssertCompliant runs the engine over every resource held by parser, reporting each violation as a test error
+func AssertCompliant(t *testing.T, parser *helmtest.HelmChartParser, engine *Engine) {
+	resources, err := parser.QueryE(helmtest.ResourceQuery{})
+	require.NoError(t, err)
+	for _, violation := range Violations(engine.Evaluate(resources)) {
+		t.Errorf("policy violation: %s", violation)
+	}
+}
+
+// Describe formats reports as one block per resource, for logging the result of a run
+func Describe(reports []ResourceReport) string {
+	var builder strings.Builder
+	for _, report := range reports {
+		builder.WriteString(report.Resource + "\n")
+		for _, violation := range report.Violations {
+			builder.WriteString(fmt.Sprintf("  [%s] %s: %s\n", violation.Rule, violation.Path, violation.Message))
+		}
+	}
+	return builder.String()
+}
+
+func resourceName(resource helmtest.Resource) string {
+	name := resource.Kind + "/" + resource.Name
+	if resource.Source != "" {
+		name += " (" + resource.Source + ")"
+	}
+	return name
+}
@@ -0,0 +1,156 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const renderedWorkloads = `---
+# Source: pega/templates/pega-tier-deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: pega-web
+spec:
+  selector:
+    matchLabels:
+      app: pega-web
+  template:
+    metadata:
+      labels:
+        app: pega-web
+    spec:
+      securityContext:
+        runAsUser: 9001
+      initContainers:
+      - name: wait-for-pegainstall
+        image: pegasystems/k8s-wait-for
+        resources:
+          limits:
+            cpu: 50m
+            memory: 64Mi
+      - name: wait-for-pegasearch
+        image: busybox:1.31.0
+        resources:
+          limits:
+            cpu: 50m
+            memory: 64Mi
+      containers:
+      - name: pega-web-tomcat
+        image: pegasystems/pega@sha256:0a5bb8d0b3ae2ea9db1a8e8e2d46d0cd1bd46dba5c48af43c4e3b9e2a1f1e6c1
+        resources:
+          limits:
+            cpu: 4
+            memory: 12Gi
+      - name: test-sidecar
+        image: test/sidecar:latest
+        securityContext:
+          runAsUser: 0
+          privileged: true
+---
+# Source: pega/charts/installer/templates/pega-install-environment-config.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: pega-install-environment-config
+data:
+  ADMIN_PASSWORD: changeit
+  CASSANDRA_EXTENDED_TOKEN_AWARE_POLICY: "false"
+  SECRET_NAME: ""
+  prbootstrap.properties: |
+    pega.jdbc.url=jdbc:postgresql://postgres:5432/pegadb
+    pega.jdbc.password={{ .Env.DB_PASSWORD }}
+    pega.admin.password=${ADMIN_PASSWORD}
+    pega.jdbc.username=pega
+    keystorePassword="changeit"
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: pega-web
+spec:
+  type: NodePort
+  ports:
+  - port: 80
+`
+
+func evaluate(t *testing.T, engine *Engine) []ResourceReport {
+	parser := helmtest.NewHelmChartParser(t, renderedWorkloads, "default")
+	return engine.Evaluate(parser.Query(helmtest.ResourceQuery{}))
+}
+
+func TestDefaultRulesReportViolationsPerResource(t *testing.T) {
+	deployment := "Deployment/pega-web (pega/templates/pega-tier-deployment.yaml)"
+	configMap := "ConfigMap/pega-install-environment-config (pega/charts/installer/templates/pega-install-environment-config.yaml)"
+	require.Equal(t, []ResourceReport{
+		{Resource: deployment, Violations: []Violation{
+			{Rule: "run-as-non-root", Resource: deployment, Path: "spec.template.spec.containers[1]",
+				Message: "container test-sidecar runs as root (runAsUser 0)"},
+			{Rule: "not-privileged", Resource: deployment, Path: "spec.template.spec.containers[1].securityContext.privileged",
+				Message: "container test-sidecar is privileged"},
+			{Rule: "resource-limits", Resource: deployment, Path: "spec.template.spec.containers[1].resources.limits",
+				Message: "container test-sidecar has no cpu or memory limit"},
+			{Rule: "pinned-image-tags", Resource: deployment, Path: "spec.template.spec.initContainers[0].image",
+				Message: "image pegasystems/k8s-wait-for of container wait-for-pegainstall has no tag and is not pinned to a digest"},
+			{Rule: "pinned-image-tags", Resource: deployment, Path: "spec.template.spec.initContainers[1].image",
+				Message: "image busybox:1.31.0 of container wait-for-pegasearch is not pinned to a digest"},
+			{Rule: "pinned-image-tags", Resource: deployment, Path: "spec.template.spec.containers[1].image",
+				Message: "image test/sidecar:latest of container test-sidecar uses the latest tag and is not pinned to a digest"},
+		}},
+		{Resource: configMap, Violations: []Violation{
+			{Rule: "no-plaintext-credentials", Resource: configMap, Path: "data.ADMIN_PASSWORD",
+				Message: "ADMIN_PASSWORD holds a credential in plain text"},
+			{Rule: "no-plaintext-credentials", Resource: configMap, Path: "data.prbootstrap.properties",
+				Message: "line 5 assigns keystorePassword in plain text"},
+		}},
+	}, evaluate(t, NewEngine()))
+}
+
+func TestRunAsNonRootWithoutSecurityContext(t *testing.T) {
+	parser := helmtest.NewHelmChartParser(t, `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: pega-db-install
+spec:
+  template:
+    spec:
+      containers:
+      - name: pega-installer
+        image: pegasystems/pega-installer:8.8.0
+`, "default")
+	violations := Violations(NewEngine(RunAsNonRoot{}).Evaluate(parser.Query(helmtest.ResourceQuery{})))
+	require.Equal(t, []Violation{{
+		Rule:     "run-as-non-root",
+		Resource: "Job/pega-db-install",
+		Path:     "spec.template.spec.containers[0]",
+		Message:  "container pega-installer sets neither runAsNonRoot nor a non-zero runAsUser and may run as root",
+	}}, violations)
+}
+
+// noNodePorts is a rule outside of the default pack, showing how the engine is extended
+type noNodePorts struct{}
+
+func (noNodePorts) Name() string { return "no-node-ports" }
+
+func (noNodePorts) Check(resource helmtest.Resource) []Violation {
+	if service, ok := resource.Object.(*corev1.Service); ok && service.Spec.Type == corev1.ServiceTypeNodePort {
+		return []Violation{{Path: "spec.type", Message: "services must not be exposed on node ports"}}
+	}
+	return nil
+}
+
+func TestCustomRules(t *testing.T) {
+	require.Equal(t, []ResourceReport{{Resource: "Service/pega-web", Violations: []Violation{{
+		Rule:     "no-node-ports",
+		Resource: "Service/pega-web",
+		Path:     "spec.type",
+		Message:  "services must not be exposed on node ports",
+	}}}}, evaluate(t, NewEngine(noNodePorts{})))
+
+	rules := append(DefaultRules(), noNodePorts{})
+	require.Len(t, Violations(evaluate(t, NewEngine(rules...))), 9)
+}
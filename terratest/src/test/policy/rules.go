@@ -0,0 +1,156 @@
+package policy
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// DefaultRules is the security baseline rendered workloads are held to
+func DefaultRules() []Rule {
+	return []Rule{
+		RunAsNonRoot{},
+		NotPrivileged{},
+		ResourceLimits{},
+		PinnedImages{},
+		NoPlaintextCredentials{},
+	}
+}
+
+// RunAsNonRoot requires every container to run as a non-root user, set through runAsNonRoot or a non-zero runAsUser on
+// the container or the pod
+type RunAsNonRoot struct{}
+
+func (RunAsNonRoot) Name() string { return "run-as-non-root" }
+
+func (RunAsNonRoot) Check(resource helmtest.Resource) []Violation {
+	return checkContainers(resource, func(w *workload, c container) []Violation {
+		var runAsNonRoot *bool
+		var runAsUser *int64
+		if podContext := w.Spec.SecurityContext; podContext != nil {
+			runAsNonRoot, runAsUser = podContext.RunAsNonRoot, podContext.RunAsUser
+		}
+		if c.SecurityContext != nil {
+			if c.SecurityContext.RunAsNonRoot != nil {
+				runAsNonRoot = c.SecurityContext.RunAsNonRoot
+			}
+			if c.SecurityContext.RunAsUser != nil {
+				runAsUser = c.SecurityContext.RunAsUser
+			}
+		}
+		switch {
+		case runAsUser != nil && *runAsUser == 0:
+			return []Violation{{Path: c.Path, Message: fmt.Sprintf("container %s runs as root (runAsUser 0)", c.Name)}}
+		case runAsUser != nil || (runAsNonRoot != nil && *runAsNonRoot):
+			return nil
+		default:
+			return []Violation{{Path: c.Path, Message: fmt.Sprintf(
+				"container %s sets neither runAsNonRoot nor a non-zero runAsUser and may run as root", c.Name)}}
+		}
+	})
+}
+
+// NotPrivileged forbids privileged containers
+type NotPrivileged struct{}
+
+func (NotPrivileged) Name() string { return "not-privileged" }
+
+func (NotPrivileged) Check(resource helmtest.Resource) []Violation {
+	return checkContainers(resource, func(_ *workload, c container) []Violation {
+		if c.SecurityContext == nil || c.SecurityContext.Privileged == nil || !*c.SecurityContext.Privileged {
+			return nil
+		}
+		return []Violation{{Path: c.Path + ".securityContext.privileged", Message: fmt.Sprintf("container %s is privileged", c.Name)}}
+	})
+}
+
+// ResourceLimits requires a cpu and a memory limit on every container, sidecars and init containers included
+type ResourceLimits struct{}
+
+func (ResourceLimits) Name() string { return "resource-limits" }
+
+func (ResourceLimits) Check(resource helmtest.Resource) []Violation {
+	return checkContainers(resource, func(_ *workload, c container) []Violation {
+		var missing []string
+		for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			if _, ok := c.Resources.Limits[name]; !ok {
+				missing = append(missing, string(name))
+			}
+		}
+		if len(missing) == 0 {
+			return nil
+		}
+		return []Violation{{Path: c.Path + ".resources.limits", Message: fmt.Sprintf("container %s has no %s limit",
+			c.Name, strings.Join(missing, " or "))}}
+	})
+}
+
+// PinnedImages requires every image to be pinned to a digest. Tags can be moved to other content, so an image such as
+// busybox:1.31.0 fails the rule as well as an untagged or latest one.
+type PinnedImages struct{}
+
+func (PinnedImages) Name() string { return "pinned-image-tags" }
+
+func (PinnedImages) Check(resource helmtest.Resource) []Violation {
+	return checkContainers(resource, func(_ *workload, c container) []Violation {
+		if strings.Contains(c.Image, "@sha256:") {
+			return nil
+		}
+		reason := "is not pinned to a digest"
+		name := c.Image[strings.LastIndex(c.Image, "/")+1:]
+		if !strings.Contains(name, ":") {
+			reason = "has no tag and is not pinned to a digest"
+		} else if strings.HasSuffix(name, ":latest") {
+			reason = "uses the latest tag and is not pinned to a digest"
+		}
+		return []Violation{{Path: c.Path + ".image", Message: fmt.Sprintf("image %s of container %s %s", c.Image, c.Name, reason)}}
+	})
+}
+
+// credentialKey matches ConfigMap keys ending in a credential word, such as ADMIN_PASSWORD or apiToken, but not names
+// that merely contain one like CASSANDRA_EXTENDED_TOKEN_AWARE_POLICY
+var credentialKey = regexp.MustCompile(`(?i)(password|passwd|secret|token|api_?key|credentials?|private_?key)$`)
+
+// credentialAssignment matches a credential assigned a literal value in an embedded file, e.g. password=changeit or
+// password="changeit". Values filled in from the environment or a template ({{ .Env.DB_PASSWORD }}, ${DB_PASSWORD})
+// are not literals.
+var credentialAssignment = regexp.MustCompile(`(?i)([\w.-]*(?:password|passwd|secret|token|api_?key|private_?key))\s*[=:]\s*"?[^"\s{$]`)
+
+// NoPlaintextCredentials forbids credentials in ConfigMaps, which are not encrypted at rest or protected by RBAC like
+// Secrets are
+type NoPlaintextCredentials struct{}
+
+func (NoPlaintextCredentials) Name() string { return "no-plaintext-credentials" }
+
+func (NoPlaintextCredentials) Check(resource helmtest.Resource) []Violation {
+	configMap, ok := resource.Object.(*corev1.ConfigMap)
+	if !ok {
+		return nil
+	}
+	keys := make([]string, 0, len(configMap.Data))
+	for key := range configMap.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var violations []Violation
+	for _, key := range keys {
+		value := configMap.Data[key]
+		path := "data." + key
+		if credentialKey.MatchString(key) && strings.TrimSpace(value) != "" && !strings.Contains(value, "\n") {
+			violations = append(violations, Violation{Path: path, Message: fmt.Sprintf("%s holds a credential in plain text", key)})
+			continue
+		}
+		for i, line := range strings.Split(value, "\n") {
+			if match := credentialAssignment.FindStringSubmatch(line); match != nil {
+				violations = append(violations, Violation{Path: path, Message: fmt.Sprintf(
+					"line %d assigns %s in plain text", i+1, match[1])})
+			}
+		}
+	}
+	return violations
+}
@@ -0,0 +1,79 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// workload is the pod spec of a resource together with its location in the resource
+type workload struct {
+	Spec corev1.PodSpec
+	Path string
+}
+
+// container is a container or init container of a workload together with its location in the resource
+type container struct {
+	corev1.Container
+	Path string
+}
+
+// podSpecOf returns the pod spec of a workload resource, or nil for other kinds. Typed objects and the unstructured
+// ones decoded for API versions unknown to the test scheme are handled alike.
+func podSpecOf(resource helmtest.Resource) (*workload, error) {
+	path, ok := helmtest.PodSpecPaths[resource.Kind]
+	if !ok || resource.Object == nil {
+		return nil, nil
+	}
+	var object map[string]interface{}
+	if unstructuredObject, ok := resource.Object.(*unstructured.Unstructured); ok {
+		object = unstructuredObject.Object
+	} else {
+		var err error
+		if object, err = runtime.DefaultUnstructuredConverter.ToUnstructured(resource.Object); err != nil {
+			return nil, err
+		}
+	}
+	spec, found, err := unstructured.NestedMap(object, strings.Split(path, ".")...)
+	if err != nil || !found {
+		return nil, err
+	}
+	result := &workload{Path: path}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(spec, &result.Spec); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// containers lists the init containers followed by the containers of the workload
+func (w *workload) containers() []container {
+	var containers []container
+	for i, initContainer := range w.Spec.InitContainers {
+		containers = append(containers, container{Container: initContainer, Path: fmt.Sprintf("%s.initContainers[%d]", w.Path, i)})
+	}
+	for i, appContainer := range w.Spec.Containers {
+		containers = append(containers, container{Container: appContainer, Path: fmt.Sprintf("%s.containers[%d]", w.Path, i)})
+	}
+	return containers
+}
+
+// checkContainers runs check on every container of a workload resource, reporting a violation when the pod spec
+// cannot be decoded
+func checkContainers(resource helmtest.Resource, check func(w *workload, c container) []Violation) []Violation {
+	w, err := podSpecOf(resource)
+	if err != nil {
+		return []Violation{{Message: fmt.Sprintf("cannot decode the pod spec: %v", err)}}
+	}
+	if w == nil {
+		return nil
+	}
+	var violations []Violation
+	for _, c := range w.containers() {
+		violations = append(violations, check(w, c)...)
+	}
+	return violations
+}
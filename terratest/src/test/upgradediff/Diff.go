@@ -0,0 +1,197 @@
+package upgradediff
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+	"sigs.k8s.io/yaml"
+)
+
+// ChangeType tells how a resource differs between the two renders
+type ChangeType string
+
+const (
+	Added     ChangeType = "added"
+	Removed   ChangeType = "removed"
+	Changed   ChangeType = "changed"
+	Unchanged ChangeType = "unchanged"
+)
+
+// Change is a single field that differs between the two renders. Before is nil for an added field and After for a
+// removed one.
+type Change struct {
+	// Path is the dot separated location of the field, e.g. spec.template.spec.containers[name=pega-web-tomcat].image
+	Path   string      `json:"path"`
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// ResourceDiff is the difference of one Kubernetes object. Unchanged objects are only reported when the upgrade
+// still affects them, like a Job helm runs as an upgrade hook.
+type ResourceDiff struct {
+	// Resource identifies the object as Kind/name
+	Resource  string     `json:"resource"`
+	Namespace string     `json:"namespace"`
+	Source    string     `json:"source,omitempty"`
+	Type      ChangeType `json:"type"`
+	Changes   []Change   `json:"changes,omitempty"`
+	Impacts   []Impact   `json:"impacts,omitempty"`
+}
+
+// Report is the machine readable difference between two renders
+type Report struct {
+	Resources []ResourceDiff `json:"resources"`
+}
+
+// Impacts lists the impacts of every resource, in report order
+func (r *Report) Impacts() []Impact {
+	var impacts []Impact
+	for _, resource := range r.Resources {
+		impacts = append(impacts, resource.Impacts...)
+	}
+	return impacts
+}
+
+// Resource returns the diff of the object identified as Kind/name, or nil when it is not part of the report
+func (r *Report) Resource(name string) *ResourceDiff {
+	for i := range r.Resources {
+		if r.Resources[i].Resource == name {
+			return &r.Resources[i]
+		}
+	}
+	return nil
+}
+
+// Side is one of the two renders compared: the release as deployed or as it is upgraded to
+type Side struct {
+	// ChartPath is a chart directory or packaged chart, which lets two chart versions be compared. It defaults to the
+	// pega chart of this repository.
+	ChartPath   string
+	ValuesFiles []string
+	SetValues   map[string]string
+}
+
+// Options control a diff
+type Options struct {
+	Before      Side
+	After       Side
+	ReleaseName string
+	Namespace   string
+	KubeVersion string
+}
+
+// Diff renders both sides and compares them. Rendering errors are returned as is, as there is nothing to compare.
+func Diff(options Options) (*Report, error) {
+	before, err := render(options, options.Before)
+	if err != nil {
+		return nil, fmt.Errorf("rendering the current release: %w", err)
+	}
+	after, err := render(options, options.After)
+	if err != nil {
+		return nil, fmt.Errorf("rendering the upgrade: %w", err)
+	}
+	return Compare(before, after, options.Namespace)
+}
+
+// Compare diffs two rendered manifests, matching objects by kind, namespace and name. Objects without a namespace are
+// placed in defaultNamespace.
+func Compare(before string, after string, defaultNamespace string) (*Report, error) {
+	if defaultNamespace == "" {
+		defaultNamespace = "default"
+	}
+	beforeObjects, err := decode(before, defaultNamespace)
+	if err != nil {
+		return nil, err
+	}
+	afterObjects, err := decode(after, defaultNamespace)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Resources: []ResourceDiff{}}
+	for key, current := range afterObjects {
+		diff := ResourceDiff{
+			Resource:  current.resource.Kind + "/" + current.resource.Name,
+			Namespace: current.resource.Namespace,
+			Source:    current.resource.Source,
+			Type:      Added,
+		}
+		if previous, ok := beforeObjects[key]; ok {
+			diff.Changes = compare("", previous.object, current.object)
+			diff.Type = Changed
+			if len(diff.Changes) == 0 {
+				diff.Type = Unchanged
+			}
+		}
+		if current.resource.Kind == "Secret" {
+			redactSecretData(diff.Changes)
+		}
+		diff.Impacts = impactsOf(current.resource.Kind, diff.Type, diff.Changes, current.object)
+		if diff.Type != Unchanged || len(diff.Impacts) > 0 {
+			report.Resources = append(report.Resources, diff)
+		}
+	}
+	for key, previous := range beforeObjects {
+		if _, ok := afterObjects[key]; !ok {
+			report.Resources = append(report.Resources, ResourceDiff{
+				Resource:  previous.resource.Kind + "/" + previous.resource.Name,
+				Namespace: previous.resource.Namespace,
+				Source:    previous.resource.Source,
+				Type:      Removed,
+			})
+		}
+	}
+	sort.Slice(report.Resources, func(i, j int) bool {
+		if report.Resources[i].Namespace != report.Resources[j].Namespace {
+			return report.Resources[i].Namespace < report.Resources[j].Namespace
+		}
+		return report.Resources[i].Resource < report.Resources[j].Resource
+	})
+	return report, nil
+}
+
+// renderedObject is a rendered resource together with its generic form the comparison walks
+type renderedObject struct {
+	resource helmtest.Resource
+	object   map[string]interface{}
+}
+
+func decode(rendered string, defaultNamespace string) (map[string]renderedObject, error) {
+	parser, err := helmtest.NewHelmChartParserE(nil, rendered, defaultNamespace)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := parser.QueryE(helmtest.ResourceQuery{})
+	if err != nil {
+		return nil, err
+	}
+	objects := map[string]renderedObject{}
+	for _, resource := range resources {
+		object := map[string]interface{}{}
+		if err := yaml.Unmarshal([]byte(resource.YAML), &object); err != nil {
+			return nil, fmt.Errorf("decoding rendered %s/%s: %w", resource.Kind, resource.Name, err)
+		}
+		// an object is placed in the same namespace whether the template names it or not
+		if metadata, ok := object["metadata"].(map[string]interface{}); ok {
+			metadata["namespace"] = resource.Namespace
+		}
+		key := resource.Kind + "/" + resource.Namespace + "/" + resource.Name
+		if _, duplicate := objects[key]; duplicate {
+			return nil, fmt.Errorf("%s/%s is rendered more than once in namespace %s", resource.Kind, resource.Name, resource.Namespace)
+		}
+		objects[key] = renderedObject{resource: resource, object: object}
+	}
+	return objects, nil
+}
+
+func render(options Options, side Side) (string, error) {
+	return helmtest.CommandRender{
+		ChartPath:   side.ChartPath,
+		ReleaseName: options.ReleaseName,
+		Namespace:   options.Namespace,
+		KubeVersion: options.KubeVersion,
+		ValuesFiles: side.ValuesFiles,
+		SetValues:   side.SetValues,
+	}.RenderE()
+}
@@ -0,0 +1,369 @@
+package upgradediff
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+	"github.com/stretchr/testify/require"
+)
+
+const beforeValues = "data/values_upgrade_before.yaml"
+
+const renderedBefore = `---
+# Source: pega/templates/pega-tier-deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: pega-web
+spec:
+  selector:
+    matchLabels:
+      app: pega-web
+  template:
+    metadata:
+      annotations:
+        config-check: 1111
+    spec:
+      containers:
+      - name: pega-web-tomcat
+        image: pegasystems/pega:8.8.0
+        env:
+        - name: JAVA_OPTS
+          value: ""
+        - name: MAX_HEAP
+          value: 8g
+---
+# Source: pega/templates/pega-credentials-secret.yaml
+apiVersion: v1
+kind: Secret
+metadata:
+  name: pega-db-secret
+data:
+  DB_PASSWORD: b2xk
+---
+# Source: pega/charts/installer/templates/pega-installer-job.yaml
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: pega-db-upgrade
+  annotations:
+    "helm.sh/hook": post-install, post-upgrade
+spec:
+  template:
+    spec:
+      containers:
+      - name: pega-db-upgrade
+        image: pegasystems/pega-installer:8.8.0
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: pega-zdt-upgrade
+spec:
+  template:
+    spec:
+      containers:
+      - name: pega-zdt-upgrade
+        image: pegasystems/pega-installer:8.8.0
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: pega-web
+data:
+  prconfig.xml: |
+    <pegarules>
+      <env name="security/urlaccesslog" value="NORMAL" />
+    </pegarules>
+`
+
+const renderedAfter = `---
+# Source: pega/templates/pega-tier-deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: pega-web
+spec:
+  selector:
+    matchLabels:
+      app: pega-web-tier
+  template:
+    metadata:
+      annotations:
+        config-check: 2222
+    spec:
+      containers:
+      - name: pega-web-tomcat
+        image: pegasystems/pega:8.8.0
+        env:
+        - name: INITIAL_HEAP
+          value: 4g
+        - name: JAVA_OPTS
+          value: ""
+        - name: MAX_HEAP
+          value: 8g
+---
+# Source: pega/templates/pega-credentials-secret.yaml
+apiVersion: v1
+kind: Secret
+metadata:
+  name: pega-db-secret
+data:
+  DB_PASSWORD: bmV3
+---
+# Source: pega/charts/installer/templates/pega-installer-job.yaml
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: pega-db-upgrade
+  annotations:
+    "helm.sh/hook": post-install, post-upgrade
+spec:
+  template:
+    spec:
+      containers:
+      - name: pega-db-upgrade
+        image: pegasystems/pega-installer:8.8.0
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: pega-zdt-upgrade
+spec:
+  template:
+    spec:
+      containers:
+      - name: pega-zdt-upgrade
+        image: pegasystems/pega-installer:8.8.1
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: pega-web
+  namespace: pega
+data:
+  prconfig.xml: |
+    <pegarules>
+      <env name="security/urlaccesslog" value="1" />
+    </pegarules>
+`
+
+func TestCompare(t *testing.T) {
+	report, err := Compare(renderedBefore, renderedAfter, "")
+	require.NoError(t, err)
+
+	require.Equal(t, []ResourceDiff{
+		{Resource: "ConfigMap/pega-web", Namespace: "default", Type: Removed},
+		{Resource: "Deployment/pega-web", Namespace: "default", Source: "pega/templates/pega-tier-deployment.yaml", Type: Changed,
+			Changes: []Change{
+				{Path: "spec.selector.matchLabels.app", Before: "pega-web", After: "pega-web-tier"},
+				{Path: "spec.template.metadata.annotations.config-check", Before: float64(1111), After: float64(2222)},
+				{Path: "spec.template.spec.containers[name=pega-web-tomcat].env[name=INITIAL_HEAP]",
+					After: map[string]interface{}{"name": "INITIAL_HEAP", "value": "4g"}},
+			},
+			Impacts: []Impact{
+				{Kind: Recreate, Reason: "spec.selector is immutable: delete the Deployment, e.g. with kubectl delete --cascade=orphan, before upgrading"},
+				{Kind: PodRestart, Reason: "config-check changed: the environment configuration of the Pega pods changed"},
+				{Kind: PodRestart, Reason: "the pod template changed at spec.template.spec.containers[name=pega-web-tomcat].env[name=INITIAL_HEAP]"},
+			}},
+		{Resource: "Job/pega-db-upgrade", Namespace: "default", Source: "pega/charts/installer/templates/pega-installer-job.yaml", Type: Unchanged,
+			Impacts: []Impact{{Kind: JobRerun, Reason: "helm runs the Job on every upgrade as a post-upgrade hook"}}},
+		{Resource: "Job/pega-zdt-upgrade", Namespace: "default", Type: Changed,
+			Changes: []Change{{Path: "spec.template.spec.containers[name=pega-zdt-upgrade].image",
+				Before: "pegasystems/pega-installer:8.8.0", After: "pegasystems/pega-installer:8.8.1"}},
+			Impacts: []Impact{
+				{Kind: Recreate, Reason: "spec.template is immutable: delete the Job before upgrading"},
+				{Kind: JobRerun, Reason: "the Job runs again when it is created"},
+			}},
+		{Resource: "Secret/pega-db-secret", Namespace: "default", Source: "pega/templates/pega-credentials-secret.yaml", Type: Changed,
+			Changes: []Change{{Path: "data.DB_PASSWORD", Before: redacted, After: redacted}}},
+		{Resource: "ConfigMap/pega-web", Namespace: "pega", Type: Added},
+	}, report.Resources)
+}
+
+func TestDescribeShowsLineDiffs(t *testing.T) {
+	report, err := Compare(renderedBefore, renderedAfter, "pega")
+	require.NoError(t, err)
+	require.Equal(t, `~ ConfigMap/pega-web
+    data["prconfig.xml"]:
+      -   <env name="security/urlaccesslog" value="NORMAL" />
+      +   <env name="security/urlaccesslog" value="1" />
+`, Describe(&Report{Resources: []ResourceDiff{*report.Resource("ConfigMap/pega-web")}}))
+	require.Equal(t, `~ Secret/pega-db-secret (pega/templates/pega-credentials-secret.yaml)
+    data.DB_PASSWORD: <redacted> -> <redacted>
+`, Describe(&Report{Resources: []ResourceDiff{*report.Resource("Secret/pega-db-secret")}}))
+}
+
+func TestDiffEnvironmentChangeRestartsEveryTier(t *testing.T) {
+	report, err := Diff(Options{
+		Before: Side{ValuesFiles: []string{beforeValues}},
+		After: Side{ValuesFiles: []string{beforeValues}, SetValues: map[string]string{
+			"global.jdbc.url": "jdbc:postgresql://postgres-replica.example.com:5432/pega",
+		}},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, []Change{{
+		Path:   "data.JDBC_URL",
+		Before: "jdbc:postgresql://postgres.example.com:5432/pega",
+		After:  "jdbc:postgresql://postgres-replica.example.com:5432/pega",
+	}}, report.Resource("ConfigMap/pega-environment-config").Changes)
+	for _, tier := range []string{"Deployment/pega-web", "Deployment/pega-batch", "StatefulSet/pega-stream"} {
+		require.Equal(t, []Impact{{Kind: PodRestart, Reason: "config-check changed: the environment configuration of the Pega pods changed"}},
+			report.Resource(tier).Impacts, tier)
+	}
+	require.Len(t, report.Impacts(), 3)
+}
+
+func TestDiffTierConfigurationRestartsOnlyThatTier(t *testing.T) {
+	report, err := Diff(Options{
+		Before: Side{ValuesFiles: []string{beforeValues}},
+		After:  Side{ValuesFiles: []string{"data/values_upgrade_web_prconfig.yaml"}},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, Changed, report.Resource("ConfigMap/pega-web").Type)
+	require.Equal(t, []Impact{{Kind: PodRestart, Reason: "config-tier-check changed: the configuration files of the tier changed"}},
+		report.Resource("Deployment/pega-web").Impacts)
+	require.Nil(t, report.Resource("Deployment/pega-batch"))
+	require.Nil(t, report.Resource("StatefulSet/pega-stream"))
+}
+
+func TestDiffStorageClassRecreatesStatefulSets(t *testing.T) {
+	report, err := Diff(Options{
+		Before: Side{ValuesFiles: []string{beforeValues}},
+		After:  Side{ValuesFiles: []string{beforeValues}, SetValues: map[string]string{"global.storageClassName": "fast-ssd"}},
+	})
+	require.NoError(t, err)
+
+	recreate := Impact{Kind: Recreate, Reason: "spec.volumeClaimTemplates is immutable: " +
+		"delete the StatefulSet, e.g. with kubectl delete --cascade=orphan, before upgrading"}
+	for _, statefulSet := range []string{"StatefulSet/pega-stream", "StatefulSet/pega-search"} {
+		require.Equal(t, []Change{{Path: "spec.volumeClaimTemplates[0].spec.storageClassName", After: "fast-ssd"}},
+			report.Resource(statefulSet).Changes, statefulSet)
+		require.Equal(t, []Impact{recreate}, report.Resource(statefulSet).Impacts, statefulSet)
+	}
+	require.Len(t, report.Impacts(), 2)
+}
+
+func TestDiffUpgradeActionRunsInstallerJobs(t *testing.T) {
+	report, err := Diff(Options{
+		Before: Side{ValuesFiles: []string{beforeValues}},
+		After: Side{ValuesFiles: []string{beforeValues}, SetValues: map[string]string{
+			"global.actions.execute":        "upgrade-deploy",
+			"installer.upgrade.upgradeType": "zero-downtime",
+		}},
+	})
+	require.NoError(t, err)
+
+	for _, job := range []string{"Job/pega-pre-upgrade", "Job/pega-zdt-upgrade", "Job/pega-post-upgrade"} {
+		require.Equal(t, Added, report.Resource(job).Type, job)
+		require.Equal(t, []Impact{{Kind: JobRerun, Reason: "the Job is new and runs when it is created"}}, report.Resource(job).Impacts, job)
+	}
+	require.Contains(t, report.Resource("Deployment/pega-web").Changes, Change{
+		Path: "spec.template.spec.initContainers[name=wait-for-pegaupgrade]",
+		After: map[string]interface{}{
+			"name":            "wait-for-pegaupgrade",
+			"image":           "pegasystems/k8s-wait-for",
+			"imagePullPolicy": "IfNotPresent",
+			"args":            []interface{}{"job", "pega-zdt-upgrade"},
+			"env": []interface{}{
+				map[string]interface{}{"name": "WAIT_TIME", "value": "2"},
+				map[string]interface{}{"name": "MAX_RETRIES", "value": "1"},
+			},
+			"resources": map[string]interface{}{
+				"limits":   map[string]interface{}{"cpu": "50m", "memory": "64Mi"},
+				"requests": map[string]interface{}{"cpu": "50m", "memory": "64Mi"},
+			},
+		},
+	})
+}
+
+func TestDiffPackagedChart(t *testing.T) {
+	packageDir := t.TempDir()
+	output, err := exec.Command("helm", "package", helmtest.ChartPath(helmtest.PegaChart), "-d", packageDir).CombinedOutput()
+	require.NoError(t, err, string(output))
+	packages, err := filepath.Glob(filepath.Join(packageDir, "pega-*.tgz"))
+	require.NoError(t, err)
+	require.Len(t, packages, 1)
+
+	report, err := Diff(Options{
+		Before: Side{ChartPath: packages[0], ValuesFiles: []string{beforeValues}},
+		After:  Side{ValuesFiles: []string{beforeValues}},
+	})
+	require.NoError(t, err)
+	require.Empty(t, report.Resources)
+}
+
+const servicesBefore = `---
+apiVersion: v1
+kind: Service
+metadata:
+  name: pega-search-transport
+spec:
+  clusterIP: None
+  ports:
+  - port: 9300
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: pega-web
+spec:
+  type: ClusterIP
+  ports:
+  - port: 80
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: pega-stream
+spec:
+  type: LoadBalancer
+  ports:
+  - port: 7003
+`
+
+const servicesAfter = `---
+apiVersion: v1
+kind: Service
+metadata:
+  name: pega-search-transport
+spec:
+  ports:
+  - port: 9300
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: pega-web
+spec:
+  type: ExternalName
+  externalName: web.example.com
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: pega-stream
+spec:
+  type: NodePort
+  ports:
+  - port: 7003
+`
+
+func TestCompareServices(t *testing.T) {
+	report, err := Compare(servicesBefore, servicesAfter, "")
+	require.NoError(t, err)
+
+	require.Equal(t, []Impact{{Kind: Recreate, Reason: "spec.clusterIP is immutable: delete the Service before upgrading"}},
+		report.Resource("Service/pega-search-transport").Impacts)
+	require.Equal(t, []Impact{{Kind: Recreate, Reason: "spec.type changes from ClusterIP to ExternalName, " +
+		"which keeps the allocated cluster IP: delete the Service before upgrading"}}, report.Resource("Service/pega-web").Impacts)
+	require.Empty(t, report.Resource("Service/pega-stream").Impacts)
+
+	report, err = Compare(servicesAfter, servicesBefore, "")
+	require.NoError(t, err)
+	require.Empty(t, report.Resource("Service/pega-web").Impacts)
+}
@@ -0,0 +1,140 @@
+package upgradediff
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+	"github.com/stretchr/testify/require"
+)
+
+// previousRelease is the last released pega chart, packaged without its remote dependencies. Add the next release
+// next to it, and point previousRelease at it, when a version is released.
+const previousRelease = "data/pega-3.23.0.tgz"
+
+// upgradePathScenarios are values customers run the chart with. Each is rendered with the previous release and with
+// the current tree.
+var upgradePathScenarios = []struct {
+	name      string
+	setValues map[string]string
+}{
+	{"k8s", map[string]string{}},
+	{"eks", map[string]string{"global.provider": "eks"}},
+	{"gke", map[string]string{"global.provider": "gke"}},
+	{"aks", map[string]string{"global.provider": "aks"}},
+	{"openshift", map[string]string{"global.provider": "openshift"}},
+	{"pks", map[string]string{"global.provider": "pks"}},
+	{"upgrade-deploy", map[string]string{"global.actions.execute": "upgrade-deploy", "installer.upgrade.upgradeType": "zero-downtime"}},
+	{"clustering-service", map[string]string{"hazelcast.clusteringServiceEnabled": "true"}},
+	{"external-search", map[string]string{"pegasearch.externalSearchService": "true", "pegasearch.externalURL": "http://srs.search:8080"}},
+	{"constellation", map[string]string{"constellation.enabled": "true"}},
+	{"tls", map[string]string{
+		"global.tier[0].service.tls.enabled":                  "true",
+		"global.tier[0].service.tls.external_secret_names[0]": "pega-web-keystore",
+	}},
+}
+
+// TestUpgradePathFromPreviousRelease fails when helm upgrade from the previous chart release would be rejected, because
+// the current tree renders an immutable field, such as a Deployment selector, the serviceName of a StatefulSet or the
+// pod template of an installer Job, differently for the same values
+func TestUpgradePathFromPreviousRelease(t *testing.T) {
+	chartPath := previousChart(t, previousRelease)
+	for _, scenario := range upgradePathScenarios {
+		scenario := scenario
+		t.Run(scenario.name, func(t *testing.T) {
+			t.Parallel()
+			report, err := Diff(Options{
+				Before: Side{ChartPath: chartPath, ValuesFiles: []string{beforeValues}, SetValues: scenario.setValues},
+				After:  Side{ValuesFiles: []string{beforeValues}, SetValues: scenario.setValues},
+			})
+			require.NoError(t, err)
+			var recreated []ResourceDiff
+			for _, resource := range report.Resources {
+				for _, impact := range resource.Impacts {
+					if impact.Kind == Recreate {
+						recreated = append(recreated, resource)
+						break
+					}
+				}
+			}
+			if len(recreated) > 0 {
+				t.Fatalf("helm upgrade from %s fails:\n%s", filepath.Base(previousRelease), Describe(&Report{Resources: recreated}))
+			}
+		})
+	}
+}
+
+// TestUpgradePathDetectsImmutableChanges renders the previous release against itself with values changes of immutable
+// fields, as a check that the upgrade path test can fail
+func TestUpgradePathDetectsImmutableChanges(t *testing.T) {
+	chartPath := previousChart(t, previousRelease)
+	report, err := Diff(Options{
+		Before: Side{ChartPath: chartPath, ValuesFiles: []string{beforeValues}},
+		After:  Side{ChartPath: chartPath, ValuesFiles: []string{beforeValues}, SetValues: map[string]string{"global.tier[2].volumeClaimTemplate.resources.requests.storage": "10Gi"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []Impact{{Kind: Recreate, Reason: "spec.volumeClaimTemplates is immutable: " +
+		"delete the StatefulSet, e.g. with kubectl delete --cascade=orphan, before upgrading"}}, report.Resource("StatefulSet/pega-stream").Impacts)
+
+	// the upgrade Jobs of upgrade-deploy are not hooks, so helm patches them in place
+	upgradeDeploy := map[string]string{"global.actions.execute": "upgrade-deploy", "installer.upgrade.upgradeType": "zero-downtime"}
+	upgradedInstaller := map[string]string{"installer.image": "pegasystems/pega-installer:8.8.1"}
+	for key, value := range upgradeDeploy {
+		upgradedInstaller[key] = value
+	}
+	report, err = Diff(Options{
+		Before: Side{ChartPath: chartPath, ValuesFiles: []string{beforeValues}, SetValues: upgradeDeploy},
+		After:  Side{ChartPath: chartPath, ValuesFiles: []string{beforeValues}, SetValues: upgradedInstaller},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []Impact{
+		{Kind: Recreate, Reason: "spec.template is immutable: delete the Job before upgrading"},
+		{Kind: JobRerun, Reason: "the Job runs again when it is created"},
+	}, report.Resource("Job/pega-zdt-upgrade").Impacts)
+}
+
+// previousChart extracts a packaged release into a temporary directory and adds the remote dependencies, which are not
+// kept in the fixture, from the charts directory of the current tree where helm dependency update downloads them
+func previousChart(t *testing.T, archive string) string {
+	dir := t.TempDir()
+	file, err := os.Open(archive)
+	require.NoError(t, err)
+	defer file.Close()
+	compressed, err := gzip.NewReader(file)
+	require.NoError(t, err)
+	reader := tar.NewReader(compressed)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		target := filepath.Join(dir, filepath.FromSlash(header.Name))
+		require.True(t, strings.HasPrefix(target, dir+string(filepath.Separator)), "%s escapes the chart directory", header.Name)
+		switch header.Typeflag {
+		case tar.TypeDir:
+			require.NoError(t, os.MkdirAll(target, 0755))
+		case tar.TypeReg:
+			require.NoError(t, os.MkdirAll(filepath.Dir(target), 0755))
+			content, err := ioutil.ReadAll(reader)
+			require.NoError(t, err)
+			require.NoError(t, ioutil.WriteFile(target, content, 0644))
+		}
+	}
+
+	chartDir := filepath.Join(dir, "pega")
+	dependencies, err := filepath.Glob(filepath.Join(helmtest.ChartPath(helmtest.PegaChart), "charts", "*.tgz"))
+	require.NoError(t, err)
+	for _, dependency := range dependencies {
+		content, err := ioutil.ReadFile(dependency)
+		require.NoError(t, err)
+		require.NoError(t, ioutil.WriteFile(filepath.Join(chartDir, "charts", filepath.Base(dependency)), content, 0644))
+	}
+	return chartDir
+}
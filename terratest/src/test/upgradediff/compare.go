@@ -0,0 +1,145 @@
+package upgradediff
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// redacted replaces the values of Secret fields, which are only base64 encoded
+const redacted = "<redacted>"
+
+// plainKey matches map keys that can be written as .key in a path; others are written as ["key"]
+var plainKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// compare walks two decoded YAML values and returns the fields that differ, ordered by path. Lists of objects that
+// all have a unique name, like containers, env or volumes, are matched by name so that inserting an entry does not
+// show up as a change of every following one.
+func compare(path string, before interface{}, after interface{}) []Change {
+	if reflect.DeepEqual(before, after) {
+		return nil
+	}
+	switch beforeValue := before.(type) {
+	case map[string]interface{}:
+		if afterValue, ok := after.(map[string]interface{}); ok {
+			return compareMaps(path, beforeValue, afterValue)
+		}
+	case []interface{}:
+		if afterValue, ok := after.([]interface{}); ok {
+			return compareLists(path, beforeValue, afterValue)
+		}
+	}
+	return []Change{{Path: path, Before: before, After: after}}
+}
+
+func compareMaps(path string, before map[string]interface{}, after map[string]interface{}) []Change {
+	keys := map[string]bool{}
+	for key := range before {
+		keys[key] = true
+	}
+	for key := range after {
+		keys[key] = true
+	}
+	sortedKeys := make([]string, 0, len(keys))
+	for key := range keys {
+		sortedKeys = append(sortedKeys, key)
+	}
+	sort.Strings(sortedKeys)
+
+	var changes []Change
+	for _, key := range sortedKeys {
+		changes = append(changes, compare(keyPath(path, key), before[key], after[key])...)
+	}
+	return changes
+}
+
+func compareLists(path string, before []interface{}, after []interface{}) []Change {
+	beforeNames, beforeNamed := names(before)
+	afterNames, afterNamed := names(after)
+	if !beforeNamed || !afterNamed {
+		var changes []Change
+		for i := 0; i < len(before) || i < len(after); i++ {
+			var beforeItem, afterItem interface{}
+			if i < len(before) {
+				beforeItem = before[i]
+			}
+			if i < len(after) {
+				afterItem = after[i]
+			}
+			changes = append(changes, compare(fmt.Sprintf("%s[%d]", path, i), beforeItem, afterItem)...)
+		}
+		return changes
+	}
+
+	var changes []Change
+	for i, name := range beforeNames {
+		var afterItem interface{}
+		if j := indexOf(afterNames, name); j >= 0 {
+			afterItem = after[j]
+		}
+		changes = append(changes, compare(fmt.Sprintf("%s[name=%s]", path, name), before[i], afterItem)...)
+	}
+	for j, name := range afterNames {
+		if indexOf(beforeNames, name) < 0 {
+			changes = append(changes, compare(fmt.Sprintf("%s[name=%s]", path, name), nil, after[j])...)
+		}
+	}
+	return changes
+}
+
+// names returns the name of every item of a list, and whether all of them are objects with a unique name
+func names(list []interface{}) ([]string, bool) {
+	var result []string
+	for _, item := range list {
+		object, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		name, ok := object["name"].(string)
+		if !ok || name == "" || indexOf(result, name) >= 0 {
+			return nil, false
+		}
+		result = append(result, name)
+	}
+	return result, true
+}
+
+func indexOf(list []string, value string) int {
+	for i, item := range list {
+		if item == value {
+			return i
+		}
+	}
+	return -1
+}
+
+func keyPath(path string, key string) string {
+	if !plainKey.MatchString(key) {
+		return fmt.Sprintf("%s[%q]", path, key)
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// hasPathPrefix reports whether path is prefix or a field below it
+func hasPathPrefix(path string, prefix string) bool {
+	return path == prefix || strings.HasPrefix(path, prefix+".") || strings.HasPrefix(path, prefix+"[")
+}
+
+func redactSecretData(changes []Change) {
+	for i, change := range changes {
+		if !hasPathPrefix(change.Path, "data") && !hasPathPrefix(change.Path, "stringData") {
+			continue
+		}
+		if change.Before != nil {
+			changes[i].Before = redacted
+		}
+		if change.After != nil {
+			changes[i].After = redacted
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package upgradediff
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+var changeMarkers = map[ChangeType]string{Added: "+", Removed: "-", Changed: "~", Unchanged: "="}
+
+// Describe formats a report for reading in a terminal: one block per resource with its changed fields and impacts.
+// Multi-line values, such as configuration files held in a ConfigMap, are shown as a line diff.
+func Describe(report *Report) string {
+	var builder strings.Builder
+	for _, resource := range report.Resources {
+		builder.WriteString(fmt.Sprintf("%s %s", changeMarkers[resource.Type], resource.Resource))
+		if resource.Source != "" {
+			builder.WriteString(" (" + resource.Source + ")")
+		}
+		builder.WriteString("\n")
+		for _, change := range resource.Changes {
+			builder.WriteString(describeChange(change))
+		}
+		for _, impact := range resource.Impacts {
+			builder.WriteString(fmt.Sprintf("  ! %s: %s\n", impact.Kind, impact.Reason))
+		}
+	}
+	return builder.String()
+}
+
+func describeChange(change Change) string {
+	beforeText, beforeMultiLine := change.Before.(string)
+	afterText, afterMultiLine := change.After.(string)
+	beforeMultiLine = beforeMultiLine && strings.Contains(beforeText, "\n")
+	afterMultiLine = afterMultiLine && strings.Contains(afterText, "\n")
+	if !beforeMultiLine && !afterMultiLine {
+		return fmt.Sprintf("    %s: %s -> %s\n", change.Path, formatValue(change.Before), formatValue(change.After))
+	}
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("    %s:\n", change.Path))
+	for _, line := range lineDiff(lines(beforeText), lines(afterText)) {
+		builder.WriteString("      " + line + "\n")
+	}
+	return builder.String()
+}
+
+func formatValue(value interface{}) string {
+	if value == nil {
+		return "<none>"
+	}
+	if text, ok := value.(string); ok && text == redacted {
+		return text
+	}
+	content, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(content)
+}
+
+func lines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// lineDiff returns the lines removed from before, prefixed with -, and added in after, prefixed with +, based on
+// their longest common subsequence. Unchanged lines are left out.
+func lineDiff(before []string, after []string) []string {
+	common := make([][]int, len(before)+1)
+	for i := range common {
+		common[i] = make([]int, len(after)+1)
+	}
+	for i := len(before) - 1; i >= 0; i-- {
+		for j := len(after) - 1; j >= 0; j-- {
+			if before[i] == after[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else if common[i+1][j] >= common[i][j+1] {
+				common[i][j] = common[i+1][j]
+			} else {
+				common[i][j] = common[i][j+1]
+			}
+		}
+	}
+
+	var diff []string
+	i, j := 0, 0
+	for i < len(before) || j < len(after) {
+		switch {
+		case i < len(before) && j < len(after) && before[i] == after[j]:
+			i++
+			j++
+		case j == len(after) || (i < len(before) && common[i+1][j] >= common[i][j+1]):
+			diff = append(diff, "- "+before[i])
+			i++
+		default:
+			diff = append(diff, "+ "+after[j])
+			j++
+		}
+	}
+	return diff
+}
@@ -0,0 +1,156 @@
+package upgradediff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ImpactKind classifies what an upgrade does to the running deployment beyond updating an object in place
+type ImpactKind string
+
+const (
+	// PodRestart is a rolling restart of the pods of a Deployment, StatefulSet or DaemonSet
+	PodRestart ImpactKind = "pod-restart"
+	// Recreate is a change to an immutable field: helm upgrade fails until the object is deleted and created again
+	Recreate ImpactKind = "recreate"
+	// JobRerun is a Job that runs again, such as the installer running a database upgrade
+	JobRerun ImpactKind = "job-rerun"
+)
+
+// Impact is a consequence of the upgrade for one resource
+type Impact struct {
+	Kind   ImpactKind `json:"kind"`
+	Reason string     `json:"reason"`
+}
+
+// checksumAnnotations are the pod template annotations pega.deployment sets to the hash of the configuration the pods
+// read on startup, so that changing it rolls the tier
+var checksumAnnotations = []struct {
+	name        string
+	description string
+}{
+	{"config-check", "the environment configuration of the Pega pods changed"},
+	{"config-tier-check", "the configuration files of the tier changed"},
+	{"certificate-check", "the certificates mounted into the Pega pods changed"},
+}
+
+// immutableFields lists the spec fields of the workload kinds that cannot be updated in place
+var immutableFields = map[string][]string{
+	"Deployment":  {"spec.selector"},
+	"DaemonSet":   {"spec.selector"},
+	"StatefulSet": {"spec.volumeClaimTemplates", "spec.selector", "spec.serviceName", "spec.podManagementPolicy"},
+}
+
+// upgradeHooks are the helm hooks run on every upgrade, whether or not the hook resource changed
+var upgradeHooks = []string{"pre-upgrade", "post-upgrade"}
+
+func impactsOf(kind string, changeType ChangeType, changes []Change, object map[string]interface{}) []Impact {
+	var impacts []Impact
+	switch kind {
+	case "Deployment", "StatefulSet", "DaemonSet":
+		if changeType != Changed {
+			break
+		}
+		for _, field := range immutableFields[kind] {
+			if changed(changes, field) {
+				impacts = append(impacts, Impact{Kind: Recreate, Reason: fmt.Sprintf(
+					"%s is immutable: delete the %s, e.g. with kubectl delete --cascade=orphan, before upgrading", field, kind)})
+			}
+		}
+		impacts = append(impacts, podRestarts(changes)...)
+	case "Service":
+		if changeType == Changed {
+			impacts = append(impacts, serviceRecreates(changes)...)
+		}
+	case "Job":
+		if hook := upgradeHook(object); hook != "" {
+			impacts = append(impacts, Impact{Kind: JobRerun, Reason: fmt.Sprintf("helm runs the Job on every upgrade as a %s hook", hook)})
+		} else if changeType == Added {
+			impacts = append(impacts, Impact{Kind: JobRerun, Reason: "the Job is new and runs when it is created"})
+		} else if changeType == Changed && changed(changes, "spec.template") {
+			impacts = append(impacts,
+				Impact{Kind: Recreate, Reason: "spec.template is immutable: delete the Job before upgrading"},
+				Impact{Kind: JobRerun, Reason: "the Job runs again when it is created"})
+		}
+	}
+	return impacts
+}
+
+// podRestarts explains a change of the pod template, naming the checksum annotations that changed before any other
+// field
+func podRestarts(changes []Change) []Impact {
+	var impacts []Impact
+	var otherFields []string
+	for _, change := range changes {
+		if !hasPathPrefix(change.Path, "spec.template") {
+			continue
+		}
+		checksum := false
+		for _, annotation := range checksumAnnotations {
+			if change.Path == "spec.template.metadata.annotations."+annotation.name {
+				impacts = append(impacts, Impact{Kind: PodRestart, Reason: annotation.name + " changed: " + annotation.description})
+				checksum = true
+			}
+		}
+		if !checksum {
+			otherFields = append(otherFields, change.Path)
+		}
+	}
+	switch {
+	case len(otherFields) == 1:
+		impacts = append(impacts, Impact{Kind: PodRestart, Reason: "the pod template changed at " + otherFields[0]})
+	case len(otherFields) > 1:
+		impacts = append(impacts, Impact{Kind: PodRestart, Reason: fmt.Sprintf(
+			"the pod template changed at %s and %d other field(s)", otherFields[0], len(otherFields)-1)})
+	}
+	return impacts
+}
+
+// serviceRecreates flags the Service changes the API server rejects: the cluster IP is allocated once, so it cannot be
+// changed, nor dropped to make the Service headless, unless the Service is or becomes an ExternalName. A Service
+// cannot become an ExternalName through helm upgrade either, as the patch keeps the cluster IP allocated before.
+func serviceRecreates(changes []Change) []Impact {
+	typeBefore, typeAfter := "ClusterIP", "ClusterIP"
+	for _, change := range changes {
+		if change.Path == "spec.type" {
+			if before, ok := change.Before.(string); ok {
+				typeBefore = before
+			}
+			if after, ok := change.After.(string); ok {
+				typeAfter = after
+			}
+		}
+	}
+	var impacts []Impact
+	if typeAfter == "ExternalName" && typeBefore != "ExternalName" {
+		impacts = append(impacts, Impact{Kind: Recreate, Reason: fmt.Sprintf(
+			"spec.type changes from %s to ExternalName, which keeps the allocated cluster IP: delete the Service before upgrading", typeBefore)})
+	} else if typeBefore != "ExternalName" && (changed(changes, "spec.clusterIP") || changed(changes, "spec.clusterIPs")) {
+		impacts = append(impacts, Impact{Kind: Recreate, Reason: "spec.clusterIP is immutable: delete the Service before upgrading"})
+	}
+	return impacts
+}
+
+func changed(changes []Change, field string) bool {
+	for _, change := range changes {
+		if hasPathPrefix(change.Path, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// upgradeHook returns the upgrade hook a resource is run as, or an empty string
+func upgradeHook(object map[string]interface{}) string {
+	metadata, _ := object["metadata"].(map[string]interface{})
+	annotations, _ := metadata["annotations"].(map[string]interface{})
+	hooks, _ := annotations["helm.sh/hook"].(string)
+	for _, hook := range strings.Split(hooks, ",") {
+		for _, upgradeHook := range upgradeHooks {
+			if strings.TrimSpace(hook) == upgradeHook {
+				return upgradeHook
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,116 @@
+// Command pega-upgrade-diff shows what a helm upgrade changes before it is run. It renders the chart as deployed and as
+// upgraded, compares the objects field by field and flags the changes that roll the Pega tiers, require a StatefulSet
+// to be recreated or run installer Jobs again.
+//
+//	go run ./upgradediff/pega-upgrade-diff -from-values current.yaml -to-values next.yaml
+//	go run ./upgradediff/pega-upgrade-diff -from-chart pega-3.22.0.tgz -from-values prod.yaml
+//
+// The exit code is 0 when the diff ran, 1 when -fail-on-impact is set and the upgrade has an impact, and 2 when the
+// diff could not run.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/upgradediff"
+)
+
+// stringList collects a flag given more than once
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func main() {
+	var fromValues, toValues, setValues, toSetValues stringList
+	flag.Var(&fromValues, "from-values", "values file of the deployed release, may be repeated")
+	flag.Var(&toValues, "to-values", "values file of the upgrade, may be repeated; defaults to the -from-values files")
+	flag.Var(&setValues, "set", "key=value applied to both renders after the values files, may be repeated")
+	flag.Var(&toSetValues, "to-set", "key=value applied to the upgrade only, after -set, may be repeated")
+	fromChart := flag.String("from-chart", "", "chart directory or packaged chart of the deployed release, defaults to charts/pega of this repository")
+	toChart := flag.String("to-chart", "", "chart directory or packaged chart of the upgrade, defaults to -from-chart")
+	releaseName := flag.String("release", "pega", "release name")
+	namespace := flag.String("namespace", "", "namespace of the release")
+	kubeVersion := flag.String("kube-version", "", "Kubernetes version reported to the templates")
+	format := flag.String("format", "text", "output format, text or json")
+	outPath := flag.String("out", "", "write the diff to this file instead of stdout")
+	failOnImpact := flag.Bool("fail-on-impact", false, "exit with 1 when the upgrade restarts pods, recreates objects or runs Jobs")
+	flag.Parse()
+	if *format != "text" && *format != "json" {
+		flag.Usage()
+		fmt.Fprintln(os.Stderr, "-format must be text or json")
+		os.Exit(2)
+	}
+
+	fromSet, err := parseSet(setValues)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	toSet, err := parseSet(append(append(stringList{}, setValues...), toSetValues...))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if len(toValues) == 0 {
+		toValues = fromValues
+	}
+	if *toChart == "" {
+		*toChart = *fromChart
+	}
+
+	report, err := upgradediff.Diff(upgradediff.Options{
+		Before:      upgradediff.Side{ChartPath: *fromChart, ValuesFiles: fromValues, SetValues: fromSet},
+		After:       upgradediff.Side{ChartPath: *toChart, ValuesFiles: toValues, SetValues: toSet},
+		ReleaseName: *releaseName,
+		Namespace:   *namespace,
+		KubeVersion: *kubeVersion,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	content := []byte(upgradediff.Describe(report))
+	if *format == "json" {
+		if content, err = json.MarshalIndent(report, "", "  "); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		content = append(content, '\n')
+	}
+	if *outPath == "" {
+		os.Stdout.Write(content)
+	} else if err := ioutil.WriteFile(*outPath, content, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	impacts := report.Impacts()
+	fmt.Fprintf(os.Stderr, "%d object(s) differ, %d impact(s)\n", len(report.Resources), len(impacts))
+	if *failOnImpact && len(impacts) > 0 {
+		os.Exit(1)
+	}
+}
+
+// parseSet turns key=value flags into helm --set values, later flags overriding earlier ones
+func parseSet(values stringList) (map[string]string, error) {
+	set := map[string]string{}
+	for _, value := range values {
+		parts := strings.SplitN(value, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%s is not in the key=value form", value)
+		}
+		set[parts[0]] = parts[1]
+	}
+	return set, nil
+}
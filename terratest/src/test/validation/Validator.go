@@ -0,0 +1,254 @@
+package validation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// ValidationError is a single schema violation in a rendered resource
+type ValidationError struct {
+	// Resource identifies the object as Kind/name, followed by the template that rendered it
+	Resource string
+	// Path is the dot separated location of the offending field, e.g. spec.template.spec.containers[0].ports
+	Path    string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("%s: %s", e.Resource, e.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", e.Resource, e.Path, e.Message)
+}
+
+// Validator checks rendered resources against the OpenAPI schema of a Kubernetes version without contacting a cluster.
+// It reports unknown fields, values of the wrong type, missing required fields and kinds the version does not serve.
+type Validator struct {
+	// KubeVersion is the version the validator was created for
+	KubeVersion string
+	// SchemaVersion is the major.minor version of the bundled schema used for KubeVersion
+	SchemaVersion string
+	bundle        *schemaBundle
+}
+
+// NewValidator loads the bundled schema closest to kubeVersion, see bundleVersionFor. The custom resources rendered by
+// the charts (Traefik ServersTransport, GKE BackendConfig and ManagedCertificate, OpenShift Route, Prometheus Operator
+// ServiceMonitor, KEDA ScaledObject, VerticalPodAutoscaler and the standard channel Gateway API HTTPRoute) are
+// validated against the same CRD schemas for every version.
+func NewValidator(kubeVersion string) (*Validator, error) {
+	bundleVersion, err := bundleVersionFor(kubeVersion)
+	if err != nil {
+		return nil, err
+	}
+	bundle, err := loadBundle(bundleVersion)
+	if err != nil {
+		return nil, err
+	}
+	return &Validator{KubeVersion: kubeVersion, SchemaVersion: bundleVersion, bundle: bundle}, nil
+}
+
+// Validate checks a single rendered resource
+func (v *Validator) Validate(resource helmtest.Resource) []ValidationError {
+	check := &checker{bundle: v.bundle, resource: resourceName(resource)}
+	jsonData, err := yaml.ToJSON([]byte(resource.YAML))
+	if err != nil {
+		check.fail("", "invalid YAML: %v", err)
+		return check.errors
+	}
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		check.fail("", "invalid JSON: %v", err)
+		return check.errors
+	}
+
+	gvk := groupVersionKind{Kind: resource.Kind, Version: resource.APIVersion}
+	if slash := strings.LastIndex(resource.APIVersion, "/"); slash >= 0 {
+		gvk.Group, gvk.Version = resource.APIVersion[:slash], resource.APIVersion[slash+1:]
+	}
+	definition, ok := v.bundle.kinds[gvk]
+	if !ok {
+		check.fail("", "%s %s is not served by Kubernetes %s", resource.APIVersion, resource.Kind, v.SchemaVersion)
+		return check.errors
+	}
+	check.validate("", document, &schema{Ref: refPrefix + definition})
+	return check.errors
+}
+
+// ValidateAll checks every resource, returning the violations in rendering order
+func (v *Validator) ValidateAll(resources []helmtest.Resource) []ValidationError {
+	var errors []ValidationError
+	for _, resource := range resources {
+		errors = append(errors, v.Validate(resource)...)
+	}
+	return errors
+}
+
+// AssertValid validates every resource held by parser against kubeVersion, reporting each violation as a test error
+func AssertValid(t *testing.T, parser *helmtest.HelmChartParser, kubeVersion string) {
+	validator, err := NewValidator(kubeVersion)
+	require.NoError(t, err)
+	for _, validationError := range validator.ValidateAll(parser.Query(helmtest.ResourceQuery{})) {
+		t.Errorf("schema validation against Kubernetes %s: %s", validator.SchemaVersion, validationError)
+	}
+}
+
+func resourceName(resource helmtest.Resource) string {
+	name := resource.Kind + "/" + resource.Name
+	if resource.Source != "" {
+		name += " (" + resource.Source + ")"
+	}
+	return name
+}
+
+type checker struct {
+	bundle   *schemaBundle
+	resource string
+	errors   []ValidationError
+}
+
+func (c *checker) fail(path string, format string, args ...interface{}) {
+	c.errors = append(c.errors, ValidationError{Resource: c.resource, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *checker) validate(path string, value interface{}, s *schema) {
+	quantity := false
+	for s.Ref != "" {
+		name := strings.TrimPrefix(s.Ref, refPrefix)
+		quantity = strings.HasSuffix(name, quantitySuffix)
+		resolved, ok := c.bundle.definitions[name]
+		if !ok {
+			c.fail(path, "schema references unknown definition %s", name)
+			return
+		}
+		s = resolved
+	}
+	// the API server treats an explicit null like an omitted field
+	if value == nil {
+		return
+	}
+
+	switch {
+	case s.Format == "int-or-string":
+		if _, isString := value.(string); !isString && !isInteger(value) {
+			c.fail(path, "expected an integer or a string, got %s", describe(value))
+		}
+		return
+	case quantity:
+		if _, isString := value.(string); !isString && !isNumber(value) {
+			c.fail(path, "expected a quantity, got %s", describe(value))
+		}
+		return
+	}
+
+	switch s.Type {
+	case "string":
+		if _, ok := value.(string); !ok {
+			c.fail(path, "expected a string, got %s", describe(value))
+		}
+	case "integer":
+		if !isInteger(value) {
+			c.fail(path, "expected an integer, got %s", describe(value))
+		}
+	case "number":
+		if !isNumber(value) {
+			c.fail(path, "expected a number, got %s", describe(value))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			c.fail(path, "expected a boolean, got %s", describe(value))
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			c.fail(path, "expected an array, got %s", describe(value))
+			return
+		}
+		if s.Items != nil {
+			for i, item := range items {
+				c.validate(fmt.Sprintf("%s[%d]", path, i), item, s.Items)
+			}
+		}
+	case "object", "":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			if s.Type == "object" {
+				c.fail(path, "expected an object, got %s", describe(value))
+			}
+			return
+		}
+		c.validateObject(path, object, s)
+	}
+}
+
+func (c *checker) validateObject(path string, object map[string]interface{}, s *schema) {
+	for _, field := range s.Required {
+		if _, ok := object[field]; !ok {
+			c.fail(path, "missing required field %q", field)
+		}
+	}
+
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fieldPath := key
+		if path != "" {
+			fieldPath = path + "." + key
+		}
+		if property, ok := s.Properties[key]; ok {
+			c.validate(fieldPath, object[key], property)
+			continue
+		}
+		switch {
+		case s.AdditionalProperties != nil:
+			c.validate(fieldPath, object[key], s.AdditionalProperties)
+		case s.PreserveUnknownFields || len(s.Properties) == 0:
+			// free-form objects such as RawExtension accept anything
+		default:
+			c.fail(path, "unknown field %q", key)
+		}
+	}
+}
+
+func isNumber(value interface{}) bool {
+	_, ok := value.(json.Number)
+	return ok
+}
+
+func isInteger(value interface{}) bool {
+	number, ok := value.(json.Number)
+	if !ok {
+		return false
+	}
+	_, err := number.Int64()
+	return err == nil
+}
+
+func describe(value interface{}) string {
+	switch typed := value.(type) {
+	case string:
+		return fmt.Sprintf("string %q", typed)
+	case json.Number:
+		return "number " + typed.String()
+	case bool:
+		return fmt.Sprintf("boolean %t", typed)
+	case []interface{}:
+		return "an array"
+	case map[string]interface{}:
+		return "an object"
+	default:
+		return fmt.Sprintf("%v", typed)
+	}
+}
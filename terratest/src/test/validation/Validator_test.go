@@ -0,0 +1,265 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+	"github.com/stretchr/testify/require"
+)
+
+const validDeployment = `
+# Source: pega/templates/pega-tier-deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: pega-web
+  annotations:
+    config-check: "abc"
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      app: pega-web
+  template:
+    metadata:
+      labels:
+        app: pega-web
+    spec:
+      containers:
+      - name: pega-web-tomcat
+        image: pegasystems/pega:8.8.0
+        ports:
+        - containerPort: 8080
+          name: pega-web-port
+        resources:
+          requests:
+            cpu: 2
+            memory: 6Gi
+        readinessProbe:
+          httpGet:
+            path: /prweb/PRRestService/monitor/pingService/ping
+            port: 8080
+        livenessProbe:
+          httpGet:
+            path: /prweb/PRRestService/monitor/pingService/ping
+            port: pega-web-port
+          initialDelaySeconds: null
+`
+
+func validate(t *testing.T, kubeVersion string, manifests string) []ValidationError {
+	validator, err := NewValidator(kubeVersion)
+	require.NoError(t, err)
+	parser := helmtest.NewHelmChartParser(t, manifests, "default")
+	return validator.ValidateAll(parser.Query(helmtest.ResourceQuery{}))
+}
+
+func TestValidDeploymentHasNoErrors(t *testing.T) {
+	for _, kubeVersion := range helmtest.ValidatedKubeVersions {
+		require.Empty(t, validate(t, kubeVersion, validDeployment), kubeVersion)
+	}
+}
+
+func TestUnknownField(t *testing.T) {
+	manifest := strings.Replace(validDeployment, "        image: pegasystems/pega:8.8.0\n",
+		"        image: pegasystems/pega:8.8.0\n        imagePullPolicy: Always\n        imagePulPolicy: Always\n", 1)
+	errors := validate(t, "1.29.0", manifest)
+	require.Len(t, errors, 1)
+	require.Equal(t, "Deployment/pega-web (pega/templates/pega-tier-deployment.yaml)", errors[0].Resource)
+	require.Equal(t, "spec.template.spec.containers[0]", errors[0].Path)
+	require.Equal(t, `unknown field "imagePulPolicy"`, errors[0].Message)
+}
+
+func TestWrongType(t *testing.T) {
+	manifest := strings.Replace(validDeployment, "replicas: 1", `replicas: "1"`, 1)
+	manifest = strings.Replace(manifest, "name: pega-web-port", "name: 8080", 1)
+	errors := validate(t, "1.29.0", manifest)
+	require.Equal(t, []ValidationError{
+		{Resource: "Deployment/pega-web (pega/templates/pega-tier-deployment.yaml)", Path: "spec.replicas", Message: `expected an integer, got string "1"`},
+		{Resource: "Deployment/pega-web (pega/templates/pega-tier-deployment.yaml)", Path: "spec.template.spec.containers[0].ports[0].name", Message: "expected a string, got number 8080"},
+	}, errors)
+}
+
+func TestMissingRequiredField(t *testing.T) {
+	manifest := strings.Replace(validDeployment, "      - name: pega-web-tomcat\n        image", "      - image", 1)
+	errors := validate(t, "1.29.0", manifest)
+	require.Len(t, errors, 1)
+	require.Equal(t, "spec.template.spec.containers[0]", errors[0].Path)
+	require.Equal(t, `missing required field "name"`, errors[0].Message)
+}
+
+func TestIntOrStringAndQuantity(t *testing.T) {
+	manifest := strings.Replace(validDeployment, "port: pega-web-port", "port: true", 1)
+	manifest = strings.Replace(manifest, "memory: 6Gi", "memory: [6Gi]", 1)
+	errors := validate(t, "1.29.0", manifest)
+	require.Len(t, errors, 2)
+	require.Equal(t, "spec.template.spec.containers[0].livenessProbe.httpGet.port", errors[0].Path)
+	require.Equal(t, "expected an integer or a string, got boolean true", errors[0].Message)
+	require.Equal(t, "spec.template.spec.containers[0].resources.requests.memory", errors[1].Path)
+	require.Equal(t, "expected a quantity, got an array", errors[1].Message)
+}
+
+func TestKindNotServedByVersion(t *testing.T) {
+	hpa := `
+apiVersion: autoscaling/v2beta2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: pega-web-hpa
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: pega-web
+  maxReplicas: 5
+`
+	require.Empty(t, validate(t, "1.25.0", hpa))
+	errors := validate(t, "1.27.0", hpa)
+	require.Len(t, errors, 1)
+	require.Equal(t, "autoscaling/v2beta2 HorizontalPodAutoscaler is not served by Kubernetes 1.27", errors[0].Message)
+}
+
+func TestCustomResources(t *testing.T) {
+	manifests := `
+apiVersion: cloud.google.com/v1
+kind: BackendConfig
+metadata:
+  name: pega-web
+spec:
+  timeoutSec: 40
+  sessionAffinity:
+    affinityType: GENERATED_COOKIE
+    affinityCookieTtlSec: 3600
+  healthCheck:
+    port: "8080"
+---
+apiVersion: networking.gke.io/v1
+kind: ManagedCertificate
+metadata:
+  name: pega-web
+spec:
+  domain: web.example.com
+---
+apiVersion: traefik.containo.us/v1alpha1
+kind: ServersTransport
+metadata:
+  name: pega-web-servers-transport
+spec:
+  insecureSkipVerify: false
+  rootCAsSecrets:
+  - pega-web-tomcat-keystore-secret
+  serverName: www.pega.com
+`
+	errors := validate(t, "1.21.0", manifests)
+	require.Equal(t, []ValidationError{
+		{Resource: "BackendConfig/pega-web", Path: "spec.healthCheck.port", Message: `expected an integer, got string "8080"`},
+		{Resource: "ManagedCertificate/pega-web", Path: "spec", Message: `missing required field "domains"`},
+		{Resource: "ManagedCertificate/pega-web", Path: "spec", Message: `unknown field "domain"`},
+	}, errors)
+}
+
+func TestServiceMonitorCustomResource(t *testing.T) {
+	manifests := `
+apiVersion: monitoring.coreos.com/v1
+kind: ServiceMonitor
+metadata:
+  name: pega-web
+spec:
+  selector:
+    matchLabels:
+      app: pega-web
+  endpoints:
+  - port: metrics
+    interval: 30
+    relabelings:
+    - sourceLabels: [__meta_kubernetes_pod_node_name]
+      targetLabel: node
+`
+	require.Equal(t, []ValidationError{
+		{Resource: "ServiceMonitor/pega-web", Path: "spec.endpoints[0].interval", Message: "expected a string, got number 30"},
+	}, validate(t, "1.29.0", manifests))
+}
+
+func TestScaledObjectCustomResource(t *testing.T) {
+	manifests := `
+apiVersion: keda.sh/v1alpha1
+kind: ScaledObject
+metadata:
+  name: pega-batch
+spec:
+  scaleTargetRef:
+    name: pega-batch
+  maxReplicaCount: 8
+  triggers:
+  - type: prometheus
+    metadata:
+      threshold: 100
+`
+	require.Equal(t, []ValidationError{
+		{Resource: "ScaledObject/pega-batch", Path: "spec.triggers[0].metadata.threshold", Message: "expected a string, got number 100"},
+	}, validate(t, "1.29.0", manifests))
+}
+
+func TestVerticalPodAutoscalerCustomResource(t *testing.T) {
+	manifests := `
+apiVersion: autoscaling.k8s.io/v1
+kind: VerticalPodAutoscaler
+metadata:
+  name: pega-web-vpa
+spec:
+  targetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: pega-web
+  updatePolicy:
+    updateMode: "Off"
+    minReplicas: "2"
+  resourcePolicy:
+    containerPolicies:
+    - containerName: pega-web-tomcat
+      minAllowed:
+        cpu: 1
+        memory: 4Gi
+`
+	require.Equal(t, []ValidationError{
+		{Resource: "VerticalPodAutoscaler/pega-web-vpa", Path: "spec.updatePolicy.minReplicas", Message: `expected an integer, got string "2"`},
+	}, validate(t, "1.29.0", manifests))
+}
+
+func TestHTTPRouteCustomResource(t *testing.T) {
+	manifests := `
+apiVersion: gateway.networking.k8s.io/v1
+kind: HTTPRoute
+metadata:
+  name: pega-web
+spec:
+  parentRefs:
+  - name: pega-gateway
+  rules:
+  - backendRefs:
+    - name: pega-web
+      port: 80
+    sessionPersistence:
+      type: Cookie
+`
+	require.Equal(t, []ValidationError{
+		{Resource: "HTTPRoute/pega-web", Path: "spec.rules[0]", Message: `unknown field "sessionPersistence"`},
+	}, validate(t, "1.29.0", manifests))
+}
+
+func TestBundleVersionFor(t *testing.T) {
+	for kubeVersion, expected := range map[string]string{
+		"1.19.0":           "1.19",
+		"1.20.4":           "1.19",
+		"v1.24.3-gke.1000": "1.23",
+		"1.29.0":           "1.29",
+		"1.31":             "1.29",
+	} {
+		actual, err := bundleVersionFor(kubeVersion)
+		require.NoError(t, err)
+		require.Equal(t, expected, actual, kubeVersion)
+	}
+	for _, kubeVersion := range []string{"latest", "1.15.0", "1.18.0"} {
+		_, err := bundleVersionFor(kubeVersion)
+		require.Error(t, err, kubeVersion)
+	}
+}
@@ -0,0 +1,107 @@
+// Command gen trims the OpenAPI (swagger 2.0) document published with a Kubernetes release down to the API groups
+// rendered by the charts in this repository, for bundling under validation/schemas.
+//
+//	go run ./validation/gen -swagger kubernetes/api/openapi-spec/swagger.json -out validation/schemas/k8s-1.29.json
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"log"
+	"strings"
+)
+
+// groups whose kinds are kept, "" being the core group
+var groups = map[string]bool{
+	"":                          true,
+	"apps":                      true,
+	"autoscaling":               true,
+	"batch":                     true,
+	"extensions":                true,
+	"networking.k8s.io":         true,
+	"policy":                    true,
+	"rbac.authorization.k8s.io": true,
+}
+
+const refPrefix = "#/definitions/"
+
+func main() {
+	swaggerPath := flag.String("swagger", "", "path of the swagger.json published in kubernetes/api/openapi-spec")
+	outPath := flag.String("out", "", "path of the trimmed schema to write")
+	flag.Parse()
+	if *swaggerPath == "" || *outPath == "" {
+		flag.Usage()
+		log.Fatal("-swagger and -out are required")
+	}
+
+	content, err := ioutil.ReadFile(*swaggerPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var swagger struct {
+		Definitions map[string]map[string]interface{} `json:"definitions"`
+	}
+	if err := json.Unmarshal(content, &swagger); err != nil {
+		log.Fatal(err)
+	}
+
+	kept := map[string]interface{}{}
+	var queue []string
+	for name, definition := range swagger.Definitions {
+		gvks, _ := definition["x-kubernetes-group-version-kind"].([]interface{})
+		for _, gvk := range gvks {
+			group, _ := gvk.(map[string]interface{})["group"].(string)
+			if groups[group] && !strings.HasSuffix(name, "List") {
+				queue = append(queue, name)
+				break
+			}
+		}
+	}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if _, done := kept[name]; done {
+			continue
+		}
+		definition, ok := swagger.Definitions[name]
+		if !ok {
+			log.Fatalf("unresolved reference %s", name)
+		}
+		kept[name] = strip(definition, &queue)
+	}
+
+	out, err := json.MarshalIndent(map[string]interface{}{"definitions": kept}, "", " ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile(*outPath, append(out, '\n'), 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// strip drops descriptions, which make up most of the document, and queues every referenced definition
+func strip(node interface{}, queue *[]string) interface{} {
+	switch value := node.(type) {
+	case map[string]interface{}:
+		result := map[string]interface{}{}
+		for key, child := range value {
+			if key == "description" {
+				continue
+			}
+			if ref, ok := child.(string); ok && key == "$ref" {
+				*queue = append(*queue, strings.TrimPrefix(ref, refPrefix))
+			}
+			result[key] = strip(child, queue)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(value))
+		for i, child := range value {
+			result[i] = strip(child, queue)
+		}
+		return result
+	default:
+		return value
+	}
+}
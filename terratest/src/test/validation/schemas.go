@@ -0,0 +1,144 @@
+package validation
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// The k8s-<major>.<minor>.json files are generated by ./gen from the swagger.json published with each Kubernetes
+// release. crds.json is maintained by hand from the upstream CRDs of the custom resources the charts render.
+//
+//go:embed schemas/*.json
+var schemaFiles embed.FS
+
+const (
+	refPrefix      = "#/definitions/"
+	crdSchemaFile  = "schemas/crds.json"
+	quantitySuffix = ".api.resource.Quantity"
+)
+
+type groupVersionKind struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+}
+
+// schema is the subset of a swagger 2.0 schema object used by the Kubernetes OpenAPI documents
+type schema struct {
+	Type                  string             `json:"type"`
+	Format                string             `json:"format"`
+	Ref                   string             `json:"$ref"`
+	Properties            map[string]*schema `json:"properties"`
+	AdditionalProperties  *schema            `json:"additionalProperties"`
+	Items                 *schema            `json:"items"`
+	Required              []string           `json:"required"`
+	PreserveUnknownFields bool               `json:"x-kubernetes-preserve-unknown-fields"`
+	GroupVersionKinds     []groupVersionKind `json:"x-kubernetes-group-version-kind"`
+}
+
+// schemaBundle is the set of definitions for one Kubernetes version merged with the CRD definitions
+type schemaBundle struct {
+	kubeVersion string
+	definitions map[string]*schema
+	kinds       map[groupVersionKind]string
+}
+
+var (
+	bundleMutex sync.Mutex
+	bundles     = map[string]*schemaBundle{}
+)
+
+// bundledKubeVersions lists the major.minor versions with a bundled schema, oldest first
+func bundledKubeVersions() ([]string, error) {
+	entries, err := schemaFiles.ReadDir("schemas")
+	if err != nil {
+		return nil, err
+	}
+	var versions []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, "k8s-") && strings.HasSuffix(name, ".json") {
+			versions = append(versions, strings.TrimSuffix(strings.TrimPrefix(name, "k8s-"), ".json"))
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool { return minorOf(versions[i]) < minorOf(versions[j]) })
+	return versions, nil
+}
+
+// bundleVersionFor picks the newest bundled schema that is not newer than kubeVersion. Versions older than every
+// bundle are refused, as the oldest bundle serves APIs that those versions do not.
+func bundleVersionFor(kubeVersion string) (string, error) {
+	versions, err := bundledKubeVersions()
+	if err != nil {
+		return "", err
+	}
+	if len(versions) == 0 {
+		return "", fmt.Errorf("no Kubernetes schemas are bundled")
+	}
+	requested := minorOf(kubeVersion)
+	if requested < 0 {
+		return "", fmt.Errorf("invalid Kubernetes version %q", kubeVersion)
+	}
+	if requested < minorOf(versions[0]) {
+		return "", fmt.Errorf("no Kubernetes schema is bundled for %s, the oldest bundled version is %s", kubeVersion, versions[0])
+	}
+	chosen := versions[0]
+	for _, version := range versions {
+		if minorOf(version) <= requested {
+			chosen = version
+		}
+	}
+	return chosen, nil
+}
+
+// minorOf returns the minor version of a 1.x version such as "1.23", "1.23.0" or "v1.23.4-gke.100", or -1
+func minorOf(kubeVersion string) int {
+	parts := strings.SplitN(strings.TrimPrefix(kubeVersion, "v"), ".", 3)
+	if len(parts) < 2 || parts[0] != "1" {
+		return -1
+	}
+	minor, err := strconv.Atoi(strings.SplitN(parts[1], "-", 2)[0])
+	if err != nil {
+		return -1
+	}
+	return minor
+}
+
+func loadBundle(bundleVersion string) (*schemaBundle, error) {
+	bundleMutex.Lock()
+	defer bundleMutex.Unlock()
+	if bundle, ok := bundles[bundleVersion]; ok {
+		return bundle, nil
+	}
+
+	bundle := &schemaBundle{
+		kubeVersion: bundleVersion,
+		definitions: map[string]*schema{},
+		kinds:       map[groupVersionKind]string{},
+	}
+	for _, file := range []string{"schemas/k8s-" + bundleVersion + ".json", crdSchemaFile} {
+		content, err := schemaFiles.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var document struct {
+			Definitions map[string]*schema `json:"definitions"`
+		}
+		if err := json.Unmarshal(content, &document); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", file, err)
+		}
+		for name, definition := range document.Definitions {
+			bundle.definitions[name] = definition
+			for _, gvk := range definition.GroupVersionKinds {
+				bundle.kinds[gvk] = name
+			}
+		}
+	}
+	bundles[bundleVersion] = bundle
+	return bundle, nil
+}
@@ -0,0 +1,234 @@
+package valueslint
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+	"sigs.k8s.io/yaml"
+)
+
+// Severity of a Finding. Only errors make a values file fail the lint.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Finding is a single problem in a values file, located either by the values path that causes it or by the rendered
+// resource it shows up in
+type Finding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	// Actions lists the actions the problem occurs for, empty when it does not depend on the action
+	Actions []string `json:"actions,omitempty"`
+	// Resource identifies a rendered object as Kind/name
+	Resource string `json:"resource,omitempty"`
+	// Path is the dot separated location in the values file, or in Resource when that is set
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+// Report is the machine readable result of linting one values file
+type Report struct {
+	ValuesFile string    `json:"valuesFile"`
+	Chart      string    `json:"chart"`
+	Actions    []string  `json:"actions"`
+	Passed     bool      `json:"passed"`
+	Findings   []Finding `json:"findings"`
+}
+
+// Errors counts the findings with SeverityError
+func (r *Report) Errors() int {
+	errors := 0
+	for _, finding := range r.Findings {
+		if finding.Severity == SeverityError {
+			errors++
+		}
+	}
+	return errors
+}
+
+// Options control a lint run
+type Options struct {
+	// ChartPath defaults to the pega chart of this repository
+	ChartPath  string
+	ValuesFile string
+	// Actions overrides the actions the values file is rendered for, see CandidateActions
+	Actions     []string
+	KubeVersion string
+}
+
+// Lint checks the values file on its own, then renders the chart with it for every candidate action and checks the
+// rendered resources. An error is only returned when the lint itself could not run; problems in the values file,
+// including a chart that does not render, are reported as findings.
+func Lint(options Options) (*Report, error) {
+	chartPath := options.ChartPath
+	if chartPath == "" {
+		chartPath = helmtest.ChartPath(helmtest.PegaChart)
+	}
+	values, err := readValues(options.ValuesFile)
+	if err != nil {
+		return nil, err
+	}
+	chartValues, err := readValues(filepath.Join(chartPath, "values.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	actions := options.Actions
+	if len(actions) == 0 {
+		actions = CandidateActions(values, chartValues)
+	}
+
+	lint := &linter{chartPath: chartPath}
+	for _, rule := range valuesRules {
+		rule(lint, values, chartValues)
+	}
+	for _, action := range actions {
+		parser, renderErr := render(chartPath, options.ValuesFile, action, options.KubeVersion)
+		if renderErr != nil {
+			lint.report(Finding{Rule: "render", Severity: SeverityError, Actions: []string{action}, Message: renderErr.Error()})
+			continue
+		}
+		resources, err := parser.QueryE(helmtest.ResourceQuery{})
+		if err != nil {
+			return nil, err
+		}
+		for _, resource := range resources {
+			object := map[string]interface{}{}
+			if err := yaml.Unmarshal([]byte(resource.YAML), &object); err != nil {
+				return nil, fmt.Errorf("decoding rendered %s/%s: %w", resource.Kind, resource.Name, err)
+			}
+			for _, rule := range resourceRules {
+				rule(lint, action, resource, object)
+			}
+		}
+	}
+
+	return &Report{
+		ValuesFile: options.ValuesFile,
+		Chart:      chartPath,
+		Actions:    actions,
+		Passed:     lint.errors() == 0,
+		Findings:   lint.sortedFindings(),
+	}, nil
+}
+
+// CandidateActions returns the actions a values file can be deployed with: the action it sets in
+// global.actions.execute or, when it leaves that to the command line, every action. Of the two upgrade actions only
+// the ones accepting installer.upgrade.upgradeType are kept, matching pega-installer-action-validate.yaml.
+func CandidateActions(values map[string]interface{}, chartValues map[string]interface{}) []string {
+	if action, ok := lookup(values, "global.actions.execute").(string); ok && action != "" {
+		return []string{action}
+	}
+	upgradeType, ok := lookup(values, "installer.upgrade.upgradeType").(string)
+	if !ok {
+		upgradeType, _ = lookup(chartValues, "installer.upgrade.upgradeType").(string)
+	}
+	var actions []string
+	for _, action := range helmtest.SupportedActions {
+		switch action {
+		case "upgrade":
+			if !contains([]string{"in-place", "out-of-place", "custom", "out-of-place-rules", "out-of-place-data"}, upgradeType) {
+				continue
+			}
+		case "upgrade-deploy":
+			if !contains([]string{"zero-downtime", "out-of-place", "custom"}, upgradeType) {
+				continue
+			}
+		}
+		actions = append(actions, action)
+	}
+	return actions
+}
+
+// linter collects findings, merging the ones that only differ in the action they were found for
+type linter struct {
+	chartPath string
+	findings  []Finding
+}
+
+func (l *linter) report(finding Finding) {
+	for i, existing := range l.findings {
+		if existing.Rule == finding.Rule && existing.Resource == finding.Resource && existing.Path == finding.Path &&
+			existing.Message == finding.Message {
+			for _, action := range finding.Actions {
+				if !contains(existing.Actions, action) {
+					l.findings[i].Actions = append(l.findings[i].Actions, action)
+				}
+			}
+			return
+		}
+	}
+	l.findings = append(l.findings, finding)
+}
+
+func (l *linter) errors() int {
+	return (&Report{Findings: l.findings}).Errors()
+}
+
+// sortedFindings orders findings by rule, then by location, keeping the report stable between runs
+func (l *linter) sortedFindings() []Finding {
+	findings := append([]Finding{}, l.findings...)
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Rule != findings[j].Rule {
+			return findings[i].Rule < findings[j].Rule
+		}
+		if findings[i].Resource != findings[j].Resource {
+			return findings[i].Resource < findings[j].Resource
+		}
+		return findings[i].Path < findings[j].Path
+	})
+	return findings
+}
+
+func render(chartPath string, valuesFile string, action string, kubeVersion string) (*helmtest.HelmChartParser, error) {
+	output, err := helmtest.CommandRender{
+		ChartPath:   chartPath,
+		KubeVersion: kubeVersion,
+		ValuesFiles: []string{valuesFile},
+		SetValues:   map[string]string{"global.actions.execute": action},
+	}.RenderE()
+	if err != nil {
+		return nil, err
+	}
+	return helmtest.NewHelmChartParserE(nil, output, "default")
+}
+
+func readValues(path string) (map[string]interface{}, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]interface{}{}
+	if err := yaml.Unmarshal(content, &values); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// lookup returns the value at a dot separated path of map keys, or nil when any part of the path is missing
+func lookup(values map[string]interface{}, path string) interface{} {
+	var current interface{} = values
+	for _, key := range strings.Split(path, ".") {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = object[key]
+	}
+	return current
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package valueslint
+
+import (
+	"testing"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+	"github.com/stretchr/testify/require"
+)
+
+func findingsOf(report *Report, rule string) []Finding {
+	var findings []Finding
+	for _, finding := range report.Findings {
+		if finding.Rule == rule {
+			findings = append(findings, finding)
+		}
+	}
+	return findings
+}
+
+func TestLintPassesCompleteValues(t *testing.T) {
+	t.Parallel()
+	report, err := Lint(Options{ValuesFile: "data/values_lint_clean.yaml"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"install-deploy"}, report.Actions)
+	require.Empty(t, report.Findings)
+	require.True(t, report.Passed)
+}
+
+func TestLintReportsMisconfigurations(t *testing.T) {
+	t.Parallel()
+	report, err := Lint(Options{ValuesFile: "data/values_lint.yaml"})
+	require.NoError(t, err)
+	require.False(t, report.Passed)
+	require.Equal(t, []string{"install", "deploy", "install-deploy", "upgrade-deploy"}, report.Actions)
+
+	placeholders := findingsOf(report, "placeholder")
+	require.Contains(t, placeholders, Finding{
+		Rule:     "placeholder",
+		Severity: SeverityError,
+		Path:     "global.jdbc.password",
+		Message:  "still set to the placeholder YOUR_JDBC_PASSWORD",
+	})
+	require.Contains(t, placeholders, Finding{
+		Rule:     "placeholder",
+		Severity: SeverityError,
+		Actions:  []string{"install", "install-deploy"},
+		Resource: "Job/pega-db-install",
+		Path:     "spec.template.spec.containers[0].image",
+		Message:  "rendered with the placeholder YOUR_INSTALLER_IMAGE:TAG from the chart defaults",
+	})
+	for _, finding := range placeholders {
+		// the JDBC password is reported once, where the values file sets it, not again in the rendered secret
+		require.NotContains(t, finding.Message, "YOUR_JDBC_PASSWORD from the chart defaults")
+	}
+
+	require.Equal(t, []Finding{{
+		Rule:     "tier-resources",
+		Severity: SeverityError,
+		Path:     "global.tier[0].resources",
+		Message:  "tier web does not set resources, its pods request 3 CPU and 12Gi of memory and are limited to 4 CPU and 12Gi",
+	}}, findingsOf(report, "tier-resources"))
+
+	keystore := findingsOf(report, "tls-keystore")
+	require.Len(t, keystore, 1)
+	require.Equal(t, "Secret/pega-tomcat-keystore-secret", keystore[0].Resource)
+	require.Equal(t, helmtest.SupportedDeployActions, keystore[0].Actions)
+
+	hpa := findingsOf(report, "hpa-replicas")
+	require.Len(t, hpa, 1)
+	require.Equal(t, "minReplicas 5 is greater than maxReplicas 2", hpa[0].Message)
+
+	ingress := findingsOf(report, "ingress-host")
+	require.Len(t, ingress, 1)
+	require.Equal(t, SeverityWarning, ingress[0].Severity)
+	require.Equal(t, "Ingress/pega-web", ingress[0].Resource)
+}
+
+func TestLintReportsRenderFailures(t *testing.T) {
+	t.Parallel()
+	report, err := Lint(Options{ValuesFile: "../../../../charts/pega/values.yaml", Actions: []string{"deploy", "install"}})
+	require.NoError(t, err)
+	require.False(t, report.Passed)
+	render := findingsOf(report, "render")
+	require.Len(t, render, 1)
+	require.Equal(t, []string{"deploy", "install"}, render[0].Actions)
+	require.Regexp(t, helmtest.SchemaError("global.provider"), render[0].Message)
+	require.Contains(t, report.Findings, Finding{
+		Rule:     "placeholder",
+		Severity: SeverityError,
+		Path:     "global.jdbc.url",
+		Message:  "still set to the placeholder YOUR_JDBC_URL",
+	})
+}
+
+func TestCandidateActions(t *testing.T) {
+	chartValues := map[string]interface{}{
+		"installer": map[string]interface{}{"upgrade": map[string]interface{}{"upgradeType": "in-place"}},
+	}
+	require.Equal(t, []string{"install", "upgrade", "deploy", "install-deploy"}, CandidateActions(map[string]interface{}{}, chartValues))
+	require.Equal(t, []string{"install", "deploy", "install-deploy", "upgrade-deploy"}, CandidateActions(map[string]interface{}{
+		"installer": map[string]interface{}{"upgrade": map[string]interface{}{"upgradeType": "zero-downtime"}},
+	}, chartValues))
+	require.Equal(t, []string{"install", "upgrade", "deploy", "install-deploy", "upgrade-deploy"}, CandidateActions(map[string]interface{}{
+		"installer": map[string]interface{}{"upgrade": map[string]interface{}{"upgradeType": "custom"}},
+	}, chartValues))
+	require.Equal(t, []string{"upgrade"}, CandidateActions(map[string]interface{}{
+		"global": map[string]interface{}{"actions": map[string]interface{}{"execute": "upgrade"}},
+	}, chartValues))
+}
@@ -0,0 +1,220 @@
+package valueslint
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+)
+
+// placeholderPattern matches the values the example values files ship with, e.g. YOUR_JDBC_URL or
+// YOUR_INSTALLER_IMAGE:TAG
+var placeholderPattern = regexp.MustCompile(`YOUR_[A-Z0-9_]+(:TAG)?`)
+
+// deprecatedResourceSettings size a tier when tier[].resources is not set
+var deprecatedResourceSettings = []string{"cpuRequest", "memRequest", "cpuLimit", "memLimit"}
+
+// valuesRules inspect the values file as written
+var valuesRules = []func(l *linter, values map[string]interface{}, chartValues map[string]interface{}){
+	placeholderValues,
+	tierResources,
+}
+
+// resourceRules inspect every resource rendered for an action, with object being the decoded resource
+var resourceRules = []func(l *linter, action string, resource helmtest.Resource, object map[string]interface{}){
+	placeholderResources,
+	sampleKeystore,
+	hpaReplicas,
+	ingressHost,
+}
+
+// placeholderValues reports placeholders left in the values file
+func placeholderValues(l *linter, values map[string]interface{}, _ map[string]interface{}) {
+	walk("", values, func(path string, value string) {
+		for _, placeholder := range placeholderPattern.FindAllString(value, -1) {
+			l.report(Finding{
+				Rule:     "placeholder",
+				Severity: SeverityError,
+				Path:     path,
+				Message:  "still set to the placeholder " + placeholder,
+			})
+		}
+	})
+}
+
+// placeholderResources reports placeholders that reach the rendered resources from the chart defaults, such as the
+// installer image when the values file does not set it. Secret data is decoded first.
+func placeholderResources(l *linter, action string, resource helmtest.Resource, object map[string]interface{}) {
+	if resource.Kind == "Secret" {
+		object = decodeSecretData(object)
+	}
+	walk("", object, func(path string, value string) {
+		for _, placeholder := range placeholderPattern.FindAllString(value, -1) {
+			if l.reportedPlaceholder(placeholder) {
+				continue
+			}
+			l.report(Finding{
+				Rule:     "placeholder",
+				Severity: SeverityError,
+				Actions:  []string{action},
+				Resource: resource.Kind + "/" + resource.Name,
+				Path:     path,
+				Message:  fmt.Sprintf("rendered with the placeholder %s from the chart defaults", placeholder),
+			})
+		}
+	})
+}
+
+// reportedPlaceholder tells whether the values file itself already contains placeholder
+func (l *linter) reportedPlaceholder(placeholder string) bool {
+	for _, finding := range l.findings {
+		if finding.Rule == "placeholder" && finding.Resource == "" && strings.HasSuffix(finding.Message, " "+placeholder) {
+			return true
+		}
+	}
+	return false
+}
+
+// tierResources reports tiers sized by the defaults of the chart rather than by tier[].resources
+func tierResources(l *linter, values map[string]interface{}, _ map[string]interface{}) {
+	tiers, _ := lookup(values, "global.tier").([]interface{})
+	for i, tier := range tiers {
+		tierValues, _ := tier.(map[string]interface{})
+		if tierValues == nil || tierValues["resources"] != nil {
+			continue
+		}
+		deprecated := false
+		for _, setting := range deprecatedResourceSettings {
+			deprecated = deprecated || tierValues[setting] != nil
+		}
+		if deprecated {
+			continue
+		}
+		l.report(Finding{
+			Rule:     "tier-resources",
+			Severity: SeverityError,
+			Path:     fmt.Sprintf("global.tier[%d].resources", i),
+			Message: fmt.Sprintf("tier %v does not set resources, its pods request 3 CPU and 12Gi of memory and are limited to 4 CPU and 12Gi",
+				tierValues["name"]),
+		})
+	}
+}
+
+// sampleKeystore reports tiers serving TLS with the keystore bundled in the chart, which is what the tomcat keystore
+// secret falls back to when neither a keystore, PEM certificates nor external secrets are configured
+func sampleKeystore(l *linter, action string, resource helmtest.Resource, object map[string]interface{}) {
+	if resource.Kind != "Secret" || !strings.HasSuffix(resource.Name, "-tomcat-keystore-secret") {
+		return
+	}
+	keystore, _ := decodeSecretData(object)["data"].(map[string]interface{})["TOMCAT_KEYSTORE_CONTENT"].(string)
+	if keystore == "" {
+		return
+	}
+	sample, err := ioutil.ReadFile(filepath.Join(l.chartPath, "config", "certs", "pegakeystore.jks"))
+	if err != nil || !bytes.Equal([]byte(keystore), sample) {
+		return
+	}
+	l.report(Finding{
+		Rule:     "tls-keystore",
+		Severity: SeverityError,
+		Actions:  []string{action},
+		Resource: resource.Kind + "/" + resource.Name,
+		Path:     "data.TOMCAT_KEYSTORE_CONTENT",
+		Message: "TLS is enabled with the sample keystore bundled with the chart; set tier[].service.tls.keystore and " +
+			"keystorepassword, certificateFile and certificateKeyFile, or external_secret_names",
+	})
+}
+
+// hpaReplicas reports autoscalers that can never scale because their bounds are inverted
+func hpaReplicas(l *linter, action string, resource helmtest.Resource, object map[string]interface{}) {
+	if resource.Kind != "HorizontalPodAutoscaler" {
+		return
+	}
+	minReplicas, hasMin := lookup(object, "spec.minReplicas").(float64)
+	maxReplicas, hasMax := lookup(object, "spec.maxReplicas").(float64)
+	if !hasMin || !hasMax || minReplicas <= maxReplicas {
+		return
+	}
+	l.report(Finding{
+		Rule:     "hpa-replicas",
+		Severity: SeverityError,
+		Actions:  []string{action},
+		Resource: resource.Kind + "/" + resource.Name,
+		Path:     "spec.minReplicas",
+		Message:  fmt.Sprintf("minReplicas %v is greater than maxReplicas %v", minReplicas, maxReplicas),
+	})
+}
+
+// ingressHost warns about ingress rules without a host, which accept requests for any domain
+func ingressHost(l *linter, action string, resource helmtest.Resource, object map[string]interface{}) {
+	if resource.Kind != "Ingress" {
+		return
+	}
+	rules, _ := lookup(object, "spec.rules").([]interface{})
+	for i, rule := range rules {
+		ruleValues, _ := rule.(map[string]interface{})
+		if host, _ := ruleValues["host"].(string); host != "" {
+			continue
+		}
+		l.report(Finding{
+			Rule:     "ingress-host",
+			Severity: SeverityWarning,
+			Actions:  []string{action},
+			Resource: resource.Kind + "/" + resource.Name,
+			Path:     fmt.Sprintf("spec.rules[%d].host", i),
+			Message:  "the ingress has no host and accepts requests for any domain; set tier[].ingress.domain",
+		})
+	}
+}
+
+// decodeSecretData returns a copy of a Secret with the base64 encoded data replaced by its decoded content
+func decodeSecretData(object map[string]interface{}) map[string]interface{} {
+	data, _ := object["data"].(map[string]interface{})
+	decoded := map[string]interface{}{}
+	for key, value := range data {
+		encoded, _ := value.(string)
+		content, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(encoded), ""))
+		if err != nil {
+			decoded[key] = encoded
+			continue
+		}
+		decoded[key] = string(content)
+	}
+	secret := map[string]interface{}{}
+	for key, value := range object {
+		secret[key] = value
+	}
+	secret["data"] = decoded
+	return secret
+}
+
+// walk calls visit for every string in value with its dot separated path, visiting map keys in sorted order
+func walk(path string, value interface{}, visit func(path string, value string)) {
+	switch typed := value.(type) {
+	case string:
+		visit(path, typed)
+	case []interface{}:
+		for i, item := range typed {
+			walk(fmt.Sprintf("%s[%d]", path, i), item, visit)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			keyPath := key
+			if path != "" {
+				keyPath = path + "." + key
+			}
+			walk(keyPath, typed[key], visit)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// Command pega-values-lint checks a values file for the pega chart before it is deployed. It renders the chart with the
+// file for every action the file can be used with and prints a JSON report of the problems found: placeholders left
+// from the example values, tiers without resources, TLS served with the sample keystore and the like.
+//
+//	go run ./valueslint/pega-values-lint -values prod-values.yaml -out report.json
+//
+// The exit code is 0 when the file has no errors, 1 when it has and 2 when the lint could not run.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/valueslint"
+)
+
+func main() {
+	valuesFile := flag.String("values", "", "path of the values file to lint")
+	chartPath := flag.String("chart", "", "path of the pega chart, defaults to charts/pega of this repository")
+	actions := flag.String("actions", "", "comma separated actions to render, defaults to every action the values file can be used with")
+	kubeVersion := flag.String("kube-version", "", "Kubernetes version reported to the templates")
+	outPath := flag.String("out", "", "write the JSON report to this file instead of stdout")
+	flag.Parse()
+	if *valuesFile == "" {
+		flag.Usage()
+		fmt.Fprintln(os.Stderr, "-values is required")
+		os.Exit(2)
+	}
+
+	options := valueslint.Options{ChartPath: *chartPath, ValuesFile: *valuesFile, KubeVersion: *kubeVersion}
+	if *actions != "" {
+		options.Actions = strings.Split(*actions, ",")
+	}
+	report, err := valueslint.Lint(options)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	content, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	content = append(content, '\n')
+	if *outPath == "" {
+		os.Stdout.Write(content)
+	} else if err := ioutil.WriteFile(*outPath, content, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	for _, finding := range report.Findings {
+		fmt.Fprintln(os.Stderr, describe(finding))
+	}
+	fmt.Fprintf(os.Stderr, "%s: %d error(s), %d finding(s) in total\n", *valuesFile, report.Errors(), len(report.Findings))
+	if !report.Passed {
+		os.Exit(1)
+	}
+}
+
+// describe formats a finding for the summary printed to stderr
+func describe(finding valueslint.Finding) string {
+	location := finding.Path
+	if finding.Resource != "" {
+		location = finding.Resource + " " + finding.Path
+	}
+	line := fmt.Sprintf("%s [%s] %s: %s", finding.Severity, finding.Rule, location, finding.Message)
+	if len(finding.Actions) > 0 {
+		line += " (" + strings.Join(finding.Actions, ", ") + ")"
+	}
+	return line
+}
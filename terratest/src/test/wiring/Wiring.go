@@ -0,0 +1,451 @@
+// Package wiring checks that the pega chart and the backingservices chart agree with each other: the search URL and
+// the SRS authentication of pega against the srs subchart, the /c11n routing of pega against the constellation
+// Services, and that the two releases do not render the same object. Each chart is tested on its own elsewhere; only
+// rendering both from one System catches a Service renamed on one side, a port changed on the other or the two
+// releases installed in namespaces that cannot reach each other.
+package wiring
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+	appsv1 "k8s.io/api/apps/v1"
+	k8score "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// System is one installation: the pega release and the backingservices release it uses, each with its own values and
+// namespace. The Chart of both scenarios is set by Render.
+type System struct {
+	Name            string
+	Pega            helmtest.Scenario
+	BackingServices helmtest.Scenario
+}
+
+// Release is the rendered output of one chart of a System
+type Release struct {
+	Namespace string
+	Parser    *helmtest.HelmChartParser
+}
+
+// Render renders both charts of the system and fails the test if either does not render
+func (s System) Render(t *testing.T) (pega Release, backingServices Release) {
+	pegaScenario, backingScenario := s.Pega, s.BackingServices
+	pegaScenario.Chart = helmtest.PegaChart
+	backingScenario.Chart = helmtest.BackingServicesChart
+	return render(t, pegaScenario), render(t, backingScenario)
+}
+
+func render(t *testing.T, scenario helmtest.Scenario) Release {
+	namespace := scenario.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+	return Release{Namespace: namespace, Parser: helmtest.NewHelmChartParser(t, scenario.HelmTest(t).Render(), namespace)}
+}
+
+// AssertWired renders the system and reports every mismatch between the two charts as a test error
+func AssertWired(t *testing.T, system System) {
+	pega, backingServices := system.Render(t)
+	problems, err := Problems(pega, backingServices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, problem := range problems {
+		t.Errorf("%s: %s", system.Name, problem)
+	}
+}
+
+// Problems lists the mismatches between the renders of the pega and backingservices charts, one sentence each
+func Problems(pega Release, backingServices Release) ([]string, error) {
+	services, err := helmtest.QueryAsE[k8score.Service](backingServices.Parser, helmtest.ResourceQuery{Kind: "Service"})
+	if err != nil {
+		return nil, err
+	}
+	policies, err := helmtest.QueryAsE[networkingv1.NetworkPolicy](backingServices.Parser, helmtest.ResourceQuery{Kind: "NetworkPolicy"})
+	if err != nil {
+		return nil, err
+	}
+	deployments, err := helmtest.QueryAsE[appsv1.Deployment](backingServices.Parser, helmtest.ResourceQuery{Kind: "Deployment"})
+	if err != nil {
+		return nil, err
+	}
+	backing := backingObjects{namespace: backingServices.Namespace, services: services, policies: policies, deployments: deployments}
+	for i := range backing.services {
+		if backing.services[i].Namespace == "" {
+			backing.services[i].Namespace = backingServices.Namespace
+		}
+	}
+	for i := range backing.policies {
+		if backing.policies[i].Namespace == "" {
+			backing.policies[i].Namespace = backingServices.Namespace
+		}
+	}
+
+	problems, err := collisions(pega, backingServices)
+	if err != nil {
+		return nil, err
+	}
+	searchProblems, err := searchProblems(pega, backing)
+	if err != nil {
+		return nil, err
+	}
+	constellationProblems, err := constellationProblems(pega, backing)
+	if err != nil {
+		return nil, err
+	}
+	return append(append(problems, searchProblems...), constellationProblems...), nil
+}
+
+// collisions reports the objects both charts render with the same kind, name and namespace; the second release to be
+// installed would fail, since helm does not take over an object another release owns
+func collisions(pega Release, backingServices Release) ([]string, error) {
+	pegaResources, err := pega.Parser.QueryE(helmtest.ResourceQuery{})
+	if err != nil {
+		return nil, err
+	}
+	backingResources, err := backingServices.Parser.QueryE(helmtest.ResourceQuery{})
+	if err != nil {
+		return nil, err
+	}
+	rendered := map[string]bool{}
+	for _, resource := range pegaResources {
+		rendered[resource.Kind+"/"+resource.Namespace+"/"+resource.Name] = true
+	}
+	var problems []string
+	for _, resource := range backingResources {
+		if rendered[resource.Kind+"/"+resource.Namespace+"/"+resource.Name] {
+			problems = append(problems, fmt.Sprintf("pega and backingservices both render %s %s in namespace %s", resource.Kind, resource.Name, resource.Namespace))
+		}
+	}
+	return problems, nil
+}
+
+// backingObjects are the objects of the backingservices render the pega objects are checked against
+type backingObjects struct {
+	namespace   string
+	services    []k8score.Service
+	policies    []networkingv1.NetworkPolicy
+	deployments []appsv1.Deployment
+}
+
+// srsDeployment returns the SRS Deployment, the one whose pods the srs Services select
+func (b backingObjects) srsDeployment() *appsv1.Deployment {
+	for i, deployment := range b.deployments {
+		if deployment.Spec.Template.Labels["app.kubernetes.io/name"] == "srs-service" {
+			return &b.deployments[i]
+		}
+	}
+	return nil
+}
+
+// constellationDeployment returns the Deployment serving the constellation static content
+func (b backingObjects) constellationDeployment() *appsv1.Deployment {
+	for i, deployment := range b.deployments {
+		if deployment.Spec.Template.Labels["app"] == "constellation" {
+			return &b.deployments[i]
+		}
+	}
+	return nil
+}
+
+// selecting returns the Services whose selector matches the pods of deployment
+func (b backingObjects) selecting(deployment *appsv1.Deployment) []k8score.Service {
+	var services []k8score.Service
+	for _, service := range b.services {
+		if service.Namespace == b.namespace && matchesLabels(service.Spec.Selector, deployment.Spec.Template.Labels) {
+			services = append(services, service)
+		}
+	}
+	return services
+}
+
+func searchProblems(pega Release, backing backingObjects) ([]string, error) {
+	srs := backing.srsDeployment()
+	if srs == nil {
+		return nil, nil
+	}
+	environment, err := environmentConfig(pega)
+	if err != nil {
+		return nil, err
+	}
+	searchURL, usesSRS := environment["SEARCH_AND_REPORTING_SERVICE_URL"]
+	if !usesSRS {
+		return []string{fmt.Sprintf("backingservices deploys SRS (Deployment %s), but pega does not use it: pegasearch.externalSearchService is not enabled", srs.Name)}, nil
+	}
+
+	var problems []string
+	if problem := resolve("pegasearch.externalURL "+searchURL, searchURL, pega.Namespace, backing.selecting(srs), backing); problem != "" {
+		problems = append(problems, problem)
+	}
+
+	_, pegaAuth := environment["SERV_AUTH_URL"]
+	srsAuth := containerEnv(srs, "AUTH_ENABLED") == "true"
+	if pegaAuth && !srsAuth {
+		problems = append(problems, "pegasearch.srsAuth.enabled is true, but SRS does not check the tokens: srs.srsRuntime.env.AuthEnabled is false")
+	}
+	if srsAuth && !pegaAuth {
+		problems = append(problems, "SRS requires tokens (srs.srsRuntime.env.AuthEnabled is true), but pega does not send them: pegasearch.srsAuth.enabled is false")
+	}
+	return problems, nil
+}
+
+// constellationProblems checks the /c11n route of the pega Ingresses against the constellation Services. The pega
+// chart deploys constellation itself when constellation.enabled is set, the backingservices chart can deploy it too;
+// either way the Service must be in the namespace of the Ingress, and it must serve the path the tiers load the UI from.
+func constellationProblems(pega Release, backing backingObjects) ([]string, error) {
+	ingresses, err := helmtest.QueryAsE[networkingv1.Ingress](pega.Parser, helmtest.ResourceQuery{Kind: "Ingress"})
+	if err != nil {
+		return nil, err
+	}
+	tiers, err := helmtest.QueryAsE[appsv1.Deployment](pega.Parser, helmtest.ResourceQuery{Kind: "Deployment"})
+	if err != nil {
+		return nil, err
+	}
+	pegaServices, err := helmtest.QueryAsE[k8score.Service](pega.Parser, helmtest.ResourceQuery{Kind: "Service"})
+	if err != nil {
+		return nil, err
+	}
+
+	// the constellation Deployments and Services of both charts
+	var constellations []*appsv1.Deployment
+	var services []k8score.Service
+	for i := range tiers {
+		if tiers[i].Spec.Template.Labels["app"] == "constellation" {
+			constellations = append(constellations, &tiers[i])
+			for _, service := range pegaServices {
+				if matchesLabels(service.Spec.Selector, tiers[i].Spec.Template.Labels) {
+					service.Namespace = pega.Namespace
+					services = append(services, service)
+				}
+			}
+		}
+	}
+	if constellation := backing.constellationDeployment(); constellation != nil {
+		constellations = append(constellations, constellation)
+		services = append(services, backing.selecting(constellation)...)
+	}
+	var urlPaths []string
+	for _, constellation := range constellations {
+		if urlPath := containerArg(constellation, "urlPath="); urlPath != "" {
+			urlPaths = append(urlPaths, urlPath)
+		}
+	}
+
+	var problems []string
+	for _, ingress := range ingresses {
+		for _, rule := range ingress.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for _, path := range rule.HTTP.Paths {
+				if !isC11nPath(path.Path) || path.Backend.Service == nil {
+					continue
+				}
+				// an Ingress backend is always in the namespace of the Ingress
+				backend := path.Backend.Service
+				description := fmt.Sprintf("Ingress %s backend of %s", ingress.Name, path.Path)
+				if len(services) == 0 {
+					problems = append(problems, fmt.Sprintf("%s is Service %s, but neither chart deploys constellation", description, backend.Name))
+				} else if problem := resolveService(description, backend.Name, pega.Namespace, backend.Port.Number, backend.Port.Name, services, backing); problem != "" {
+					problems = append(problems, problem)
+				}
+			}
+		}
+	}
+
+	for _, tier := range tiers {
+		cosmos := containerEnv(&tier, "COSMOS_SETTINGS")
+		if cosmos == "" {
+			continue
+		}
+		uri := strings.TrimPrefix(cosmos, "Pega-UIEngine/cosmosservicesURI=")
+		for _, urlPath := range urlPaths {
+			if urlPath != uri {
+				problems = append(problems, fmt.Sprintf("Deployment %s loads the UI from %s, but constellation serves %s", tier.Name, uri, urlPath))
+			}
+		}
+	}
+	return dedupe(problems), nil
+}
+
+// isC11nPath tells whether path is the route of the constellation UI
+func isC11nPath(path string) bool {
+	return strings.TrimSuffix(path, "/") == "/c11n"
+}
+
+// resolve checks that a URL pega calls is served by one of the expected Services of backingservices
+func resolve(description string, rawURL string, clientNamespace string, expected []k8score.Service, backing backingObjects) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Hostname() == "" {
+		return fmt.Sprintf("%s is not a URL", description)
+	}
+	labels := strings.Split(parsed.Hostname(), ".")
+	name, namespace := labels[0], clientNamespace
+	if len(labels) > 1 {
+		namespace = labels[1]
+		if suffix := strings.Join(labels[2:], "."); suffix != "" && suffix != "svc" && suffix != "svc.cluster.local" {
+			return fmt.Sprintf("%s is not the address of a Service in the cluster, expected %s", description, serviceURLs(expected))
+		}
+	}
+	if net.ParseIP(parsed.Hostname()) != nil {
+		return fmt.Sprintf("%s is an IP address, expected %s", description, serviceURLs(expected))
+	}
+	port := 80
+	if parsed.Scheme == "https" {
+		port = 443
+	}
+	if parsed.Port() != "" {
+		port, _ = strconv.Atoi(parsed.Port())
+	}
+	return resolveService(description, name, namespace, int32(port), "", expected, backing)
+}
+
+// resolveService checks that the Service name in namespace is one of expected and has the port, given by number or,
+// when port is 0, by name
+func resolveService(description string, name string, namespace string, port int32, portName string,
+	expected []k8score.Service, backing backingObjects) string {
+	var service *k8score.Service
+	for i := range expected {
+		if expected[i].Name == name && (service == nil || expected[i].Namespace == namespace) {
+			service = &expected[i]
+		}
+	}
+	if service == nil {
+		return fmt.Sprintf("%s calls Service %s, expected %s", description, name, serviceURLs(expected))
+	}
+	if service.Namespace != namespace {
+		return fmt.Sprintf("%s calls Service %s in namespace %s, but backingservices is installed in namespace %s", description, name, namespace, service.Namespace)
+	}
+	for _, servicePort := range service.Spec.Ports {
+		if (port != 0 && servicePort.Port == port) || (port == 0 && servicePort.Name == portName) {
+			return admitted(description, service, servicePort, backing)
+		}
+	}
+	var ports []string
+	for _, servicePort := range service.Spec.Ports {
+		ports = append(ports, strconv.Itoa(int(servicePort.Port)))
+	}
+	requested := strconv.Itoa(int(port))
+	if port == 0 {
+		requested = portName
+	}
+	return fmt.Sprintf("%s calls port %s of Service %s, which only has port(s) %s", description, requested, name, strings.Join(ports, ", "))
+}
+
+// admitted checks that the NetworkPolicies selecting the pods behind the Service let traffic in on the target port
+func admitted(description string, service *k8score.Service, port k8score.ServicePort, backing backingObjects) string {
+	targetPort := port.TargetPort
+	if targetPort.IntVal == 0 && targetPort.StrVal == "" {
+		targetPort = intstr.FromInt(int(port.Port))
+	}
+	restricted := false
+	for _, policy := range backing.policies {
+		if policy.Namespace != service.Namespace || !matchesLabels(policy.Spec.PodSelector.MatchLabels, service.Spec.Selector) || !restrictsIngress(policy) {
+			continue
+		}
+		restricted = true
+		for _, rule := range policy.Spec.Ingress {
+			if len(rule.Ports) == 0 {
+				return ""
+			}
+			for _, rulePort := range rule.Ports {
+				if rulePort.Port == nil || *rulePort.Port == targetPort {
+					return ""
+				}
+			}
+		}
+	}
+	if restricted {
+		return fmt.Sprintf("%s reaches Service %s on target port %s, which no NetworkPolicy of backingservices lets in", description, service.Name, targetPort.String())
+	}
+	return ""
+}
+
+func restrictsIngress(policy networkingv1.NetworkPolicy) bool {
+	if len(policy.Spec.PolicyTypes) == 0 {
+		return true
+	}
+	for _, policyType := range policy.Spec.PolicyTypes {
+		if policyType == networkingv1.PolicyTypeIngress {
+			return true
+		}
+	}
+	return false
+}
+
+func serviceURLs(services []k8score.Service) string {
+	if len(services) == 0 {
+		return "no Service"
+	}
+	var urls []string
+	for _, service := range services {
+		for _, port := range service.Spec.Ports {
+			urls = append(urls, fmt.Sprintf("%s.%s:%d", service.Name, service.Namespace, port.Port))
+		}
+	}
+	sort.Strings(urls)
+	return strings.Join(urls, " or ")
+}
+
+func environmentConfig(pega Release) (map[string]string, error) {
+	configMaps, err := helmtest.QueryAsE[k8score.ConfigMap](pega.Parser, helmtest.ResourceQuery{Kind: "ConfigMap", Name: "pega-environment-config"})
+	if err != nil || len(configMaps) == 0 {
+		return map[string]string{}, err
+	}
+	return configMaps[0].Data, nil
+}
+
+func containerEnv(deployment *appsv1.Deployment, name string) string {
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		for _, env := range container.Env {
+			if env.Name == name {
+				return env.Value
+			}
+		}
+	}
+	return ""
+}
+
+func containerArg(deployment *appsv1.Deployment, prefix string) string {
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		for _, arg := range container.Args {
+			if strings.HasPrefix(arg, prefix) {
+				return strings.TrimPrefix(arg, prefix)
+			}
+		}
+	}
+	return ""
+}
+
+// matchesLabels tells whether labels carry every key and value of selector; an empty selector matches nothing
+func matchesLabels(selector map[string]string, labels map[string]string) bool {
+	if len(selector) == 0 {
+		return false
+	}
+	for key, value := range selector {
+		if labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+func dedupe(problems []string) []string {
+	seen := map[string]bool{}
+	var result []string
+	for _, problem := range problems {
+		if !seen[problem] {
+			seen[problem] = true
+			result = append(result, problem)
+		}
+	}
+	return result
+}
@@ -0,0 +1,291 @@
+package wiring
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pegasystems/pega-helm-charts/terratest/src/test/helmtest"
+	"github.com/stretchr/testify/require"
+)
+
+// pegaWithSRS renders pega for SRS at searchURL, in namespace pega
+func pegaWithSRS(provider string, searchURL string, setValues map[string]string) helmtest.Scenario {
+	values := map[string]string{
+		"pegasearch.externalSearchService": "true",
+		"pegasearch.externalURL":           searchURL,
+	}
+	for key, value := range setValues {
+		values[key] = value
+	}
+	return helmtest.Scenario{Provider: provider, Action: "deploy", Namespace: "pega", SetValues: values}
+}
+
+// backingServices renders srs with an external Elasticsearch, as release srs in namespace
+func backingServices(namespace string, setValues map[string]string) helmtest.Scenario {
+	values := map[string]string{
+		"srs.enabled":        "true",
+		"srs.deploymentName": "srs",
+		"srs.srsStorage.provisionInternalESCluster":  "false",
+		"srs.srsStorage.domain":                      "elasticsearch.example.com",
+		"srs.srsStorage.port":                        "9200",
+		"srs.srsStorage.protocol":                    "https",
+		"srs.srsStorage.tls.enabled":                 "false",
+		"srs.srsStorage.basicAuthentication.enabled": "false",
+	}
+	for key, value := range setValues {
+		values[key] = value
+	}
+	return helmtest.Scenario{ReleaseName: "srs", Namespace: namespace, KubeVersion: "1.27.0", SetValues: values}
+}
+
+var srsAuth = map[string]string{
+	"pegasearch.srsAuth.enabled":              "true",
+	"pegasearch.srsAuth.url":                  "https://oauth.example.com/token",
+	"pegasearch.srsAuth.clientId":             "pega",
+	"pegasearch.srsAuth.authType":             "client_secret_basic",
+	"pegasearch.srsAuth.external_secret_name": "pega-srs-credentials",
+}
+
+var srsRuntimeAuth = map[string]string{
+	"srs.srsRuntime.env.AuthEnabled":       "true",
+	"srs.srsRuntime.env.OAuthPublicKeyURL": "https://oauth.example.com/keys",
+}
+
+var constellation = map[string]string{"constellation.enabled": "true"}
+
+func merged(maps ...map[string]string) map[string]string {
+	result := map[string]string{}
+	for _, values := range maps {
+		for key, value := range values {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+func TestWiredSystems(t *testing.T) {
+	var systems []System
+	for _, provider := range []string{"k8s", "eks", "gke"} {
+		systems = append(systems,
+			System{
+				Name:            provider + "-srs-in-pega-namespace",
+				Pega:            pegaWithSRS(provider, "http://srs", nil),
+				BackingServices: backingServices("pega", nil),
+			},
+			System{
+				Name:            provider + "-srs-in-own-namespace",
+				Pega:            pegaWithSRS(provider, "http://srs.search.svc.cluster.local:8080", nil),
+				BackingServices: backingServices("search", nil),
+			},
+			System{
+				Name:            provider + "-srs-with-oauth",
+				Pega:            pegaWithSRS(provider, "http://srs.search:8080", srsAuth),
+				BackingServices: backingServices("search", srsRuntimeAuth),
+			},
+			System{
+				Name:            provider + "-constellation-in-pega",
+				Pega:            pegaWithSRS(provider, "http://srs.search:8080", constellation),
+				BackingServices: backingServices("search", nil),
+			},
+			System{
+				Name:            provider + "-constellation-in-both-namespaces",
+				Pega:            pegaWithSRS(provider, "http://srs.search:8080", constellation),
+				BackingServices: backingServices("search", constellation),
+			},
+		)
+	}
+	for _, system := range systems {
+		system := system
+		t.Run(system.Name, func(t *testing.T) {
+			t.Parallel()
+			AssertWired(t, system)
+		})
+	}
+}
+
+func TestMiswiredSystems(t *testing.T) {
+	cases := []struct {
+		system   System
+		problems []string
+	}{
+		{
+			System{Name: "wrong-service-name", Pega: pegaWithSRS("k8s", "http://pega-srs", nil), BackingServices: backingServices("pega", nil)},
+			[]string{"pegasearch.externalURL http://pega-srs calls Service pega-srs, expected srs.pega:80 or srs.pega:8080"},
+		},
+		{
+			System{Name: "wrong-namespace", Pega: pegaWithSRS("k8s", "http://srs", nil), BackingServices: backingServices("search", nil)},
+			[]string{"pegasearch.externalURL http://srs calls Service srs in namespace pega, but backingservices is installed in namespace search"},
+		},
+		{
+			System{Name: "wrong-port", Pega: pegaWithSRS("k8s", "http://srs.search:9200", nil), BackingServices: backingServices("search", nil)},
+			[]string{"pegasearch.externalURL http://srs.search:9200 calls port 9200 of Service srs, which only has port(s) 8080, 80"},
+		},
+		{
+			System{Name: "external-host", Pega: pegaWithSRS("k8s", "https://srs.example.com", nil), BackingServices: backingServices("search", nil)},
+			[]string{"pegasearch.externalURL https://srs.example.com is not the address of a Service in the cluster, expected srs.search:80 or srs.search:8080"},
+		},
+		{
+			System{Name: "srs-not-used", Pega: helmtest.Scenario{Provider: "k8s", Action: "deploy", Namespace: "pega"}, BackingServices: backingServices("pega", nil)},
+			[]string{"backingservices deploys SRS (Deployment srs), but pega does not use it: pegasearch.externalSearchService is not enabled"},
+		},
+		{
+			System{Name: "oauth-in-pega-only", Pega: pegaWithSRS("k8s", "http://srs", srsAuth), BackingServices: backingServices("pega", nil)},
+			[]string{"pegasearch.srsAuth.enabled is true, but SRS does not check the tokens: srs.srsRuntime.env.AuthEnabled is false"},
+		},
+		{
+			System{Name: "oauth-in-srs-only", Pega: pegaWithSRS("k8s", "http://srs", nil), BackingServices: backingServices("pega", srsRuntimeAuth)},
+			[]string{"SRS requires tokens (srs.srsRuntime.env.AuthEnabled is true), but pega does not send them: pegasearch.srsAuth.enabled is false"},
+		},
+		{
+			System{Name: "constellation-in-both-charts", Pega: pegaWithSRS("k8s", "http://srs", constellation), BackingServices: backingServices("pega", constellation)},
+			[]string{
+				"pega and backingservices both render Service constellation in namespace pega",
+				"pega and backingservices both render Deployment constellation in namespace pega",
+			},
+		},
+	}
+	for _, testCase := range cases {
+		testCase := testCase
+		t.Run(testCase.system.Name, func(t *testing.T) {
+			t.Parallel()
+			pega, backing := testCase.system.Render(t)
+			problems, err := Problems(pega, backing)
+			require.NoError(t, err)
+			require.ElementsMatch(t, testCase.problems, problems)
+		})
+	}
+}
+
+const networkPolicyManifests = `---
+apiVersion: v1
+kind: Service
+metadata:
+  name: srs
+spec:
+  selector:
+    app.kubernetes.io/name: srs-service
+  ports:
+  - name: http80
+    port: 80
+    targetPort: 9090
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: srs
+spec:
+  template:
+    metadata:
+      labels:
+        app.kubernetes.io/name: srs-service
+    spec:
+      containers:
+      - name: srs-service
+---
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: srs-networkpolicy
+spec:
+  podSelector:
+    matchLabels:
+      app.kubernetes.io/name: srs-service
+  policyTypes:
+  - Ingress
+  ingress:
+  - ports:
+    - protocol: TCP
+      port: 8080
+`
+
+const pegaManifests = `---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: pega-environment-config
+data:
+  SEARCH_AND_REPORTING_SERVICE_URL: http://srs.search
+`
+
+func TestProblemsNetworkPolicy(t *testing.T) {
+	backing := Release{Namespace: "search", Parser: helmtest.NewHelmChartParser(t, networkPolicyManifests, "search")}
+	pega := Release{Namespace: "pega", Parser: helmtest.NewHelmChartParser(t, pegaManifests, "pega")}
+	problems, err := Problems(pega, backing)
+	require.NoError(t, err)
+	require.Equal(t, []string{"pegasearch.externalURL http://srs.search reaches Service srs on target port 9090, which no NetworkPolicy of backingservices lets in"}, problems)
+}
+
+const c11nIngressManifests = `---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: pega-web
+spec:
+  rules:
+  - http:
+      paths:
+      - path: /c11n
+        pathType: ImplementationSpecific
+        backend:
+          service:
+            name: constellation
+            port:
+              number: %d
+`
+
+const constellationManifests = `---
+apiVersion: v1
+kind: Service
+metadata:
+  name: constellation
+spec:
+  selector:
+    app: constellation
+  ports:
+  - port: 3000
+    targetPort: 3000
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: constellation
+spec:
+  template:
+    metadata:
+      labels:
+        app: constellation
+    spec:
+      containers:
+      - name: constellation
+        args:
+        - port=3000
+        - urlPath=/c11n
+`
+
+func TestProblemsC11nRoute(t *testing.T) {
+	cases := []struct {
+		name             string
+		port             int
+		backingNamespace string
+		problems         []string
+	}{
+		{"same namespace", 3000, "pega", nil},
+		{"other namespace", 3000, "search",
+			[]string{"Ingress pega-web backend of /c11n calls Service constellation in namespace pega, but backingservices is installed in namespace search"}},
+		{"wrong port", 8080, "pega",
+			[]string{"Ingress pega-web backend of /c11n calls port 8080 of Service constellation, which only has port(s) 3000"}},
+	}
+	for _, testCase := range cases {
+		backing := Release{Namespace: testCase.backingNamespace, Parser: helmtest.NewHelmChartParser(t, constellationManifests, testCase.backingNamespace)}
+		pega := Release{Namespace: "pega", Parser: helmtest.NewHelmChartParser(t, fmt.Sprintf(c11nIngressManifests, testCase.port), "pega")}
+		problems, err := Problems(pega, backing)
+		require.NoError(t, err)
+		require.Equal(t, testCase.problems, problems, testCase.name)
+	}
+
+	pega := Release{Namespace: "pega", Parser: helmtest.NewHelmChartParser(t, fmt.Sprintf(c11nIngressManifests, 3000), "pega")}
+	backing := Release{Namespace: "pega", Parser: helmtest.NewHelmChartParser(t, "", "pega")}
+	problems, err := Problems(pega, backing)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Ingress pega-web backend of /c11n is Service constellation, but neither chart deploys constellation"}, problems)
+}